@@ -30,6 +30,7 @@ func NewRedisClient(cfg config.RedisInstanceConfig) (*RedisClient, error) {
 		ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
 	})
+	client.AddHook(tracingHook{name: cfg.Name, db: cfg.DB})
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
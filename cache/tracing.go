@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// tracingHook creates a span for every Redis command.
+// Only command names are recorded, never keys or values.
+type tracingHook struct {
+	name string
+	db   int
+}
+
+// DialHook implements redis.Hook
+func (h tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := tracing.Start(ctx, "redis.dial",
+			tracing.AttrDBSystem.String("redis"),
+			tracing.AttrDBName.String(h.name),
+		)
+		conn, err := next(ctx, network, addr)
+		tracing.End(span, err)
+		return conn, err
+	}
+}
+
+// ProcessHook implements redis.Hook
+func (h tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis."+cmd.Name(),
+			tracing.AttrDBSystem.String("redis"),
+			tracing.AttrDBName.String(h.name),
+			tracing.AttrDBOperation.String(cmd.Name()),
+			tracing.AttrRedisDatabaseIndex.Int(h.db),
+		)
+		err := next(ctx, cmd)
+		tracing.End(span, ignoreNil(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook
+func (h tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis.pipeline",
+			tracing.AttrDBSystem.String("redis"),
+			tracing.AttrDBName.String(h.name),
+			tracing.AttrDBOperation.String("pipeline"),
+			tracing.AttrRedisDatabaseIndex.Int(h.db),
+		)
+		err := next(ctx, cmds)
+		tracing.End(span, ignoreNil(err))
+		return err
+	}
+}
+
+// ignoreNil treats a missing key as a successful command
+func ignoreNil(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
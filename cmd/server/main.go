@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/metrics"
 	"github.com/SkillingX/mcp-localbridge/server"
+	"github.com/SkillingX/mcp-localbridge/tracing"
 	"github.com/SkillingX/mcp-localbridge/transports"
 )
 
@@ -36,6 +40,20 @@ func main() {
 		"server", cfg.Server.Name,
 		"version", cfg.Server.Version)
 
+	// Initialize OpenTelemetry tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Server.Name, cfg.Server.Version)
+	if err != nil {
+		logger.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Error shutting down tracing", "error", err)
+		}
+	}()
+
 	// Create MCP server
 	mcpServer, err := server.NewMCPServer(cfg, logger)
 	if err != nil {
@@ -61,6 +79,17 @@ func main() {
 
 	logger.Info("Transports initialized successfully")
 
+	// Start Prometheus metrics endpoint
+	var metricsServer *metrics.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = metrics.NewServer(mcpServer.GetMetricsRegistry(), cfg.Metrics, logger)
+		go func() {
+			if err := metricsServer.Start(); err != nil {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	}
+
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -74,6 +103,14 @@ func main() {
 		if err := transportMgr.StopAll(); err != nil {
 			logger.Error("Error during graceful shutdown", "error", err)
 		}
+
+		if metricsServer != nil {
+			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := metricsServer.Stop(stopCtx); err != nil {
+				logger.Error("Error stopping metrics server", "error", err)
+			}
+		}
 	}()
 
 	// Start all transports
@@ -137,7 +174,8 @@ func setupLoggerWithConfig(cfg *config.Config) *slog.Logger {
 		})
 	}
 
-	return slog.New(handler)
+	// Correlate log records with the active trace span
+	return slog.New(tracing.NewLogHandler(handler))
 }
 
 // getEnabledTransports returns a list of enabled transport names
@@ -0,0 +1,294 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// Defaults applied when completion settings are missing from the config
+const (
+	defaultCacheTTL    = 30
+	defaultScanCount   = 100
+	defaultMaxScanKeys = 1000
+)
+
+// maxValues is the most values a completion response may carry
+const maxValues = 100
+
+// maxCacheEntries bounds the cached candidate lists, since every typed Redis
+// key prefix adds one
+const maxCacheEntries = 1000
+
+// keyDelimiter separates the segments of Redis key prefixes
+const keyDelimiter = ":"
+
+// Completer answers completion/complete requests for prompt and resource
+// template arguments. Values are resolved by argument name, so the same
+// names used by tools (database, table, column, group_by, order_by, redis,
+// key, pattern) complete everywhere.
+type Completer struct {
+	repositories map[string]db.Repository
+	redisClients map[string]*cache.RedisClient
+	config       config.CompletionConfig
+	logger       *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// cacheEntry is a list of candidates with its expiry
+type cacheEntry struct {
+	values    []string
+	expiresAt time.Time
+}
+
+// NewCompleter creates a new completer
+func NewCompleter(
+	repos map[string]db.Repository,
+	redisClients map[string]*cache.RedisClient,
+	cfg config.CompletionConfig,
+	logger *slog.Logger,
+) *Completer {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+	if cfg.ScanCount <= 0 {
+		cfg.ScanCount = defaultScanCount
+	}
+	if cfg.MaxScanKeys <= 0 {
+		cfg.MaxScanKeys = defaultMaxScanKeys
+	}
+
+	return &Completer{
+		repositories: repos,
+		redisClients: redisClients,
+		config:       cfg,
+		logger:       logger,
+		cache:        make(map[string]cacheEntry),
+	}
+}
+
+// CompletePromptArgument completes an argument of a prompt
+func (c *Completer) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, context mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.Complete(ctx, argument, context.Arguments), nil
+}
+
+// CompleteResourceArgument completes a variable of a resource template
+func (c *Completer) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, context mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.Complete(ctx, argument, context.Arguments), nil
+}
+
+// Complete returns the candidates for an argument, given the arguments the
+// client already resolved. Lookup failures yield an empty completion.
+func (c *Completer) Complete(ctx context.Context, argument mcp.CompleteArgument, resolved map[string]string) *mcp.Completion {
+	switch argument.Name {
+	case "database":
+		return match(argument.Value, mapKeys(c.repositories))
+	case "table":
+		return match(argument.Value, c.tables(ctx, resolved["database"]))
+	case "column":
+		return match(argument.Value, c.columns(ctx, resolved["database"], resolved["table"]))
+	case "group_by", "order_by":
+		// Complete the last entry of a comma separated list
+		head, last := "", argument.Value
+		if i := strings.LastIndex(argument.Value, ","); i >= 0 {
+			head, last = argument.Value[:i+1]+" ", strings.TrimSpace(argument.Value[i+1:])
+		}
+		completion := match(last, c.columns(ctx, resolved["database"], resolved["table"]))
+		for i, value := range completion.Values {
+			completion.Values[i] = head + value
+		}
+		return completion
+	case "redis":
+		return match(argument.Value, mapKeys(c.redisClients))
+	case "key", "pattern":
+		return match(argument.Value, c.keyPrefixes(ctx, resolved["redis"], argument.Value))
+	default:
+		return &mcp.Completion{Values: []string{}}
+	}
+}
+
+// tables lists the tables of a database
+func (c *Completer) tables(ctx context.Context, dbName string) []string {
+	repo, ok := c.repositories[dbName]
+	if !ok {
+		return nil
+	}
+
+	return c.cached(ctx, "tables:"+dbName, func() ([]string, error) {
+		switch r := repo.(type) {
+		case *db.MySQLRepository:
+			return r.GetTableList(ctx)
+		case *db.PostgresRepository:
+			return r.GetTableList(ctx)
+		default:
+			return nil, fmt.Errorf("unsupported repository type")
+		}
+	})
+}
+
+// columns lists the columns of a table
+func (c *Completer) columns(ctx context.Context, dbName, tableName string) []string {
+	repo, ok := c.repositories[dbName]
+	if !ok || tableName == "" {
+		return nil
+	}
+
+	return c.cached(ctx, "columns:"+dbName+":"+tableName, func() ([]string, error) {
+		return db.ColumnNames(ctx, repo, tableName)
+	})
+}
+
+// keyPrefixes scans Redis for keys starting with value and collapses them to
+// the next delimiter, so "us" completes to "user:" rather than every user key
+func (c *Completer) keyPrefixes(ctx context.Context, redisName, value string) []string {
+	client, ok := c.redisClients[redisName]
+	if !ok {
+		// Without a resolved instance, a single configured one is unambiguous
+		if redisName != "" || len(c.redisClients) != 1 {
+			return nil
+		}
+		for _, only := range c.redisClients {
+			client = only
+		}
+	}
+
+	return c.cached(ctx, "keys:"+client.GetName()+":"+value, func() ([]string, error) {
+		pattern := escapeGlob(value) + "*"
+		seen := make(map[string]bool)
+		cursor := uint64(0)
+		scanned := 0
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, int64(c.config.ScanCount))
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range keys {
+				seen[collapseKey(key, value)] = true
+			}
+			scanned += len(keys)
+			cursor = next
+			if cursor == 0 || scanned >= c.config.MaxScanKeys || len(seen) >= maxValues {
+				break
+			}
+		}
+		return mapKeys(seen), nil
+	})
+}
+
+// cached returns the candidates stored under key, loading them when missing
+// or expired. Expired entries are dropped when read, and the cache holds at
+// most maxCacheEntries. Failed loads are logged and not cached.
+func (c *Completer) cached(ctx context.Context, key string, load func() ([]string, error)) []string {
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	if ok && !time.Now().Before(entry.expiresAt) {
+		delete(c.cache, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.values
+	}
+
+	values, err := load()
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to load completion candidates", "key", key, "error", err)
+		return nil
+	}
+
+	c.mu.Lock()
+	if len(c.cache) >= maxCacheEntries {
+		c.evict()
+	}
+	c.cache[key] = cacheEntry{
+		values:    values,
+		expiresAt: time.Now().Add(time.Duration(c.config.CacheTTL) * time.Second),
+	}
+	c.mu.Unlock()
+	return values
+}
+
+// evict drops the expired entries of a full cache, or the entry closest to
+// expiry when none has expired. c.mu must be held.
+func (c *Completer) evict() {
+	now := time.Now()
+	oldest := ""
+	for key, entry := range c.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(c.cache, key)
+		} else if oldest == "" || entry.expiresAt.Before(c.cache[oldest].expiresAt) {
+			oldest = key
+		}
+	}
+	if len(c.cache) >= maxCacheEntries {
+		delete(c.cache, oldest)
+	}
+}
+
+// match filters candidates by case-insensitive prefix. When nothing matches,
+// the closest names are offered instead so misspellings still complete.
+func match(value string, candidates []string) *mcp.Completion {
+	lower := strings.ToLower(value)
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), lower) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 && value != "" {
+		matches = append(matches, toolerrors.Suggest(value, candidates, maxValues)...)
+	} else {
+		sort.Strings(matches)
+	}
+
+	completion := &mcp.Completion{Values: matches, Total: len(matches)}
+	if len(matches) > maxValues {
+		completion.Values = matches[:maxValues]
+		completion.HasMore = true
+	}
+	return completion
+}
+
+// collapseKey cuts a key after the first delimiter following the typed prefix
+func collapseKey(key, prefix string) string {
+	if i := strings.Index(key[len(prefix):], keyDelimiter); i >= 0 {
+		return key[:len(prefix)+i+len(keyDelimiter)]
+	}
+	return key
+}
+
+// escapeGlob escapes the Redis MATCH glob characters in a literal prefix
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// mapKeys returns the sorted keys of a map
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
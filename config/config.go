@@ -19,6 +19,10 @@ type Config struct {
 	Databases  DatabasesConfig  `yaml:"databases"`
 	Redis      RedisConfig      `yaml:"redis"`
 	Tools      ToolsConfig      `yaml:"tools"`
+	Prompts    PromptsConfig    `yaml:"prompts"`
+	Completion CompletionConfig `yaml:"completion"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Tracing    TracingConfig    `yaml:"tracing"`
 }
 
 // ServerConfig defines the core server settings
@@ -85,6 +89,29 @@ type InProcessConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// MetricsConfig for the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
+	Port    int    `yaml:"port"`
+	Path    string `yaml:"path"`
+}
+
+// Address returns the full address string (host:port)
+func (m MetricsConfig) Address() string {
+	return fmt.Sprintf("%s:%d", m.Host, m.Port)
+}
+
+// TracingConfig for OpenTelemetry tracing
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Exporter    string  `yaml:"exporter"`     // otlp, stdout, file
+	Endpoint    string  `yaml:"endpoint"`     // OTLP/HTTP collector host:port
+	Insecure    bool    `yaml:"insecure"`     // disable TLS for OTLP
+	FilePath    string  `yaml:"file_path"`    // output file for the file exporter
+	SampleRatio float64 `yaml:"sample_ratio"` // 0 < ratio <= 1
+}
+
 // DatabasesConfig defines all database connections
 type DatabasesConfig struct {
 	MySQL    []MySQLConfig    `yaml:"mysql"`
@@ -93,16 +120,19 @@ type DatabasesConfig struct {
 
 // MySQLConfig for MySQL database connection
 type MySQLConfig struct {
-	Name            string `yaml:"name"`
-	Enabled         bool   `yaml:"enabled"`
-	Host            string `yaml:"host"`
-	Port            int    `yaml:"port"`
-	User            string `yaml:"user"`
-	Password        string `yaml:"password"`
-	Database        string `yaml:"database"`
-	MaxOpenConns    int    `yaml:"max_open_conns"`
-	MaxIdleConns    int    `yaml:"max_idle_conns"`
-	ConnMaxLifetime int    `yaml:"conn_max_lifetime"` // seconds
+	Name            string   `yaml:"name"`
+	Enabled         bool     `yaml:"enabled"`
+	Host            string   `yaml:"host"`
+	Port            int      `yaml:"port"`
+	User            string   `yaml:"user"`
+	Password        string   `yaml:"password"`
+	Database        string   `yaml:"database"`
+	Schemas         []string `yaml:"schemas"`           // databases reachable as schema.table; the first is the default
+	RowCount        string   `yaml:"row_count"`         // none, estimate, exact or exact_below:N
+	RowCountTimeout int      `yaml:"row_count_timeout"` // seconds allowed for an exact count
+	MaxOpenConns    int      `yaml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns"`
+	ConnMaxLifetime int      `yaml:"conn_max_lifetime"` // seconds
 }
 
 // DSN returns MySQL connection string
@@ -113,17 +143,54 @@ func (m MySQLConfig) DSN() string {
 
 // PostgresConfig for PostgreSQL database connection
 type PostgresConfig struct {
-	Name            string `yaml:"name"`
-	Enabled         bool   `yaml:"enabled"`
-	Host            string `yaml:"host"`
-	Port            int    `yaml:"port"`
-	User            string `yaml:"user"`
-	Password        string `yaml:"password"`
-	Database        string `yaml:"database"`
-	SSLMode         string `yaml:"sslmode"`
-	MaxOpenConns    int    `yaml:"max_open_conns"`
-	MaxIdleConns    int    `yaml:"max_idle_conns"`
-	ConnMaxLifetime int    `yaml:"conn_max_lifetime"` // seconds
+	Name            string   `yaml:"name"`
+	Enabled         bool     `yaml:"enabled"`
+	Host            string   `yaml:"host"`
+	Port            int      `yaml:"port"`
+	User            string   `yaml:"user"`
+	Password        string   `yaml:"password"`
+	Database        string   `yaml:"database"`
+	Schemas         []string `yaml:"schemas"`           // schemas reachable as schema.table; the first is the default
+	RowCount        string   `yaml:"row_count"`         // none, estimate, exact or exact_below:N
+	RowCountTimeout int      `yaml:"row_count_timeout"` // seconds allowed for an exact count
+	SSLMode         string   `yaml:"sslmode"`
+	MaxOpenConns    int      `yaml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns"`
+	ConnMaxLifetime int      `yaml:"conn_max_lifetime"` // seconds
+}
+
+// Row count strategies for table metadata
+const (
+	RowCountNone       = "none"        // no row counts
+	RowCountEstimate   = "estimate"    // catalog statistics only
+	RowCountExact      = "exact"       // SELECT COUNT(*) for every table
+	RowCountExactBelow = "exact_below" // exact when the estimate is below a threshold
+)
+
+// RowCountStrategy controls how table row counts are obtained
+type RowCountStrategy struct {
+	Mode      string
+	Threshold int64 // exact_below: tables estimated below this many rows are counted exactly
+}
+
+// ParseRowCountStrategy parses none, estimate, exact or exact_below:N. An
+// empty value selects estimate.
+func ParseRowCountStrategy(value string) (RowCountStrategy, error) {
+	mode, threshold, hasThreshold := strings.Cut(strings.TrimSpace(value), ":")
+	switch mode {
+	case "":
+		return RowCountStrategy{Mode: RowCountEstimate}, nil
+	case RowCountNone, RowCountEstimate, RowCountExact:
+		if !hasThreshold {
+			return RowCountStrategy{Mode: mode}, nil
+		}
+	case RowCountExactBelow:
+		n, err := strconv.ParseInt(threshold, 10, 64)
+		if err == nil && n > 0 {
+			return RowCountStrategy{Mode: mode, Threshold: n}, nil
+		}
+	}
+	return RowCountStrategy{}, fmt.Errorf("invalid row_count %q: expected none, estimate, exact or exact_below:N", value)
 }
 
 // DSN returns PostgreSQL connection string
@@ -185,31 +252,60 @@ type InsightsToolsConfig struct {
 	SemanticSummary SemanticSummaryConfig `yaml:"semantic_summary"`
 	Analytics       AnalyticsConfig       `yaml:"analytics"`
 	Relationship    RelationshipConfig    `yaml:"relationship"`
+	Summarization   SummarizationConfig   `yaml:"summarization"`
 }
 
 // IntrospectionConfig for introspection tool
 type IntrospectionConfig struct {
 	CacheTTL      int  `yaml:"cache_ttl"` // seconds
 	UseRedisCache bool `yaml:"use_redis_cache"`
+	Workers       int  `yaml:"workers"`   // tables introspected concurrently
+	PageSize      int  `yaml:"page_size"` // tables per page of tool output
 }
 
 // SemanticSummaryConfig for semantic summary tool
 type SemanticSummaryConfig struct {
-	SampleSize int `yaml:"sample_size"`
-	MaxColumns int `yaml:"max_columns"`
+	SampleSize int    `yaml:"sample_size"`
+	MaxColumns int    `yaml:"max_columns"`
+	SampleMode string `yaml:"sample_mode"` // first, random, recent or hash; stratified needs a column per call
 }
 
 // AnalyticsConfig for analytics tool
 type AnalyticsConfig struct {
-	MaxResultRows    int `yaml:"max_result_rows"`
-	ExecutionTimeout int `yaml:"execution_timeout"` // seconds
+	MaxResultRows     int `yaml:"max_result_rows"`
+	ExecutionTimeout  int `yaml:"execution_timeout"`   // seconds
+	ProfileSampleRows int `yaml:"profile_sample_rows"` // rows the profile tool samples from larger tables
+	StatsSampleRows   int `yaml:"stats_sample_rows"`   // rows read for percentiles on databases without window functions
 }
 
 // RelationshipConfig for relationship analysis tool
 type RelationshipConfig struct {
-	MaxDepth     int  `yaml:"max_depth"`
-	CacheEnabled bool `yaml:"cache_enabled"`
-	CacheTTL     int  `yaml:"cache_ttl"` // seconds
+	MaxDepth           int     `yaml:"max_depth"`
+	CacheEnabled       bool    `yaml:"cache_enabled"`
+	CacheTTL           int     `yaml:"cache_ttl"`            // seconds
+	Infer              bool    `yaml:"infer"`                // infer relationships from column names, types and sampled values by default
+	InferSampleRows    int     `yaml:"infer_sample_rows"`    // rows whose values are checked against the referenced key
+	InferMinConfidence float64 `yaml:"infer_min_confidence"` // inferred relationships below this confidence are left out
+}
+
+// SummarizationConfig for summaries generated through client sampling
+type SummarizationConfig struct {
+	Enabled   bool `yaml:"enabled"`    // sample summaries by default when the client supports it
+	MaxTokens int  `yaml:"max_tokens"` // token limit of each sampling request
+	CacheTTL  int  `yaml:"cache_ttl"`  // seconds
+}
+
+// PromptsConfig for MCP prompts
+type PromptsConfig struct {
+	// Templates maps a prompt name to a text/template file replacing its built-in body
+	Templates map[string]string `yaml:"templates"`
+}
+
+// CompletionConfig for argument autocompletion
+type CompletionConfig struct {
+	CacheTTL    int `yaml:"cache_ttl"`     // seconds
+	ScanCount   int `yaml:"scan_count"`    // Redis SCAN count per iteration
+	MaxScanKeys int `yaml:"max_scan_keys"` // keys scanned per key prefix completion
 }
 
 // Load reads and parses the configuration file
@@ -289,6 +385,27 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("TOOLS_DB_DRY_RUN"); v != "" {
 		cfg.Tools.DB.DefaultDryRun = strings.ToLower(v) == "true"
 	}
+
+	// Metrics overrides
+	if v := os.Getenv("METRICS_ENABLED"); v != "" {
+		cfg.Metrics.Enabled = strings.ToLower(v) == "true"
+	}
+	if v := os.Getenv("METRICS_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			cfg.Metrics.Port = port
+		}
+	}
+
+	// Tracing overrides
+	if v := os.Getenv("TRACING_ENABLED"); v != "" {
+		cfg.Tracing.Enabled = strings.ToLower(v) == "true"
+	}
+	if v := os.Getenv("TRACING_EXPORTER"); v != "" {
+		cfg.Tracing.Exporter = v
+	}
+	if v := os.Getenv("TRACING_ENDPOINT"); v != "" {
+		cfg.Tracing.Endpoint = v
+	}
 }
 
 // Validate checks if the configuration is valid
@@ -324,6 +441,70 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate metrics endpoint settings
+	if c.Metrics.Enabled {
+		if c.Metrics.Port <= 0 || c.Metrics.Port > 65535 {
+			return fmt.Errorf("invalid metrics port: %d", c.Metrics.Port)
+		}
+	}
+
+	// Validate database schema lists and row count strategies
+	for _, m := range c.Databases.MySQL {
+		if err := validateSchemas(m.Name, m.Schemas); err != nil {
+			return err
+		}
+		if _, err := ParseRowCountStrategy(m.RowCount); err != nil {
+			return fmt.Errorf("database %s: %w", m.Name, err)
+		}
+	}
+	for _, p := range c.Databases.Postgres {
+		if err := validateSchemas(p.Name, p.Schemas); err != nil {
+			return err
+		}
+		if _, err := ParseRowCountStrategy(p.RowCount); err != nil {
+			return fmt.Errorf("database %s: %w", p.Name, err)
+		}
+	}
+
+	// Validate the default sampling mode
+	switch mode := c.Tools.Insights.SemanticSummary.SampleMode; mode {
+	case "", "first", "random", "recent", "hash":
+	default:
+		return fmt.Errorf("invalid semantic_summary sample_mode: %s", mode)
+	}
+
+	// Validate tracing settings
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			if c.Tracing.Endpoint == "" {
+				return fmt.Errorf("tracing endpoint is required for the otlp exporter")
+			}
+		case "stdout":
+			// Stdio transport owns stdout, spans would corrupt the protocol stream
+			if c.Transports.Stdio.Enabled {
+				return fmt.Errorf("stdout trace exporter cannot be used with the stdio transport")
+			}
+		case "file":
+			if c.Tracing.FilePath == "" {
+				return fmt.Errorf("tracing file_path is required for the file exporter")
+			}
+		default:
+			return fmt.Errorf("invalid trace exporter: %s", c.Tracing.Exporter)
+		}
+	}
+
+	return nil
+}
+
+// validateSchemas checks that schema names are usable as the first part of a
+// qualified schema.table name
+func validateSchemas(database string, schemas []string) error {
+	for _, schema := range schemas {
+		if schema == "" || strings.ContainsAny(schema, ".`\"") {
+			return fmt.Errorf("invalid schema %q for database %s", schema, database)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,354 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AggregateFuncs lists the aggregate functions accepted in metrics
+var AggregateFuncs = []string{
+	"COUNT", "COUNT_DISTINCT", "APPROX_COUNT_DISTINCT", "SUM", "AVG", "MIN", "MAX",
+	"MEDIAN", "PERCENTILE", "STDDEV", "VARIANCE",
+}
+
+// percentilePattern matches the PERCENTILE(p) form of a metric function
+var percentilePattern = regexp.MustCompile(`^PERCENTILE\s*\(\s*([0-9]*\.?[0-9]+)\s*\)$`)
+
+// Comparison operators accepted in conditions and HAVING filters
+var comparisonOps = []string{"=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE", "IN", "NOT IN"}
+
+// Metric is one aggregate of an aggregation query
+type Metric struct {
+	Fn     string  `json:"fn"`               // one of AggregateFuncs, or PERCENTILE(p)
+	Column string  `json:"column,omitempty"` // empty or * counts rows
+	Alias  string  `json:"alias,omitempty"`  // result key; defaults to fn_column, or pNN_column for percentiles
+	P      float64 `json:"p,omitempty"`      // PERCENTILE fraction between 0 and 1
+}
+
+// Percentile returns the fraction computed by a MEDIAN or PERCENTILE metric
+func (m Metric) Percentile() (float64, bool) {
+	switch m.Fn {
+	case "MEDIAN":
+		return 0.5, true
+	case "PERCENTILE":
+		return m.P, true
+	default:
+		return 0, false
+	}
+}
+
+// HavingFilter keeps the groups whose metric compares true against a value
+type HavingFilter struct {
+	Metric string `json:"metric"` // metric alias
+	Op     string `json:"op"`
+	Value  any    `json:"value"`
+}
+
+// AggregationSpec describes a multi-metric aggregation query.
+//
+// Condition values select the comparison: a scalar compares with =, nil
+// matches NULL, an array matches any of its values, and an object maps
+// operators to values, e.g. {">=": 10, "<": 20}.
+type AggregationSpec struct {
+	Table      string
+	Metrics    []Metric
+	GroupBy    []string
+	Conditions map[string]any
+	Having     []HavingFilter
+	OrderBy    string // comma separated metric aliases or group-by columns, each optionally ASC or DESC
+	Limit      int
+}
+
+// NormalizeMetric validates a metric and fills in its default alias.
+// COUNT(DISTINCT) may be written as COUNT_DISTINCT or "COUNT DISTINCT", and
+// PERCENTILE takes its fraction as p or as PERCENTILE(0.95). Fractions above
+// one are read as percentages.
+func NormalizeMetric(m Metric) (Metric, error) {
+	fn := strings.ToUpper(strings.TrimSpace(m.Fn))
+	if match := percentilePattern.FindStringSubmatch(fn); match != nil {
+		p, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return m, fmt.Errorf("invalid percentile: %s", m.Fn)
+		}
+		fn, m.P = "PERCENTILE", p
+	}
+	fn = strings.NewReplacer("(", " ", ")", " ").Replace(fn)
+	fn = strings.Join(strings.Fields(fn), "_")
+	if !slices.Contains(AggregateFuncs, fn) {
+		return m, fmt.Errorf("invalid aggregate function: %s (must be one of %s)", m.Fn, strings.Join(AggregateFuncs, ", "))
+	}
+	m.Fn = fn
+
+	if m.Column == "*" {
+		m.Column = ""
+	}
+	if m.Column == "" && fn != "COUNT" {
+		return m, fmt.Errorf("%s requires a column", fn)
+	}
+
+	if fn == "PERCENTILE" {
+		if m.P > 1 && m.P <= 100 {
+			m.P /= 100
+		}
+		if m.P < 0 || m.P > 1 || (m.P == 0 && !strings.Contains(m.Fn, "(")) {
+			return m, fmt.Errorf("PERCENTILE requires p between 0 and 1, e.g. PERCENTILE(0.95)")
+		}
+	} else if m.P != 0 {
+		return m, fmt.Errorf("p only applies to PERCENTILE, not %s", fn)
+	}
+
+	if m.Alias == "" {
+		m.Alias = strings.ToLower(fn)
+		if fn == "PERCENTILE" {
+			// p95, p99_9: the percentage with at most four decimals
+			percent := strconv.FormatFloat(math.Round(m.P*1e6)/1e4, 'f', -1, 64)
+			m.Alias = "p" + strings.ReplaceAll(percent, ".", "_")
+		}
+		if m.Column != "" {
+			m.Alias += "_" + strings.ReplaceAll(m.Column, ".", "_")
+		}
+	}
+	if strings.Contains(m.Alias, ".") || !(&QueryBuilder{}).isValidIdentifier(m.Alias) {
+		return m, fmt.Errorf("invalid metric alias: %q", m.Alias)
+	}
+	return m, nil
+}
+
+// BuildAggregationQuery builds a parameterized multi-metric aggregation query.
+// Metrics are normalized as by NormalizeMetric; group-by columns come first in
+// the select list, followed by the metrics under their aliases. On MySQL,
+// percentiles are emulated with window functions over a ranked derived table,
+// which needs MySQL 8.0 or MariaDB 10.2; see SupportsWindowFunctions.
+func (qb *QueryBuilder) BuildAggregationQuery(spec AggregationSpec) (string, []any, error) {
+	if len(spec.Metrics) == 0 {
+		return "", nil, fmt.Errorf("at least one metric is required")
+	}
+
+	var selectList []string
+	groupBy := make([]string, len(spec.GroupBy))
+	outputs := make(map[string]bool)
+	for i, column := range spec.GroupBy {
+		if !qb.isValidIdentifier(column) {
+			return "", nil, fmt.Errorf("invalid group_by column: %q", column)
+		}
+		groupBy[i] = qb.quoteIdentifier(column)
+		selectList = append(selectList, groupBy[i])
+		outputs[column] = true
+	}
+
+	expressions := make(map[string]string, len(spec.Metrics))
+	var windows []string
+	ranked := make(map[string]int) // percentile column -> index of its rank and count columns
+	for _, metric := range spec.Metrics {
+		m, err := NormalizeMetric(metric)
+		if err != nil {
+			return "", nil, err
+		}
+		if outputs[m.Alias] {
+			return "", nil, fmt.Errorf("duplicate result column %q; give the metric a distinct alias", m.Alias)
+		}
+		if m.Column != "" && !qb.isValidIdentifier(m.Column) {
+			return "", nil, fmt.Errorf("invalid metric column: %q", m.Column)
+		}
+
+		expressions[m.Alias] = qb.aggregateExpr(m)
+		if p, ok := m.Percentile(); ok && qb.driver != "postgres" {
+			i, seen := ranked[m.Column]
+			if !seen {
+				i = len(ranked)
+				ranked[m.Column] = i
+				windows = append(windows, qb.percentileWindows(m.Column, groupBy, i))
+			}
+			expressions[m.Alias] = qb.windowPercentile(m.Column, p, i)
+		}
+		selectList = append(selectList, fmt.Sprintf("%s AS %s", expressions[m.Alias], qb.quoteIdentifier(m.Alias)))
+		outputs[m.Alias] = true
+	}
+
+	var params []any
+	where, params, err := qb.buildConditions(spec.Conditions, params)
+	if err != nil {
+		return "", nil, err
+	}
+	source := qb.quoteIdentifier(spec.Table)
+	if len(where) > 0 {
+		source += " WHERE " + strings.Join(where, " AND ")
+	}
+	if len(windows) > 0 {
+		source = fmt.Sprintf("(SELECT *, %s FROM %s) AS ranked", strings.Join(windows, ", "), source)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), source)
+
+	if len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+
+	// HAVING repeats the aggregate, since PostgreSQL does not resolve aliases there
+	var having []string
+	for _, filter := range spec.Having {
+		expr, ok := expressions[filter.Metric]
+		if !ok {
+			return "", nil, fmt.Errorf("having refers to unknown metric %q", filter.Metric)
+		}
+		var clause string
+		clause, params, err = qb.comparison(expr, filter.Op, filter.Value, params)
+		if err != nil {
+			return "", nil, err
+		}
+		having = append(having, clause)
+	}
+	if len(having) > 0 {
+		query += " HAVING " + strings.Join(having, " AND ")
+	}
+
+	if spec.OrderBy != "" {
+		orderBy, err := qb.orderByOutputs(spec.OrderBy, outputs)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " ORDER BY " + orderBy
+	}
+
+	if spec.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", spec.Limit)
+	}
+
+	return query, params, nil
+}
+
+// aggregateExpr returns the SQL aggregate of a normalized metric. Percentiles
+// use percentile_cont, which only PostgreSQL has; see windowPercentile.
+// APPROX_COUNT_DISTINCT counts exactly here; without group-by it is estimated
+// from BuildDistinctSketchWhere instead.
+func (qb *QueryBuilder) aggregateExpr(m Metric) string {
+	if m.Column == "" {
+		return "COUNT(*)"
+	}
+	return qb.aggregateOf(m, qb.quoteIdentifier(m.Column))
+}
+
+// aggregateOf returns the aggregate of a normalized metric applied to an
+// expression instead of its column
+func (qb *QueryBuilder) aggregateOf(m Metric, expr string) string {
+	switch m.Fn {
+	case "COUNT_DISTINCT", "APPROX_COUNT_DISTINCT":
+		return fmt.Sprintf("COUNT(DISTINCT %s)", expr)
+	case "STDDEV":
+		return fmt.Sprintf("STDDEV_SAMP(%s)", expr)
+	case "VARIANCE":
+		return fmt.Sprintf("VAR_SAMP(%s)", expr)
+	case "MEDIAN", "PERCENTILE":
+		p, _ := m.Percentile()
+		return fmt.Sprintf("percentile_cont(%s) WITHIN GROUP (ORDER BY %s)", formatFraction(p), expr)
+	default:
+		return fmt.Sprintf("%s(%s)", m.Fn, expr)
+	}
+}
+
+// buildConditions builds WHERE clauses for conditions in column order,
+// appending their values to params
+func (qb *QueryBuilder) buildConditions(conditions map[string]any, params []any) ([]string, []any, error) {
+	columns := make([]string, 0, len(conditions))
+	for column := range conditions {
+		if !qb.isValidIdentifier(column) {
+			return nil, nil, fmt.Errorf("invalid condition column: %q", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var clauses []string
+	for _, column := range columns {
+		col := qb.quoteIdentifier(column)
+		var clause string
+		var err error
+		switch value := conditions[column].(type) {
+		case nil:
+			clause = col + " IS NULL"
+		case []any:
+			clause, params, err = qb.comparison(col, "IN", value, params)
+		case map[string]any:
+			ops := make([]string, 0, len(value))
+			for op := range value {
+				ops = append(ops, op)
+			}
+			sort.Strings(ops)
+			parts := make([]string, 0, len(ops))
+			for _, op := range ops {
+				var part string
+				part, params, err = qb.comparison(col, op, value[op], params)
+				if err != nil {
+					break
+				}
+				parts = append(parts, part)
+			}
+			clause = strings.Join(parts, " AND ")
+		default:
+			clause, params, err = qb.comparison(col, "=", value, params)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("condition on %s: %w", column, err)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, params, nil
+}
+
+// comparison builds "expr op placeholder", appending the value to params.
+// IN and NOT IN take an array of values.
+func (qb *QueryBuilder) comparison(expr, op string, value any, params []any) (string, []any, error) {
+	op = strings.Join(strings.Fields(strings.ToUpper(op)), " ")
+	if !slices.Contains(comparisonOps, op) {
+		return "", nil, fmt.Errorf("invalid operator %q (must be one of %s)", op, strings.Join(comparisonOps, ", "))
+	}
+
+	if op == "IN" || op == "NOT IN" {
+		values, ok := value.([]any)
+		if !ok || len(values) == 0 {
+			return "", nil, fmt.Errorf("%s needs a non-empty array of values", op)
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			params = append(params, v)
+			placeholders[i] = qb.placeholder(len(params))
+		}
+		return fmt.Sprintf("%s %s (%s)", expr, op, strings.Join(placeholders, ", ")), params, nil
+	}
+
+	switch value.(type) {
+	case nil, []any, map[string]any:
+		return "", nil, fmt.Errorf("%s needs a scalar value", op)
+	}
+	params = append(params, value)
+	return fmt.Sprintf("%s %s %s", expr, op, qb.placeholder(len(params))), params, nil
+}
+
+// orderByOutputs validates an ORDER BY list against the result columns and
+// quotes its keys
+func (qb *QueryBuilder) orderByOutputs(orderBy string, outputs map[string]bool) (string, error) {
+	var terms []string
+	for _, part := range strings.Split(orderBy, ",") {
+		tokens := strings.Fields(part)
+		if len(tokens) == 0 || len(tokens) > 2 {
+			return "", fmt.Errorf("invalid order_by term: %q", strings.TrimSpace(part))
+		}
+		if !outputs[tokens[0]] {
+			return "", fmt.Errorf("order_by %q is neither a metric alias nor a group_by column", tokens[0])
+		}
+		term := qb.quoteIdentifier(tokens[0])
+		if len(tokens) == 2 {
+			direction := strings.ToUpper(tokens[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", fmt.Errorf("invalid order_by direction: %q", tokens[1])
+			}
+			term += " " + direction
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// killTimeout bounds the server-side cancellation of an abandoned statement
+const killTimeout = 5 * time.Second
+
+// statementTagPrefix marks statements issued by this server. The tag carries
+// no user input and contains no LIKE wildcards.
+const statementTagPrefix = "/* mcp-localbridge:"
+
+// killFunc cancels the running statements whose text starts with tag
+type killFunc func(ctx context.Context, tag string) error
+
+// watchCancellation tags a statement and, if ctx is cancelled before stop is
+// called, cancels it server-side. Dropping the client connection alone leaves
+// the statement running on the database. A cancellation after stop, such as
+// the deferred cancel of a handler, leaves the finished statement alone.
+func watchCancellation(ctx context.Context, query string, kill killFunc) (string, func()) {
+	// Nothing to watch without a cancellable context, and an already cancelled
+	// one makes the driver return before the statement is sent
+	if ctx.Done() == nil || ctx.Err() != nil {
+		return query, func() {}
+	}
+
+	tag := statementTagPrefix + uuid.NewString() + " */"
+	stop := context.AfterFunc(ctx, func() {
+		// Keep the trace parent but not the cancellation
+		killCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killTimeout)
+		defer cancel()
+
+		killCtx, span := tracing.Start(killCtx, "db.cancel")
+		err := kill(killCtx, tag)
+		tracing.End(span, err)
+	})
+
+	return tag + " " + query, func() { stop() }
+}
+
+// killMySQL runs KILL QUERY for every connection executing a tagged statement
+func killMySQL(ctx context.Context, r *MySQLRepository, tag string) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM information_schema.processlist WHERE info LIKE ? AND id <> CONNECTION_ID()`,
+		tag+"%")
+	if err != nil {
+		return fmt.Errorf("failed to find running statement: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan connection id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating connection ids: %w", err)
+	}
+
+	for _, id := range ids {
+		// KILL does not accept placeholders; id is an integer read from the server
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id)); err != nil {
+			return fmt.Errorf("failed to kill query on connection %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// cancelPostgres calls pg_cancel_backend for every backend executing a tagged statement
+func cancelPostgres(ctx context.Context, r *PostgresRepository, tag string) error {
+	_, err := r.db.ExecContext(ctx,
+		`SELECT pg_cancel_backend(pid) FROM pg_stat_activity WHERE query LIKE $1 AND pid <> pg_backend_pid()`,
+		tag+"%")
+	if err != nil {
+		return fmt.Errorf("failed to cancel backend: %w", err)
+	}
+	return nil
+}
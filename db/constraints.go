@@ -0,0 +1,105 @@
+package db
+
+import (
+	"slices"
+	"strings"
+)
+
+// Catalog queries return one row per key column. These helpers fold rows that
+// belong to the same index or constraint into a single entry; rows must be
+// ordered by name and then by column position.
+
+// appendForeignKeyColumn adds a key column pair to the last foreign key when
+// it belongs to the same constraint, or starts a new foreign key
+func appendForeignKeyColumn(fks []ForeignKeyInfo, fk ForeignKeyInfo, sourceColumn, referencedColumn string) []ForeignKeyInfo {
+	if n := len(fks); n > 0 && fks[n-1].Name == fk.Name && fks[n-1].SourceTable == fk.SourceTable {
+		fks[n-1].SourceColumns = append(fks[n-1].SourceColumns, sourceColumn)
+		fks[n-1].ReferencedColumns = append(fks[n-1].ReferencedColumns, referencedColumn)
+		fks[n-1].SourceColumn = strings.Join(fks[n-1].SourceColumns, ", ")
+		fks[n-1].ReferencedColumn = strings.Join(fks[n-1].ReferencedColumns, ", ")
+		return fks
+	}
+	fk.SourceColumn = sourceColumn
+	fk.SourceColumns = []string{sourceColumn}
+	fk.ReferencedColumn = referencedColumn
+	fk.ReferencedColumns = []string{referencedColumn}
+	return append(fks, fk)
+}
+
+// appendIndexColumn adds a column to the last index when it has the same
+// name, or starts a new index
+func appendIndexColumn(indexes []IndexInfo, index IndexInfo, column, order string) []IndexInfo {
+	if n := len(indexes); n > 0 && indexes[n-1].Name == index.Name {
+		indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+		indexes[n-1].ColumnOrders = append(indexes[n-1].ColumnOrders, order)
+		return indexes
+	}
+	index.Columns = []string{column}
+	index.ColumnOrders = []string{order}
+	return append(indexes, index)
+}
+
+// appendConstraintColumn adds a column to the last constraint when it has
+// the same name, or starts a new constraint. Check constraints that name no
+// columns pass an empty column.
+func appendConstraintColumn(constraints []ConstraintInfo, constraint ConstraintInfo, column string) []ConstraintInfo {
+	n := len(constraints)
+	if n == 0 || constraints[n-1].Name != constraint.Name {
+		constraints = append(constraints, constraint)
+		n++
+	}
+	if column != "" {
+		constraints[n-1].Columns = append(constraints[n-1].Columns, column)
+	}
+	return constraints
+}
+
+// primaryKeyColumns returns the columns of the primary key constraint, in key order
+func primaryKeyColumns(constraints []ConstraintInfo) []string {
+	for _, c := range constraints {
+		if c.Type == ConstraintPrimaryKey {
+			return c.Columns
+		}
+	}
+	return nil
+}
+
+// markPrimaryKey flags the columns that belong to the primary key
+func markPrimaryKey(columns []ColumnInfo, primaryKey []string) {
+	for i := range columns {
+		columns[i].IsPrimaryKey = slices.Contains(primaryKey, columns[i].Name)
+	}
+}
+
+// postgresReferentialAction maps pg_constraint.confdeltype/confupdtype codes
+// to their SQL names
+func postgresReferentialAction(code string) string {
+	switch code {
+	case "a":
+		return "NO ACTION"
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	default:
+		return ""
+	}
+}
+
+// postgresConstraintType maps pg_constraint.contype codes to constraint types
+func postgresConstraintType(code string) string {
+	switch code {
+	case "p":
+		return ConstraintPrimaryKey
+	case "u":
+		return ConstraintUnique
+	case "c":
+		return ConstraintCheck
+	default:
+		return ""
+	}
+}
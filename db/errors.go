@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// DatabaseNotFoundError creates a DATABASE_NOT_FOUND error listing the available
+// databases and the closest matches to dbName.
+// This is a shared utility function used by tools and insights handlers
+func DatabaseNotFoundError(dbName string, repositories map[string]Repository) *toolerrors.ToolError {
+	available := make([]string, 0, len(repositories))
+	for name := range repositories {
+		available = append(available, name)
+	}
+	return toolerrors.NotFound(toolerrors.CodeDatabaseNotFound, "database", dbName, available)
+}
+
+// ValidateIdentifier returns an INVALID_IDENTIFIER error if name is not a safe
+// SQL identifier. kind describes the identifier in the message (table, column...).
+func ValidateIdentifier(kind, name string) error {
+	qb := &QueryBuilder{}
+	if !qb.isValidIdentifier(name) {
+		return toolerrors.Newf(toolerrors.CodeInvalidIdentifier, "invalid %s name: %q", kind, name).
+			WithTarget(name).
+			WithHint("Identifiers may only contain letters, digits, underscores and dots.")
+	}
+	return nil
+}
+
+// ClassifyError maps a driver error into a ToolError. Unknown table and column
+// errors are enriched with the nearest existing names from the database.
+func ClassifyError(ctx context.Context, repo Repository, table string, err error) *toolerrors.ToolError {
+	te := toolerrors.FromDBError(err)
+	if te == nil || te.Target == "" {
+		return te
+	}
+
+	switch te.Code {
+	case toolerrors.CodeUnknownTable:
+		if tables, listErr := tableNames(ctx, repo); listErr == nil {
+			te.WithSuggestions(tables)
+		}
+	case toolerrors.CodeUnknownColumn:
+		if table == "" {
+			break
+		}
+		if columns, listErr := ColumnNames(ctx, repo, table); listErr == nil && len(columns) > 0 {
+			te.WithSuggestions(columns)
+		}
+	}
+	return te
+}
+
+// TableNotFoundError creates an UNKNOWN_TABLE error with the nearest existing table names
+func TableNotFoundError(ctx context.Context, repo Repository, table string) *toolerrors.ToolError {
+	te := toolerrors.Newf(toolerrors.CodeUnknownTable, "table '%s' not found in database '%s'", table, repo.GetName()).
+		WithTarget(table).
+		WithHint("Use db_table_list to see the available tables.")
+	if tables, err := tableNames(ctx, repo); err == nil {
+		te.WithSuggestions(tables)
+	}
+	return te
+}
+
+// tableNames lists the tables of a repository, if the repository supports it
+func tableNames(ctx context.Context, repo Repository) ([]string, error) {
+	switch r := repo.(type) {
+	case *MySQLRepository:
+		return r.GetTableList(ctx)
+	case *PostgresRepository:
+		return r.GetTableList(ctx)
+	default:
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// fingerprints hashes catalog rows per table. Rows must arrive ordered by
+// table so that equal definitions always produce the same fingerprint.
+type fingerprints map[string]hash.Hash
+
+// add feeds one catalog row of a table into its hash
+func (f fingerprints) add(table string, parts ...string) {
+	h, ok := f[table]
+	if !ok {
+		h = sha256.New()
+		f[table] = h
+	}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{'\n'})
+}
+
+// sums returns the hex fingerprint of every table
+func (f fingerprints) sums() map[string]string {
+	sums := make(map[string]string, len(f))
+	for table, h := range f {
+		sums[table] = hex.EncodeToString(h.Sum(nil)[:8])
+	}
+	return sums
+}
+
+// scanFingerprints reads rows of (schema, table, part...) into per-table
+// fingerprints keyed by the table name qualify returns
+func scanFingerprints(rows *Rows, width int, qualify func(schema, table string) string) (map[string]string, error) {
+	defer rows.Close()
+
+	f := fingerprints{}
+	values := make([]sql.NullString, width)
+	dest := make([]any, width)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan table fingerprint: %w", err)
+		}
+		parts := make([]string, 0, width-2)
+		for _, v := range values[2:] {
+			parts = append(parts, v.String)
+		}
+		f.add(qualify(values[0].String, values[1].String), parts...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table fingerprints: %w", err)
+	}
+	return f.sums(), nil
+}
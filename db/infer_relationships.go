@@ -0,0 +1,341 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// How the name of an inferred foreign key column matches the referenced table
+const (
+	NameMatchExact  = "exact"  // user_id, userId -> users.id; customer_id -> customers.customer_id
+	NameMatchPlural = "plural" // users_id -> users.id, or a plural table name taken as is
+	NameMatchRole   = "role"   // created_by_user_id -> users.id
+)
+
+// How the column type of an inferred foreign key matches the referenced key
+const (
+	TypeMatchIdentical  = "identical"  // same data type
+	TypeMatchCompatible = "compatible" // same column kind, e.g. int and bigint
+)
+
+// MinContainment is the share of sampled values that must be found in the
+// referenced key for an inferred foreign key to be kept
+const MinContainment = 0.5
+
+// InferredForeignKey is a foreign key proposed from column names, types and,
+// once checked, the share of sampled values found in the referenced key.
+// Confidence ranges from 0 to 1: names and types alone reach at most 0.4,
+// the containment check adds up to 0.6.
+type InferredForeignKey struct {
+	ForeignKeyInfo
+	Inferred      bool     `json:"inferred"`
+	Confidence    float64  `json:"confidence"`
+	NameMatch     string   `json:"name_match"`
+	TypeMatch     string   `json:"type_match"`
+	Containment   *float64 `json:"containment,omitempty"` // share of sampled distinct values found in the referenced key
+	SampledValues int64    `json:"sampled_values,omitempty"`
+}
+
+// InferForeignKeys proposes foreign keys from column naming conventions:
+// a column named after a table with a single-column primary key, in singular
+// or plural form and optionally prefixed by a role, and of a compatible type.
+// tables maps table names, as in ForeignKeyInfo, to their info; columns with
+// a declared foreign key to the same table are skipped. A non-empty table
+// limits the candidates to its own columns and the keys referencing it.
+// Candidates are ordered by table and column, and carry the confidence of the
+// name and type until ApplyContainment adds the sampled evidence.
+func InferForeignKeys(tables map[string]*TableInfo, declared []ForeignKeyInfo, table string) []InferredForeignKey {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// Referenced keys: each table with a single-column primary key
+	type target struct {
+		table    string
+		key      ColumnInfo
+		variants [][]string // name tokens of the key column, by match kind
+		matches  []string
+	}
+	var targets []target
+	for _, name := range names {
+		key, ok := singlePrimaryKey(tables[name])
+		if !ok {
+			continue
+		}
+		t := target{table: name, key: key}
+		keyTokens := nameTokens(key.Name)
+		for _, v := range tableNameVariants(name) {
+			full := slices.Concat(v.tokens, keyTokens)
+			if hasTokenPrefix(keyTokens, v.tokens) {
+				full = keyTokens // customers.customer_id
+			}
+			if !slices.ContainsFunc(t.variants, func(existing []string) bool { return slices.Equal(existing, full) }) {
+				t.variants = append(t.variants, full)
+				t.matches = append(t.matches, v.match)
+			}
+		}
+		targets = append(targets, t)
+	}
+
+	declaredKey := func(source, column, referenced string) bool {
+		return slices.ContainsFunc(declared, func(fk ForeignKeyInfo) bool {
+			return fk.SourceTable == source && fk.ReferencedTable == referenced &&
+				(fk.SourceColumn == column || slices.Contains(fk.SourceColumns, column))
+		})
+	}
+
+	var inferred []InferredForeignKey
+	for _, name := range names {
+		for _, col := range tables[name].Columns {
+			tokens := nameTokens(col.Name)
+			for _, t := range targets {
+				if name == t.table && col.Name == t.key.Name {
+					continue
+				}
+				if table != "" && name != table && t.table != table {
+					continue
+				}
+				nameMatch := ""
+				for i, full := range t.variants {
+					switch {
+					case slices.Equal(tokens, full):
+						nameMatch = t.matches[i]
+					case nameMatch == "" && len(tokens) > len(full) && slices.Equal(tokens[len(tokens)-len(full):], full):
+						nameMatch = NameMatchRole
+					default:
+						continue
+					}
+					if nameMatch == NameMatchExact {
+						break
+					}
+				}
+				if nameMatch == "" {
+					continue
+				}
+				typeMatch := columnTypeMatch(col.DataType, t.key.DataType)
+				if typeMatch == "" || declaredKey(name, col.Name, t.table) {
+					continue
+				}
+
+				fk := InferredForeignKey{
+					ForeignKeyInfo: ForeignKeyInfo{
+						Name:              fmt.Sprintf("inferred_%s_%s", bareTableName(name), col.Name),
+						SourceTable:       name,
+						SourceColumn:      col.Name,
+						SourceColumns:     []string{col.Name},
+						ReferencedTable:   t.table,
+						ReferencedColumn:  t.key.Name,
+						ReferencedColumns: []string{t.key.Name},
+					},
+					Inferred:  true,
+					NameMatch: nameMatch,
+					TypeMatch: typeMatch,
+				}
+				fk.Confidence = roundConfidence(0.4 * fk.nameTypeScore())
+				inferred = append(inferred, fk)
+			}
+		}
+	}
+	return inferred
+}
+
+// ApplyContainment adds the result of a containment check: sampled distinct
+// values of the column, of which matched were found in the referenced key.
+// It reports false when too few values match for the key to be kept, or
+// when the sample is empty and gives no evidence for it.
+func (fk *InferredForeignKey) ApplyContainment(sampled, matched int64) bool {
+	if sampled <= 0 {
+		return false
+	}
+	ratio := float64(matched) / float64(sampled)
+	fk.Containment = &ratio
+	fk.SampledValues = sampled
+	if ratio < MinContainment {
+		return false
+	}
+	fk.Confidence = roundConfidence(0.4*fk.nameTypeScore() + 0.6*ratio)
+	return true
+}
+
+// nameTypeScore weighs the name and type evidence of an inferred foreign key
+func (fk *InferredForeignKey) nameTypeScore() float64 {
+	score := 1.0
+	switch fk.NameMatch {
+	case NameMatchPlural:
+		score = 0.9
+	case NameMatchRole:
+		score = 0.7
+	}
+	if fk.TypeMatch == TypeMatchCompatible {
+		score *= 0.8
+	}
+	return score
+}
+
+// BuildContainmentCheck builds a single-row query returning the number of
+// distinct non-null values of a column among its first limit rows, as
+// sampled, and how many of them the referenced key holds, as matched
+func (qb *QueryBuilder) BuildContainmentCheck(table, column, referencedTable, referencedColumn string, limit int) string {
+	col := qb.quoteIdentifier(column)
+	sample := fmt.Sprintf("SELECT DISTINCT sample_value FROM (SELECT %s AS sample_value FROM %s WHERE %s IS NOT NULL LIMIT %d) AS sample_rows",
+		col, qb.quoteIdentifier(table), col, limit)
+	key := qb.quoteIdentifier("referenced_key." + referencedColumn)
+	return fmt.Sprintf("SELECT COUNT(*) AS sampled, COUNT(%s) AS matched FROM (%s) AS sample_values LEFT JOIN %s AS referenced_key ON %s = sample_values.sample_value",
+		key, sample, qb.quoteIdentifier(referencedTable), key)
+}
+
+// singlePrimaryKey returns the primary key column of a table keyed by one column
+func singlePrimaryKey(info *TableInfo) (ColumnInfo, bool) {
+	key := info.PrimaryKey
+	if len(key) == 0 {
+		for _, col := range info.Columns {
+			if col.IsPrimaryKey {
+				key = append(key, col.Name)
+			}
+		}
+	}
+	if len(key) != 1 {
+		return ColumnInfo{}, false
+	}
+	i := slices.IndexFunc(info.Columns, func(col ColumnInfo) bool { return col.Name == key[0] })
+	if i < 0 {
+		return ColumnInfo{}, false
+	}
+	return info.Columns[i], true
+}
+
+// tableNameVariant is a form of a table name columns may refer to it by
+type tableNameVariant struct {
+	tokens []string
+	match  string
+}
+
+// tableNameVariants returns the singular form of a table name, as an exact
+// match, then the plural and unchanged forms. Only the last word changes
+// number: order_items -> order_item.
+func tableNameVariants(table string) []tableNameVariant {
+	tokens := nameTokens(bareTableName(table))
+	if len(tokens) == 0 {
+		return nil
+	}
+	last := len(tokens) - 1
+	withLast := func(word string) []string {
+		return append(slices.Clone(tokens[:last]), word)
+	}
+	singular := SingularName(tokens[last])
+	return []tableNameVariant{
+		{withLast(singular), NameMatchExact},
+		{withLast(PluralName(singular)), NameMatchPlural},
+		{tokens, NameMatchPlural},
+	}
+}
+
+// irregularPlurals maps the singular of common irregular nouns to their plural
+var irregularPlurals = map[string]string{
+	"person": "people",
+	"child":  "children",
+	"man":    "men",
+	"woman":  "women",
+	"datum":  "data",
+}
+
+// SingularName returns the singular of a lower-case English noun, by the
+// common pluralization rules: categories -> category, addresses -> address,
+// boxes -> box, users -> user
+func SingularName(word string) string {
+	for singular, plural := range irregularPlurals {
+		if word == plural {
+			return singular
+		}
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "uses"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && len(word) > 1:
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// PluralName returns the plural of a lower-case English noun in singular:
+// category -> categories, address -> addresses, user -> users
+func PluralName(word string) string {
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// nameTokens splits a snake_case or camelCase name into lower-case words:
+// customerID -> customer, id
+func nameTokens(name string) []string {
+	var tokens []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return tokens
+}
+
+// hasTokenPrefix reports whether tokens start with prefix and go on beyond it
+func hasTokenPrefix(tokens, prefix []string) bool {
+	return len(tokens) > len(prefix) && slices.Equal(tokens[:len(prefix)], prefix)
+}
+
+// columnTypeMatch compares the data types of a column and a referenced key
+func columnTypeMatch(columnType, keyType string) string {
+	c, k := strings.ToLower(columnType), strings.ToLower(keyType)
+	switch kind := ColumnKind(c); {
+	case c == k:
+		return TypeMatchIdentical
+	case kind == ColumnKind(k) && (kind == ColumnKindNumeric || kind == ColumnKindString):
+		return TypeMatchCompatible
+	default:
+		return ""
+	}
+}
+
+// bareTableName drops the schema of a schema-qualified table name
+func bareTableName(table string) string {
+	return table[strings.LastIndex(table, ".")+1:]
+}
+
+// roundConfidence rounds a confidence to two decimals
+func roundConfidence(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Directions of a join step along its foreign key
+const (
+	JoinOutbound = "outbound" // from the referencing table to the referenced one
+	JoinInbound  = "inbound"  // from the referenced table to a referencing one
+)
+
+// maxJoinPaths bounds the shortest paths enumerated between two tables
+const maxJoinPaths = 50
+
+// JoinStep joins ToTable to FromTable along one foreign key
+type JoinStep struct {
+	Constraint  string   `json:"constraint"`
+	FromTable   string   `json:"from_table"`
+	FromColumns []string `json:"from_columns"`
+	ToTable     string   `json:"to_table"`
+	ToColumns   []string `json:"to_columns"`
+	Direction   string   `json:"direction"`
+}
+
+// Predicate returns the join condition of the step, e.g.
+// orders.customer_id = customers.id
+func (s JoinStep) Predicate() string {
+	parts := make([]string, len(s.FromColumns))
+	for i := range s.FromColumns {
+		parts[i] = fmt.Sprintf("%s.%s = %s.%s", s.FromTable, s.FromColumns[i], s.ToTable, s.ToColumns[i])
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// FormatJoinPath describes a join path, e.g.
+// orders -[fk_orders_customer]-> customers <-[fk_addresses_customer]- addresses
+func FormatJoinPath(path []JoinStep) string {
+	if len(path) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(path[0].FromTable)
+	for _, step := range path {
+		if step.Direction == JoinOutbound {
+			fmt.Fprintf(&b, " -[%s]-> %s", step.Constraint, step.ToTable)
+		} else {
+			fmt.Fprintf(&b, " <-[%s]- %s", step.Constraint, step.ToTable)
+		}
+	}
+	return b.String()
+}
+
+// JoinGraph holds the join steps leaving each table: outbound along its own
+// foreign keys and inbound along the foreign keys referencing it
+type JoinGraph map[string][]JoinStep
+
+// NewJoinGraph builds the join graph of foreign keys. Self-references are
+// left out, since a join path visits each table once.
+func NewJoinGraph(fks []ForeignKeyInfo) JoinGraph {
+	graph := make(JoinGraph)
+	for _, fk := range fks {
+		if fk.SourceTable == fk.ReferencedTable {
+			continue
+		}
+		graph[fk.SourceTable] = append(graph[fk.SourceTable], JoinStep{
+			Constraint:  fk.Name,
+			FromTable:   fk.SourceTable,
+			FromColumns: fk.SourceColumns,
+			ToTable:     fk.ReferencedTable,
+			ToColumns:   fk.ReferencedColumns,
+			Direction:   JoinOutbound,
+		})
+		graph[fk.ReferencedTable] = append(graph[fk.ReferencedTable], JoinStep{
+			Constraint:  fk.Name,
+			FromTable:   fk.ReferencedTable,
+			FromColumns: fk.ReferencedColumns,
+			ToTable:     fk.SourceTable,
+			ToColumns:   fk.SourceColumns,
+			Direction:   JoinInbound,
+		})
+	}
+	return graph
+}
+
+// ShortestPaths returns every shortest join path, of at most maxDepth steps,
+// from any of the given tables to another table, up to maxJoinPaths paths.
+// Paths are breadth-first over both directions of each foreign key and come
+// in a stable order. A target among the start tables has one empty path.
+func (g JoinGraph) ShortestPaths(from []string, to string, maxDepth int) [][]JoinStep {
+	if slices.Contains(from, to) {
+		return [][]JoinStep{{}}
+	}
+
+	// Breadth-first search recording every step that reaches a table at
+	// its shortest distance
+	depth := make(map[string]int, len(from))
+	for _, table := range from {
+		depth[table] = 0
+	}
+	reached := make(map[string][]JoinStep)
+	frontier := slices.Clone(from)
+	for d := 1; d <= maxDepth && len(frontier) > 0; d++ {
+		var next []string
+		for _, table := range frontier {
+			for _, step := range g[table] {
+				seen, ok := depth[step.ToTable]
+				if !ok {
+					depth[step.ToTable] = d
+					next = append(next, step.ToTable)
+				} else if seen != d {
+					continue
+				}
+				reached[step.ToTable] = append(reached[step.ToTable], step)
+			}
+		}
+		if _, ok := depth[to]; ok {
+			break
+		}
+		frontier = next
+	}
+	if _, ok := depth[to]; !ok {
+		return nil
+	}
+
+	// Walk the recorded steps back from the target
+	var paths [][]JoinStep
+	var walk func(table string, suffix []JoinStep)
+	walk = func(table string, suffix []JoinStep) {
+		if len(paths) >= maxJoinPaths {
+			return
+		}
+		if depth[table] == 0 {
+			paths = append(paths, slices.Clone(suffix))
+			return
+		}
+		for _, step := range reached[table] {
+			walk(step.FromTable, append([]JoinStep{step}, suffix...))
+		}
+	}
+	walk(to, nil)
+	return paths
+}
+
+// foreignKeyReader is implemented by the repositories that read foreign keys
+type foreignKeyReader interface {
+	GetAllForeignKeys(ctx context.Context) ([]ForeignKeyInfo, error)
+}
+
+// LoadJoinGraph reads the foreign keys of every table in the configured
+// schemas, in one catalog query, and builds their join graph
+func LoadJoinGraph(ctx context.Context, repo Repository) (JoinGraph, error) {
+	reader, ok := repo.(foreignKeyReader)
+	if !ok {
+		return nil, fmt.Errorf("repository %s cannot read foreign keys", repo.GetName())
+	}
+	fks, err := reader.GetAllForeignKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewJoinGraph(fks), nil
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JoinColumn is one projected column of a join query, returned as
+// table.column
+type JoinColumn struct {
+	Table  string
+	Column string
+}
+
+// Label returns the result key of the column
+func (c JoinColumn) Label() string {
+	return c.Table + "." + c.Column
+}
+
+// JoinSpec describes a join query from a root table along join steps, each
+// joining a new table to one already joined. Tables are named the way results
+// report them; FullNames maps them to their schema-qualified form for FROM
+// and JOIN, and names missing from it are used as they are.
+type JoinSpec struct {
+	Root       string
+	Steps      []JoinStep
+	FullNames  map[string]string
+	Left       bool // LEFT JOIN instead of INNER JOIN
+	Columns    []JoinColumn
+	Conditions map[string]any // keyed by table.column, as in AggregationSpec
+	OrderBy    string         // comma separated table.column terms, each optionally ASC or DESC
+	Limit      int
+	Offset     int
+}
+
+// BuildJoinQuery builds a parameterized join query. Columns are selected
+// under their table.column labels, so equal column names of different tables
+// stay apart.
+func (qb *QueryBuilder) BuildJoinQuery(spec JoinSpec) (string, []any, error) {
+	if len(spec.Columns) == 0 {
+		return "", nil, fmt.Errorf("at least one column is required")
+	}
+
+	from, joined, err := qb.joinClause(spec.Root, spec.Steps, spec.FullNames, spec.Left)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selectList := make([]string, len(spec.Columns))
+	for i, column := range spec.Columns {
+		if !joined[column.Table] || !qb.isValidIdentifier(column.Column) || strings.Contains(column.Column, ".") {
+			return "", nil, fmt.Errorf("invalid column %q: not a column of a joined table", column.Label())
+		}
+		selectList[i] = fmt.Sprintf("%s AS %s", qb.quoteIdentifier(column.Label()), qb.quote(column.Label()))
+	}
+	query := fmt.Sprintf("SELECT %s %s", strings.Join(selectList, ", "), from)
+
+	for key := range spec.Conditions {
+		if table, _, ok := SplitColumnName(key); !ok || !joined[table] {
+			return "", nil, fmt.Errorf("condition %q must name a column of a joined table as table.column", key)
+		}
+	}
+	where, params, err := qb.buildConditions(spec.Conditions, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	if spec.OrderBy != "" {
+		var terms []string
+		for _, part := range strings.Split(spec.OrderBy, ",") {
+			tokens := strings.Fields(part)
+			if len(tokens) == 0 || len(tokens) > 2 || !qb.isValidIdentifier(tokens[0]) {
+				return "", nil, fmt.Errorf("invalid order_by term: %q", strings.TrimSpace(part))
+			}
+			if table, _, ok := SplitColumnName(tokens[0]); !ok || !joined[table] {
+				return "", nil, fmt.Errorf("order_by %q must name a column of a joined table as table.column", tokens[0])
+			}
+			term := qb.quoteIdentifier(tokens[0])
+			if len(tokens) == 2 {
+				direction := strings.ToUpper(tokens[1])
+				if direction != "ASC" && direction != "DESC" {
+					return "", nil, fmt.Errorf("invalid order_by direction: %q", tokens[1])
+				}
+				term += " " + direction
+			}
+			terms = append(terms, term)
+		}
+		query += " ORDER BY " + strings.Join(terms, ", ")
+	}
+
+	if spec.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", spec.Limit)
+	}
+	if spec.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", spec.Offset)
+	}
+	return query, params, nil
+}
+
+// BuildJoinClause builds the FROM clause joining the tables of a join path to
+// its root, e.g. FROM "orders" INNER JOIN "customers" ON "orders"."customer_id"
+// = "customers"."id". Tables are named as in JoinSpec.
+func (qb *QueryBuilder) BuildJoinClause(root string, steps []JoinStep, fullNames map[string]string, left bool) (string, error) {
+	from, _, err := qb.joinClause(root, steps, fullNames, left)
+	return from, err
+}
+
+// joinClause builds the FROM clause of a join and returns the joined tables
+func (qb *QueryBuilder) joinClause(root string, steps []JoinStep, fullNames map[string]string, left bool) (string, map[string]bool, error) {
+	joined := map[string]bool{root: true}
+	fullName := func(table string) string {
+		if name, ok := fullNames[table]; ok {
+			return name
+		}
+		return table
+	}
+	if !qb.isValidIdentifier(root) {
+		return "", nil, fmt.Errorf("invalid table: %q", root)
+	}
+	from := "FROM " + qb.quoteIdentifier(fullName(root))
+
+	joinType := "INNER JOIN"
+	if left {
+		joinType = "LEFT JOIN"
+	}
+	for _, step := range steps {
+		if !joined[step.FromTable] || joined[step.ToTable] {
+			return "", nil, fmt.Errorf("join step %s -> %s must join a new table to a joined one", step.FromTable, step.ToTable)
+		}
+		if !qb.isValidIdentifier(step.ToTable) || len(step.FromColumns) == 0 || len(step.FromColumns) != len(step.ToColumns) {
+			return "", nil, fmt.Errorf("invalid join step %s -> %s", step.FromTable, step.ToTable)
+		}
+		predicates := make([]string, len(step.FromColumns))
+		for i := range step.FromColumns {
+			if !qb.isValidIdentifier(step.FromColumns[i]) || !qb.isValidIdentifier(step.ToColumns[i]) {
+				return "", nil, fmt.Errorf("invalid join columns of %s", step.Constraint)
+			}
+			predicates[i] = fmt.Sprintf("%s = %s",
+				qb.quoteIdentifier(step.FromTable+"."+step.FromColumns[i]), qb.quoteIdentifier(step.ToTable+"."+step.ToColumns[i]))
+		}
+		from += fmt.Sprintf(" %s %s ON %s", joinType, qb.quoteIdentifier(fullName(step.ToTable)), strings.Join(predicates, " AND "))
+		joined[step.ToTable] = true
+	}
+	return from, joined, nil
+}
+
+// SplitColumnName splits a table.column reference at its last dot, so that
+// the table may be schema-qualified
+func SplitColumnName(ref string) (table, column string, ok bool) {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 || i == len(ref)-1 {
+		return "", ref, false
+	}
+	return ref[:i], ref[i+1:], true
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// Object types reported by GetObjects and accepted by GetObjectDefinition
+const (
+	ObjectTable            = "table"
+	ObjectView             = "view"
+	ObjectMaterializedView = "materialized_view"
+	ObjectProcedure        = "procedure"
+	ObjectFunction         = "function"
+	ObjectTrigger          = "trigger"
+	ObjectSequence         = "sequence"
+)
+
+// ObjectTypes lists every object type in the order objects are listed
+var ObjectTypes = []string{
+	ObjectTable, ObjectView, ObjectMaterializedView,
+	ObjectProcedure, ObjectFunction, ObjectTrigger, ObjectSequence,
+}
+
+// ObjectInfo names a schema object and its type
+type ObjectInfo struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Table string `json:"table,omitempty"` // target table of a trigger
+}
+
+// ViewInfo represents a view or materialized view
+type ViewInfo struct {
+	Name         string `json:"name"`
+	Materialized bool   `json:"materialized,omitempty"`
+	Definition   string `json:"definition"` // SELECT statement of the view
+}
+
+// RoutineInfo represents a stored procedure or function
+type RoutineInfo struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`      // procedure or function
+	Signature  string `json:"signature"` // name and parameter list
+	ReturnType string `json:"return_type,omitempty"`
+	Language   string `json:"language,omitempty"`
+	Volatility string `json:"volatility,omitempty"` // IMMUTABLE/STABLE/VOLATILE, or DETERMINISTIC/NOT DETERMINISTIC on MySQL
+	DataAccess string `json:"data_access,omitempty"`
+}
+
+// TriggerInfo represents a trigger on a table
+type TriggerInfo struct {
+	Name      string   `json:"name"`
+	Table     string   `json:"table"`
+	Timing    string   `json:"timing"` // BEFORE, AFTER or INSTEAD OF
+	Events    []string `json:"events"` // INSERT, UPDATE, DELETE, TRUNCATE
+	Statement string   `json:"statement,omitempty"`
+}
+
+// SequenceInfo represents a sequence
+type SequenceInfo struct {
+	Name      string `json:"name"`
+	DataType  string `json:"data_type"`
+	Start     string `json:"start"`
+	Increment string `json:"increment"`
+	Minimum   string `json:"minimum"`
+	Maximum   string `json:"maximum"`
+	Cycle     bool   `json:"cycle"`
+}
+
+// SchemaObjects groups the non-table objects of a database
+type SchemaObjects struct {
+	Views     []ViewInfo     `json:"views,omitempty"`
+	Routines  []RoutineInfo  `json:"routines,omitempty"`
+	Triggers  []TriggerInfo  `json:"triggers,omitempty"`
+	Sequences []SequenceInfo `json:"sequences,omitempty"`
+}
+
+// Objects flattens the schema objects into a name/type list
+func (o *SchemaObjects) Objects() []ObjectInfo {
+	var objects []ObjectInfo
+	for _, v := range o.Views {
+		objectType := ObjectView
+		if v.Materialized {
+			objectType = ObjectMaterializedView
+		}
+		objects = append(objects, ObjectInfo{Name: v.Name, Type: objectType})
+	}
+	for _, r := range o.Routines {
+		objects = append(objects, ObjectInfo{Name: r.Name, Type: r.Type})
+	}
+	for _, t := range o.Triggers {
+		objects = append(objects, ObjectInfo{Name: t.Name, Type: ObjectTrigger, Table: t.Table})
+	}
+	for _, s := range o.Sequences {
+		objects = append(objects, ObjectInfo{Name: s.Name, Type: ObjectSequence})
+	}
+
+	// Overloaded routines share a name
+	return slices.Compact(objects)
+}
+
+// sortObjects orders objects by type, then name
+func sortObjects(objects []ObjectInfo) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		ti, tj := slices.Index(ObjectTypes, objects[i].Type), slices.Index(ObjectTypes, objects[j].Type)
+		if ti != tj {
+			return ti < tj
+		}
+		return objects[i].Name < objects[j].Name
+	})
+}
+
+// appendTriggerEvent adds an event row to the last trigger when it has the
+// same name, or starts a new trigger. information_schema.triggers has one row
+// per event.
+func appendTriggerEvent(triggers []TriggerInfo, trigger TriggerInfo, event string) []TriggerInfo {
+	if n := len(triggers); n > 0 && triggers[n-1].Name == trigger.Name && triggers[n-1].Table == trigger.Table {
+		triggers[n-1].Events = append(triggers[n-1].Events, event)
+		return triggers
+	}
+	trigger.Events = []string{event}
+	return append(triggers, trigger)
+}
+
+// ObjectNotFoundError creates an UNKNOWN_OBJECT error with the nearest existing object names
+func ObjectNotFoundError(objectType, name string, objects []ObjectInfo) *toolerrors.ToolError {
+	var available []string
+	for _, o := range objects {
+		if objectType == "" || o.Type == objectType {
+			available = append(available, o.Name)
+		}
+	}
+	kind := "object"
+	if objectType != "" {
+		kind = strings.ReplaceAll(objectType, "_", " ")
+	}
+	te := toolerrors.Newf(toolerrors.CodeUnknownObject, "%s '%s' not found", kind, name).
+		WithTarget(name).
+		WithSuggestions(available)
+	if te.Hint == "" {
+		te.WithHint("Use db_table_list to see the available objects and their types.")
+	}
+	return te
+}
+
+// ResolveObject checks that an object called name exists and returns its
+// type. With an empty objectType the type is looked up, which fails when
+// several types share the name.
+func ResolveObject(ctx context.Context, repo Repository, objectType, name string) (string, error) {
+	var objects []ObjectInfo
+	var err error
+	switch r := repo.(type) {
+	case *MySQLRepository:
+		objects, err = r.GetObjects(ctx)
+	case *PostgresRepository:
+		objects, err = r.GetObjects(ctx)
+	default:
+		return "", toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var types []string
+	for _, o := range objects {
+		if o.Name == name && (objectType == "" || o.Type == objectType) && !slices.Contains(types, o.Type) {
+			types = append(types, o.Type)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return "", ObjectNotFoundError(objectType, name, objects)
+	case 1:
+		return types[0], nil
+	default:
+		return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "%q names several objects: %s", name, strings.Join(types, ", ")).
+			WithTarget(name).
+			WithHint("Pass type to choose one of them.")
+	}
+}
+
+// formatSequenceDDL renders a CREATE SEQUENCE statement from catalog values
+func formatSequenceDDL(s SequenceInfo) string {
+	cycle := "NO CYCLE"
+	if s.Cycle {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf("CREATE SEQUENCE %s\n    AS %s\n    START WITH %s\n    INCREMENT BY %s\n    MINVALUE %s\n    MAXVALUE %s\n    %s;",
+		s.Name, s.DataType, s.Start, s.Increment, s.Minimum, s.Maximum, cycle)
+}
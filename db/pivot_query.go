@@ -0,0 +1,150 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PivotDimension is the row or column dimension of a pivot: a column, or the
+// time buckets of a time column when Bucket is set
+type PivotDimension struct {
+	Column   string
+	Bucket   string // one of TimeBuckets, or empty
+	TimeKind string // TimeKind constant of a bucketed column
+}
+
+// PivotSpec describes a pivot (cross-tab) query: one metric per row and
+// column dimension value
+type PivotSpec struct {
+	Table      string
+	Rows       PivotDimension
+	Columns    PivotDimension
+	Timezone   string // time zone of bucketed dimensions, as in TimeseriesSpec
+	Metric     Metric // any metric but percentiles
+	Conditions map[string]any
+}
+
+// BuildPivotColumns builds a query for the first limit values of the column
+// dimension, in order, as column_key
+func (qb *QueryBuilder) BuildPivotColumns(spec PivotSpec, limit int) (string, []any, error) {
+	source, params, err := qb.pivotSource(spec, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT column_key FROM %s GROUP BY column_key ORDER BY column_key LIMIT %d", source, limit), params, nil
+}
+
+// BuildPivotQuery builds a conditional aggregation query for the pivot rows:
+// row_key, then the metric for each of values as cell_0, cell_1, ... and for
+// the whole row as total, for the first limit rows in order. The values come
+// from BuildPivotColumns and are bound as parameters.
+func (qb *QueryBuilder) BuildPivotQuery(spec PivotSpec, values []any, limit int) (string, []any, error) {
+	cells, params, err := qb.pivotCells(spec, values)
+	if err != nil {
+		return "", nil, err
+	}
+	source, params, err := qb.pivotSource(spec, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT row_key, %s FROM %s GROUP BY row_key ORDER BY row_key LIMIT %d", cells, source, limit), params, nil
+}
+
+// BuildPivotTotals builds the query for the column totals of a pivot, as
+// cell_0, cell_1, ..., and the grand total, as total
+func (qb *QueryBuilder) BuildPivotTotals(spec PivotSpec, values []any) (string, []any, error) {
+	cells, params, err := qb.pivotCells(spec, values)
+	if err != nil {
+		return "", nil, err
+	}
+	source, params, err := qb.pivotSource(spec, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", cells, source), params, nil
+}
+
+// pivotCells returns the select list of conditional aggregates, appending the
+// column dimension values to params. The select list precedes the source, so
+// its parameters come first.
+func (qb *QueryBuilder) pivotCells(spec PivotSpec, values []any) (string, []any, error) {
+	m, err := NormalizeMetric(spec.Metric)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := m.Percentile(); ok {
+		return "", nil, fmt.Errorf("%s is not supported in pivots", m.Fn)
+	}
+
+	value := "metric_value"
+	if m.Column == "" {
+		value = "1"
+	}
+	var params []any
+	cells := make([]string, 0, len(values)+1)
+	for i, v := range values {
+		match := "column_key IS NULL"
+		if v != nil {
+			params = append(params, v)
+			match = "column_key = " + qb.placeholder(len(params))
+		}
+		cells = append(cells, fmt.Sprintf("%s AS cell_%d", qb.aggregateOf(m, fmt.Sprintf("CASE WHEN %s THEN %s END", match, value)), i))
+	}
+	cells = append(cells, qb.aggregateOf(m, value)+" AS total")
+	return strings.Join(cells, ", "), params, nil
+}
+
+// pivotSource returns a derived table of the rows matching the conditions
+// with their row_key, column_key and, for metrics on a column, metric_value
+func (qb *QueryBuilder) pivotSource(spec PivotSpec, params []any) (string, []any, error) {
+	rowKey, params, err := qb.pivotKey(spec.Rows, spec.Timezone, params)
+	if err != nil {
+		return "", nil, err
+	}
+	columnKey, params, err := qb.pivotKey(spec.Columns, spec.Timezone, params)
+	if err != nil {
+		return "", nil, err
+	}
+	selectList := []string{rowKey + " AS row_key", columnKey + " AS column_key"}
+
+	m, err := NormalizeMetric(spec.Metric)
+	if err != nil {
+		return "", nil, err
+	}
+	if m.Column != "" {
+		if !qb.isValidIdentifier(m.Column) {
+			return "", nil, fmt.Errorf("invalid metric column: %q", m.Column)
+		}
+		selectList = append(selectList, qb.quoteIdentifier(m.Column)+" AS metric_value")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), qb.quoteIdentifier(spec.Table))
+	where, params, err := qb.buildConditions(spec.Conditions, params)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return fmt.Sprintf("(%s) AS pivot_source", query), params, nil
+}
+
+// pivotKey returns the expression of a pivot dimension
+func (qb *QueryBuilder) pivotKey(dim PivotDimension, timezone string, params []any) (string, []any, error) {
+	if !qb.isValidIdentifier(dim.Column) {
+		return "", nil, fmt.Errorf("invalid dimension column: %q", dim.Column)
+	}
+	if dim.Bucket == "" {
+		return qb.quoteIdentifier(dim.Column), params, nil
+	}
+	if !slices.Contains(TimeBuckets, dim.Bucket) {
+		return "", nil, fmt.Errorf("invalid bucket: %s (must be one of %s)", dim.Bucket, strings.Join(TimeBuckets, ", "))
+	}
+	return qb.timeBucket(TimeseriesSpec{
+		TimeColumn: dim.Column,
+		TimeKind:   dim.TimeKind,
+		Bucket:     dim.Bucket,
+		Timezone:   timezone,
+	}, params)
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Column kinds decide which profile statistics apply to a column
+const (
+	ColumnKindNumeric  = "numeric"
+	ColumnKindString   = "string"
+	ColumnKindTemporal = "temporal"
+	ColumnKindBoolean  = "boolean"
+	ColumnKindOther    = "other" // JSON, binary, arrays, spatial and user-defined types
+)
+
+// ColumnKind classifies a MySQL or PostgreSQL data type
+func ColumnKind(dataType string) string {
+	t := strings.ToLower(dataType)
+	switch {
+	case t == "boolean" || t == "bool":
+		return ColumnKindBoolean
+	case strings.Contains(t, "int") && !strings.Contains(t, "interval") && !strings.Contains(t, "point"),
+		strings.HasPrefix(t, "decimal"), strings.HasPrefix(t, "numeric"),
+		t == "real", t == "float", t == "double", t == "double precision", t == "money":
+		return ColumnKindNumeric
+	case strings.Contains(t, "char"), strings.HasSuffix(t, "text"), t == "enum", t == "set", t == "uuid", t == "citext":
+		return ColumnKindString
+	case strings.HasPrefix(t, "date"), strings.HasPrefix(t, "time"), t == "year", t == "interval":
+		return ColumnKindTemporal
+	default:
+		return ColumnKindOther
+	}
+}
+
+// SketchPrecision sets the 2^10 HyperLogLog registers, for a ~3% standard error
+const SketchPrecision = 10
+
+// BuildColumnStats builds a single-row query over source returning, in order:
+// row count, non-null count, distinct count, min, max, mean, standard
+// deviation, and min/max/mean character length. Statistics that do not apply
+// to the column kind are NULL; distinct is NULL unless requested.
+func (qb *QueryBuilder) BuildColumnStats(source, column, kind string, distinct bool) string {
+	col := qb.quoteIdentifier(column)
+	stats := []string{"COUNT(*)", fmt.Sprintf("COUNT(%s)", col)}
+
+	if distinct && kind != ColumnKindOther {
+		stats = append(stats, fmt.Sprintf("COUNT(DISTINCT %s)", col))
+	} else {
+		stats = append(stats, "NULL")
+	}
+
+	switch kind {
+	case ColumnKindNumeric, ColumnKindString, ColumnKindTemporal:
+		stats = append(stats, fmt.Sprintf("MIN(%s)", col), fmt.Sprintf("MAX(%s)", col))
+	default:
+		stats = append(stats, "NULL", "NULL")
+	}
+
+	if kind == ColumnKindNumeric {
+		stats = append(stats, fmt.Sprintf("AVG(%s)", col), fmt.Sprintf("STDDEV_POP(%s)", col))
+	} else {
+		stats = append(stats, "NULL", "NULL")
+	}
+
+	if kind == ColumnKindString {
+		length := fmt.Sprintf("CHAR_LENGTH(%s)", col)
+		stats = append(stats, "MIN("+length+")", "MAX("+length+")", "AVG("+length+")")
+	} else {
+		stats = append(stats, "NULL", "NULL", "NULL")
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(stats, ", "), source)
+}
+
+// BuildTopValues builds a query for the most frequent non-null values of a
+// column with their counts
+func (qb *QueryBuilder) BuildTopValues(source, column string, limit int) string {
+	col := qb.quoteIdentifier(column)
+	return fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s IS NOT NULL GROUP BY %s ORDER BY 2 DESC, 1 LIMIT %d",
+		col, source, col, col, limit)
+}
+
+// BuildPercentileTiles builds a query that splits the non-null values of a
+// column into up to 100 equal-count tiles and returns the largest value of
+// each, in tile order. Requires window functions (MySQL 8.0+).
+func (qb *QueryBuilder) BuildPercentileTiles(source, column string) string {
+	col := qb.quoteIdentifier(column)
+	return fmt.Sprintf("SELECT tile, MAX(%s) FROM (SELECT %s, NTILE(100) OVER (ORDER BY %s) AS tile FROM %s WHERE %s IS NOT NULL) AS tiles GROUP BY tile ORDER BY tile",
+		col, col, col, source, col)
+}
+
+// BuildHistogram builds an equi-width histogram query returning the bucket
+// index and row count of the non-null values of a numeric column. Values
+// from min upwards fall into buckets of the given width; the maximum lands
+// in the last bucket.
+func (qb *QueryBuilder) BuildHistogram(source, column string, minValue, width float64, buckets int) (string, []any) {
+	col := qb.quoteIdentifier(column)
+	lower, size := qb.placeholder(1), qb.placeholder(2)
+	if qb.driver == "postgres" {
+		lower, size = lower+"::float8", size+"::float8"
+	}
+	query := fmt.Sprintf("SELECT LEAST(FLOOR((%s - %s) / %s), %d) AS bucket, COUNT(*) FROM %s WHERE %s IS NOT NULL GROUP BY 1 ORDER BY 1",
+		col, lower, size, buckets-1, source, col)
+	return query, []any{minValue, width}
+}
+
+// BuildDistinctSketch builds a HyperLogLog sketch query over the whole table:
+// each non-null value is hashed to 32 bits, the low SketchPrecision bits pick
+// a register and the rest give the rank (position of the first set bit).
+// It returns one (register, max rank) row per non-empty register, to be
+// combined with EstimateDistinct.
+func (qb *QueryBuilder) BuildDistinctSketch(table, column string) string {
+	return qb.distinctSketch(table, column, nil)
+}
+
+// BuildDistinctSketchWhere builds the BuildDistinctSketch query over the rows
+// of a table matching conditions, as given to BuildAggregationQuery
+func (qb *QueryBuilder) BuildDistinctSketchWhere(table, column string, conditions map[string]any) (string, []any, error) {
+	if !qb.isValidIdentifier(column) {
+		return "", nil, fmt.Errorf("invalid column: %q", column)
+	}
+	where, params, err := qb.buildConditions(conditions, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return qb.distinctSketch(table, column, where), params, nil
+}
+
+// distinctSketch builds a HyperLogLog sketch query over the rows of a table
+// matching the where clauses
+func (qb *QueryBuilder) distinctSketch(table, column string, where []string) string {
+	col := qb.quoteIdentifier(column)
+	registers := 1<<SketchPrecision - 1
+	rest := hashBits - SketchPrecision
+
+	log2 := "FLOOR(LOG2(h >> %d))"
+	if qb.driver == "postgres" {
+		log2 = "floor(log(2, (h >> %d)::numeric))"
+	}
+	rank := fmt.Sprintf("CASE WHEN (h >> %d) = 0 THEN %d ELSE %d - "+log2+" END",
+		SketchPrecision, rest+1, rest, SketchPrecision)
+
+	filter := strings.Join(append([]string{col + " IS NOT NULL"}, where...), " AND ")
+	return fmt.Sprintf("SELECT h & %d AS register, MAX(%s) FROM (SELECT %s AS h FROM %s WHERE %s) AS hashes GROUP BY 1",
+		registers, rank, qb.hash32(col), qb.quoteIdentifier(table), filter)
+}
+
+// EstimateDistinct combines the registers returned by BuildDistinctSketch
+// (register index to rank) into a HyperLogLog cardinality estimate
+func EstimateDistinct(registers map[int]int) int64 {
+	m := float64(int(1) << SketchPrecision)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	empty := m - float64(len(registers))
+	sum := empty // empty registers contribute 2^0 each
+	for _, rank := range registers {
+		sum += math.Pow(2, -float64(rank))
+	}
+	estimate := alpha * m * m / sum
+
+	switch {
+	case estimate <= 2.5*m && empty > 0:
+		// Small range: linear counting is more accurate
+		estimate = m * math.Log(m/empty)
+	case estimate > math.Exp2(hashBits)/30:
+		// Large range: correct for 32-bit hash collisions
+		space := math.Exp2(hashBits)
+		estimate = -space * math.Log(1-estimate/space)
+	}
+	return int64(math.Round(estimate))
+}
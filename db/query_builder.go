@@ -80,57 +80,29 @@ func (qb *QueryBuilder) BuildCount(table string, conditions map[string]any) (str
 }
 
 // BuildAggregation builds an aggregation query (SUM, AVG, MIN, MAX, COUNT)
+// with a single metric named result; see BuildAggregationQuery
 // CRITICAL: Uses parameterized queries and validates aggregate functions
 func (qb *QueryBuilder) BuildAggregation(table, column, aggFunc string, conditions map[string]any, groupBy string) (string, []any, error) {
-	// Validate aggregate function to prevent injection
-	validAggFuncs := map[string]bool{
-		"SUM": true, "AVG": true, "MIN": true, "MAX": true, "COUNT": true,
+	spec := AggregationSpec{
+		Table:      table,
+		Metrics:    []Metric{{Fn: aggFunc, Column: column, Alias: "result"}},
+		Conditions: conditions,
 	}
-	aggFunc = strings.ToUpper(aggFunc)
-	if !validAggFuncs[aggFunc] {
-		return "", nil, fmt.Errorf("invalid aggregate function: %s", aggFunc)
+	if groupBy != "" {
+		spec.GroupBy = []string{groupBy}
 	}
-
-	var params []any
-
-	// Build SELECT clause with aggregation
-	selectClause := fmt.Sprintf("%s(%s) as result", aggFunc, qb.quoteIdentifier(column))
-	if groupBy != "" && qb.isValidIdentifier(groupBy) {
-		selectClause = fmt.Sprintf("%s, %s", qb.quoteIdentifier(groupBy), selectClause)
-	}
-
-	query := fmt.Sprintf("SELECT %s FROM %s", selectClause, qb.quoteIdentifier(table))
-
-	// Build WHERE clause
-	if len(conditions) > 0 {
-		whereClauses := []string{}
-		for key, value := range conditions {
-			whereClauses = append(whereClauses, fmt.Sprintf("%s = %s", qb.quoteIdentifier(key), qb.placeholder(len(params)+1)))
-			params = append(params, value)
-		}
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
-	}
-
-	// Add GROUP BY clause
-	if groupBy != "" && qb.isValidIdentifier(groupBy) {
-		query += " GROUP BY " + qb.quoteIdentifier(groupBy)
-	}
-
-	return query, params, nil
+	return qb.BuildAggregationQuery(spec)
 }
 
-// quoteIdentifier quotes a database identifier (table/column name) to prevent injection
+// quoteIdentifier quotes a database identifier (table/column name) to prevent injection.
+// Qualified names such as schema.table are quoted part by part.
 func (qb *QueryBuilder) quoteIdentifier(identifier string) string {
-	// Remove any existing quotes and validate
-	identifier = strings.Trim(identifier, "`\"")
-
-	// Basic validation: only allow alphanumeric and underscore
-	if !qb.isValidIdentifier(identifier) {
-		// Return a safe quoted version, though this should be validated earlier
-		return qb.quote(identifier)
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		// Remove any existing quotes; isValidIdentifier should have been checked earlier
+		parts[i] = qb.quote(strings.Trim(part, "`\""))
 	}
-
-	return qb.quote(identifier)
+	return strings.Join(parts, ".")
 }
 
 // quote wraps identifier in appropriate quotes for the database driver
@@ -198,15 +170,18 @@ func (qb *QueryBuilder) isValidOrderBy(orderBy string) bool {
 // CRITICAL: Uses parameterized queries to prevent SQL injection
 func (qb *QueryBuilder) BuildTableList(schema string) (string, []any) {
 	if qb.driver == "postgres" {
+		// Read pg_class so partitioned parents (relkind p) are listed with
+		// ordinary tables, as in the table fingerprints
+		query := `SELECT c.relname AS table_name FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') ORDER BY c.relname`
 		if schema != "" {
 			// Validate schema name as additional security layer
 			if !qb.isValidIdentifier(schema) {
 				// Return safe default with public schema
-				return "SELECT table_name FROM information_schema.tables WHERE table_schema = $1 AND table_type = 'BASE TABLE' ORDER BY table_name", []any{"public"}
+				return query, []any{"public"}
 			}
-			return "SELECT table_name FROM information_schema.tables WHERE table_schema = $1 AND table_type = 'BASE TABLE' ORDER BY table_name", []any{schema}
+			return query, []any{schema}
 		}
-		return "SELECT table_name FROM information_schema.tables WHERE table_schema = $1 AND table_type = 'BASE TABLE' ORDER BY table_name", []any{"public"}
+		return query, []any{"public"}
 	}
 
 	// MySQL
@@ -245,8 +220,15 @@ func (qb *QueryBuilder) BuildTableSchema(table, schema string) (string, []any, e
 				is_nullable,
 				column_default,
 				CASE WHEN column_name IN (
-					SELECT column_name FROM information_schema.key_column_usage
-					WHERE table_name = $1 AND constraint_name LIKE '%pkey'
+					SELECT kcu.column_name
+					FROM information_schema.table_constraints tc
+					JOIN information_schema.key_column_usage kcu
+						ON kcu.constraint_schema = tc.constraint_schema
+						AND kcu.constraint_name = tc.constraint_name
+						AND kcu.table_name = tc.table_name
+					WHERE tc.constraint_type = 'PRIMARY KEY'
+						AND tc.table_name = $1
+						AND tc.table_schema = $3
 				) THEN true ELSE false END as is_primary_key
 			FROM information_schema.columns
 			WHERE table_name = $2 AND table_schema = $3
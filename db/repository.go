@@ -3,17 +3,37 @@ package db
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"sort"
-	"strings"
 )
 
+// Rows are the result rows of Query. The statement counts as running until
+// Close, so cancelling its context while rows are still read cancels it
+// server-side, and its span ends on Close.
+type Rows struct {
+	*sql.Rows
+	finish func(err error)
+}
+
+// newRows wraps rows so that Close calls finish with the iteration error
+func newRows(rows *sql.Rows, finish func(err error)) *Rows {
+	return &Rows{Rows: rows, finish: finish}
+}
+
+// Close closes the rows and ends the statement
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.finish != nil {
+		r.finish(r.Rows.Err())
+		r.finish = nil
+	}
+	return err
+}
+
 // Repository defines the interface for database operations
 // This design allows easy testing and supports multiple database types
 type Repository interface {
 	// Query executes a parameterized query and returns rows
 	// CRITICAL: params must be used to prevent SQL injection
-	Query(ctx context.Context, query string, params ...any) (*sql.Rows, error)
+	Query(ctx context.Context, query string, params ...any) (*Rows, error)
 
 	// QueryRow executes a parameterized query that returns at most one row
 	QueryRow(ctx context.Context, query string, params ...any) *sql.Row
@@ -31,8 +51,15 @@ type Repository interface {
 	// GetDriver returns the database driver name (mysql, postgres, etc.)
 	GetDriver() string
 
+	// Schemas returns the schemas (MySQL databases) the repository can reach.
+	// Tables in the first, default schema are named without a qualifier.
+	Schemas() []string
+
 	// Ping checks if the database connection is alive
 	Ping(ctx context.Context) error
+
+	// Stats returns connection pool statistics
+	Stats() sql.DBStats
 }
 
 // QueryResult represents a generic query result
@@ -44,12 +71,16 @@ type QueryResult struct {
 
 // TableInfo represents database table metadata
 type TableInfo struct {
-	TableName   string       `json:"table_name"`
-	Schema      string       `json:"schema,omitempty"`
-	Columns     []ColumnInfo `json:"columns"`
-	Indexes     []IndexInfo  `json:"indexes,omitempty"`
-	RowCount    *int64       `json:"row_count,omitempty"`
-	Description string       `json:"description,omitempty"`
+	TableName        string           `json:"table_name"`
+	Schema           string           `json:"schema,omitempty"`
+	Columns          []ColumnInfo     `json:"columns"`
+	Indexes          []IndexInfo      `json:"indexes,omitempty"`
+	PrimaryKey       []string         `json:"primary_key,omitempty"`
+	Constraints      []ConstraintInfo `json:"constraints,omitempty"`
+	ForeignKeys      []ForeignKeyInfo `json:"foreign_keys,omitempty"`
+	RowCount         *int64           `json:"row_count,omitempty"`
+	RowCountAccuracy string           `json:"row_count_accuracy,omitempty"` // exact (COUNT(*)) or estimated (catalog statistics)
+	Description      string           `json:"description,omitempty"`
 }
 
 // ColumnInfo represents database column metadata
@@ -64,34 +95,41 @@ type ColumnInfo struct {
 
 // IndexInfo represents database index metadata
 type IndexInfo struct {
-	Name      string   `json:"name"`
-	Columns   []string `json:"columns"`
-	IsUnique  bool     `json:"is_unique"`
-	IsPrimary bool     `json:"is_primary"`
+	Name         string   `json:"name"`
+	Columns      []string `json:"columns"`                 // key columns in index order; expressions are shown as written
+	ColumnOrders []string `json:"column_orders,omitempty"` // ASC or DESC for each column
+	IsUnique     bool     `json:"is_unique"`
+	IsPrimary    bool     `json:"is_primary"`
+	Type         string   `json:"type,omitempty"`      // access method, e.g. BTREE, HASH, gin
+	Predicate    string   `json:"predicate,omitempty"` // WHERE clause of a partial index
 }
 
-// ForeignKeyInfo represents foreign key relationship
-type ForeignKeyInfo struct {
-	Name             string `json:"name"`
-	SourceTable      string `json:"source_table"`
-	SourceColumn     string `json:"source_column"`
-	ReferencedTable  string `json:"referenced_table"`
-	ReferencedColumn string `json:"referenced_column"`
-	OnDelete         string `json:"on_delete,omitempty"`
-	OnUpdate         string `json:"on_update,omitempty"`
+// ConstraintInfo represents a primary key, unique or check constraint
+type ConstraintInfo struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"` // PRIMARY KEY, UNIQUE or CHECK
+	Columns    []string `json:"columns,omitempty"`
+	Definition string   `json:"definition,omitempty"` // check expression
 }
 
-// FormatDatabaseNotFoundError creates a helpful error message with available databases
-// This is a shared utility function used by tools and insights handlers
-func FormatDatabaseNotFoundError(dbName string, repositories map[string]Repository) string {
-	available := make([]string, 0, len(repositories))
-	for name := range repositories {
-		available = append(available, name)
-	}
-	sort.Strings(available)
+// Constraint types reported in ConstraintInfo.Type
+const (
+	ConstraintPrimaryKey = "PRIMARY KEY"
+	ConstraintUnique     = "UNIQUE"
+	ConstraintCheck      = "CHECK"
+)
 
-	if len(available) == 0 {
-		return fmt.Sprintf("database '%s' not found. No databases are configured or enabled.", dbName)
-	}
-	return fmt.Sprintf("database '%s' not found or not enabled. Available databases: %s", dbName, strings.Join(available, ", "))
+// ForeignKeyInfo represents foreign key relationship. Composite keys are one
+// entry: SourceColumns and ReferencedColumns pair up by position, and
+// SourceColumn and ReferencedColumn join them with ", ".
+type ForeignKeyInfo struct {
+	Name              string   `json:"name"`
+	SourceTable       string   `json:"source_table"`
+	SourceColumn      string   `json:"source_column"`
+	SourceColumns     []string `json:"source_columns,omitempty"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumn  string   `json:"referenced_column"`
+	ReferencedColumns []string `json:"referenced_columns,omitempty"`
+	OnDelete          string   `json:"on_delete,omitempty"`
+	OnUpdate          string   `json:"on_update,omitempty"`
 }
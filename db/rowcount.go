@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+)
+
+// Accuracy of TableInfo.RowCount
+const (
+	RowCountExact     = "exact"
+	RowCountEstimated = "estimated"
+)
+
+// defaultRowCountTimeout bounds exact counts when row_count_timeout is unset
+const defaultRowCountTimeout = 5 * time.Second
+
+// rowCounter obtains table row counts following the configured strategy
+type rowCounter struct {
+	strategy config.RowCountStrategy
+	timeout  time.Duration
+}
+
+// newRowCounter parses a row_count setting and its timeout in seconds
+func newRowCounter(value string, timeoutSeconds int) (rowCounter, error) {
+	strategy, err := config.ParseRowCountStrategy(value)
+	if err != nil {
+		return rowCounter{}, err
+	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultRowCountTimeout
+	}
+	return rowCounter{strategy: strategy, timeout: timeout}, nil
+}
+
+// estimateFunc reads the row estimate of a table from the catalog statistics.
+// It reports false when the table has no statistics yet.
+type estimateFunc func(ctx context.Context) (int64, bool, error)
+
+// exactFunc counts the rows of a table
+type exactFunc func(ctx context.Context) (int64, error)
+
+// count returns the row count of a table and its accuracy, or nil when the
+// strategy is none or no count could be obtained. Exact counts that fail or
+// exceed the timeout fall back to the estimate.
+func (c rowCounter) count(ctx context.Context, estimate estimateFunc, exact exactFunc) (*int64, string) {
+	if c.strategy.Mode == config.RowCountNone {
+		return nil, ""
+	}
+
+	var estimated int64
+	var known bool
+	if c.strategy.Mode != config.RowCountExact {
+		n, ok, err := estimate(ctx)
+		estimated, known = n, ok && err == nil
+		if c.strategy.Mode == config.RowCountEstimate || (known && estimated >= c.strategy.Threshold) {
+			return estimatedCount(estimated, known)
+		}
+	}
+
+	// The timeout cancels the statement server-side, see watchCancellation
+	countCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	n, err := exact(countCtx)
+	cancel()
+	if err == nil {
+		return &n, RowCountExact
+	}
+
+	if c.strategy.Mode == config.RowCountExact {
+		n, ok, err := estimate(ctx)
+		estimated, known = n, ok && err == nil
+	}
+	return estimatedCount(estimated, known)
+}
+
+// estimatedCount returns an estimate with its accuracy when it is known
+func estimatedCount(n int64, known bool) (*int64, string) {
+	if !known {
+		return nil, ""
+	}
+	return &n, RowCountEstimated
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sampling modes for table data samples
+const (
+	SampleFirst      = "first"      // first rows in physical order
+	SampleRandom     = "random"     // uniform random rows
+	SampleRecent     = "recent"     // latest rows by a timestamp column
+	SampleStratified = "stratified" // equal share of rows per value of a categorical column
+	SampleHash       = "hash"       // reproducible rows ordered by a hash of the primary key
+)
+
+// SampleModes lists the supported sampling modes
+var SampleModes = []string{SampleFirst, SampleRandom, SampleRecent, SampleStratified, SampleHash}
+
+// hashBits is the width of the row hashes computed in SQL
+const hashBits = 32
+
+// BuildSampleSource returns a FROM source over the given columns of a table:
+// the table itself, or at most limit rows of it. fraction is the expected
+// share of rows to keep; zero or less takes the first limit rows, one or more
+// the whole table.
+func (qb *QueryBuilder) BuildSampleSource(table string, columns []string, limit int, fraction float64) string {
+	if limit <= 0 || fraction >= 1 {
+		return qb.quoteIdentifier(table)
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = qb.quoteIdentifier(column)
+	}
+	selectList := fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), qb.quoteIdentifier(table))
+
+	switch {
+	case fraction <= 0:
+		return fmt.Sprintf("(%s LIMIT %d) AS sample_source", selectList, limit)
+	case qb.driver == "postgres":
+		// Block sampling reads only the sampled pages
+		return fmt.Sprintf("(%s TABLESAMPLE SYSTEM (%.6f) LIMIT %d) AS sample_source", selectList, fraction*100, limit)
+	default:
+		return fmt.Sprintf("(%s WHERE RAND() < %.8f LIMIT %d) AS sample_source", selectList, fraction, limit)
+	}
+}
+
+// BuildRandomSample builds a query for limit random rows of source. The sort
+// covers every row of source, so large tables need a bounded source.
+func (qb *QueryBuilder) BuildRandomSample(source string, limit int) string {
+	return fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT %d", source, qb.random(), limit)
+}
+
+// BuildRecentSample builds a query for the limit latest rows of a table by a
+// timestamp column
+func (qb *QueryBuilder) BuildRecentSample(table, column string, limit int) string {
+	col := qb.quoteIdentifier(column)
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s IS NOT NULL ORDER BY %s DESC LIMIT %d",
+		qb.quoteIdentifier(table), col, col, limit)
+}
+
+// BuildStratifiedSample builds a query for limit rows of source spread evenly
+// over the values of a categorical column: rows are ranked randomly within
+// each value and taken rank by rank. The rank is returned as sample_rank.
+// Requires window functions (MySQL 8.0+).
+func (qb *QueryBuilder) BuildStratifiedSample(source, column string, limit int) string {
+	col := qb.quoteIdentifier(column)
+	return fmt.Sprintf("SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS sample_rank FROM %s) AS strata ORDER BY sample_rank, %s LIMIT %d",
+		col, qb.random(), source, col, limit)
+}
+
+// BuildHashSample builds a query for limit rows of source ordered by a hash
+// of the seed and key columns, so the same seed returns the same rows while
+// the data is unchanged. fraction below one keeps only rows whose hash falls
+// in that share of the hash space, which bounds the sort on large tables.
+func (qb *QueryBuilder) BuildHashSample(source string, key []string, seed string, fraction float64, limit int) (string, []any) {
+	seedParam := qb.placeholder(1)
+	if qb.driver == "postgres" {
+		seedParam += "::text"
+	}
+	parts := []string{seedParam}
+	for _, column := range key {
+		parts = append(parts, qb.quoteIdentifier(column))
+	}
+	hash := qb.hash32(fmt.Sprintf("CONCAT_WS('|', %s)", strings.Join(parts, ", ")))
+
+	query := fmt.Sprintf("SELECT * FROM %s", source)
+	if fraction > 0 && fraction < 1 {
+		query += fmt.Sprintf(" WHERE %s < %d", hash, int64(fraction*(1<<hashBits)))
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", hash, limit)
+	return query, []any{seed}
+}
+
+// hash32 returns an expression hashing expr to a non-negative 32-bit integer
+// from the first 8 hex digits of its MD5
+func (qb *QueryBuilder) hash32(expr string) string {
+	if qb.driver == "postgres" {
+		return fmt.Sprintf("('x' || substr(md5((%s)::text), 1, 8))::bit(32)::bigint", expr)
+	}
+	return fmt.Sprintf("CAST(CONV(LEFT(MD5(%s), 8), 16, 10) AS UNSIGNED)", expr)
+}
+
+// random returns the random number function of the driver
+func (qb *QueryBuilder) random() string {
+	if qb.driver == "postgres" {
+		return "random()"
+	}
+	return "RAND()"
+}
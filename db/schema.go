@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// Tables in the default schema of a repository are named by their bare name;
+// tables in the other configured schemas are named schema.table.
+
+// DefaultSchema returns the schema unqualified table names resolve to
+func DefaultSchema(repo Repository) string {
+	if schemas := repo.Schemas(); len(schemas) > 0 {
+		return schemas[0]
+	}
+	return ""
+}
+
+// SplitTableName splits a possibly qualified table name into its schema and
+// table. Unqualified names belong to the default schema.
+func SplitTableName(repo Repository, name string) (schema, table string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return DefaultSchema(repo), name
+}
+
+// FullTableName returns the schema.table form of a table name for use in SQL,
+// so statements do not depend on the connection's search path
+func FullTableName(repo Repository, name string) string {
+	schema, table := SplitTableName(repo, name)
+	if schema == "" {
+		return table
+	}
+	return schema + "." + table
+}
+
+// QualifyTableName names a table of schema the way results report it
+func QualifyTableName(repo Repository, schema, table string) string {
+	if schema == "" || schema == DefaultSchema(repo) {
+		return table
+	}
+	return schema + "." + table
+}
+
+// ResolveSchema checks that schema is one of the configured schemas
+func ResolveSchema(repo Repository, schema string) error {
+	if slices.Contains(repo.Schemas(), schema) {
+		return nil
+	}
+	te := toolerrors.Newf(toolerrors.CodeInvalidArgument, "schema '%s' is not configured for database '%s'", schema, repo.GetName()).
+		WithTarget(schema).
+		WithSuggestions(repo.Schemas())
+	if te.Hint == "" {
+		te.WithHint(fmt.Sprintf("Configured schemas: %s. Add the schema to `schemas` in the database config to reach it.",
+			strings.Join(repo.Schemas(), ", ")))
+	}
+	return te
+}
+
+// ResolveTableName combines a table argument with an optional schema
+// argument into the name results use. The table may itself be qualified as
+// schema.table when no schema argument is given.
+func ResolveTableName(repo Repository, schema, table string) (string, error) {
+	switch strings.Count(table, ".") {
+	case 0:
+	case 1:
+		if schema != "" {
+			return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "table %q is already qualified; omit schema or pass the bare table name", table).
+				WithTarget(table)
+		}
+		schema, table = SplitTableName(repo, table)
+	default:
+		return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid table name %q: expected table or schema.table", table).
+			WithTarget(table)
+	}
+
+	if schema == "" {
+		return table, nil
+	}
+	if err := ResolveSchema(repo, schema); err != nil {
+		return "", err
+	}
+	return QualifyTableName(repo, schema, table), nil
+}
+
+// ColumnNames returns the column names of a table in column order, read from
+// information_schema.columns without the rest of its metadata. A missing
+// table has no columns.
+func ColumnNames(ctx context.Context, repo Repository, tableName string) ([]string, error) {
+	qb := NewQueryBuilder(repo.GetDriver())
+	query := fmt.Sprintf(
+		"SELECT column_name FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position",
+		qb.placeholder(1), qb.placeholder(2))
+
+	schema, table := SplitTableName(repo, tableName)
+	rows, err := repo.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to scan column name: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
+	}
+	return columns, nil
+}
+
+// scanTableColumns reads rows of table schema, table name, column name, data
+// type, nullability and primary key flag into column-only table info, keyed
+// by table name as results report it
+func scanTableColumns(repo Repository, rows *Rows) (map[string]*TableInfo, error) {
+	tables := make(map[string]*TableInfo)
+	for rows.Next() {
+		var schema, table, isNullable string
+		var col ColumnInfo
+		if err := rows.Scan(&schema, &table, &col.Name, &col.DataType, &isNullable, &col.IsPrimaryKey); err != nil {
+			return nil, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		col.IsNullable = isNullable == "YES"
+
+		name := QualifyTableName(repo, schema, table)
+		info, ok := tables[name]
+		if !ok {
+			info = &TableInfo{TableName: name, Schema: schema}
+			tables[name] = info
+		}
+		info.Columns = append(info.Columns, col)
+		if col.IsPrimaryKey {
+			info.PrimaryKey = append(info.PrimaryKey, col.Name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
+	}
+	return tables, nil
+}
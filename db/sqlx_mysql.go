@@ -4,24 +4,33 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
 )
 
 // MySQLRepository implements Repository for MySQL databases
 type MySQLRepository struct {
-	db     *sqlx.DB
-	name   string
-	config config.MySQLConfig
+	db         *sqlx.DB
+	name       string
+	config     config.MySQLConfig
+	rowCounter rowCounter
 }
 
 // NewMySQLRepository creates a new MySQL repository
 // CRITICAL: Uses parameterized queries throughout to prevent SQL injection
 func NewMySQLRepository(cfg config.MySQLConfig) (*MySQLRepository, error) {
+	counter, err := newRowCounter(cfg.RowCount, cfg.RowCountTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MySQL config %s: %w", cfg.Name, err)
+	}
+
 	// Connect to MySQL database
 	db, err := sqlx.Connect("mysql", cfg.DSN())
 	if err != nil {
@@ -42,28 +51,63 @@ func NewMySQLRepository(cfg config.MySQLConfig) (*MySQLRepository, error) {
 		return nil, fmt.Errorf("failed to ping MySQL %s: %w", cfg.Name, err)
 	}
 
+	// The connected database is the default schema
+	if len(cfg.Schemas) == 0 {
+		cfg.Schemas = []string{cfg.Database}
+	}
+
 	return &MySQLRepository{
-		db:     db,
-		name:   cfg.Name,
-		config: cfg,
+		db:         db,
+		name:       cfg.Name,
+		config:     cfg,
+		rowCounter: counter,
 	}, nil
 }
 
 // Query executes a parameterized SELECT query
 // CRITICAL: Always use parameterized queries. Never concatenate user input into SQL!
-func (r *MySQLRepository) Query(ctx context.Context, query string, params ...any) (*sql.Rows, error) {
-	return r.db.QueryContext(ctx, query, params...)
+func (r *MySQLRepository) Query(ctx context.Context, query string, params ...any) (*Rows, error) {
+	ctx, span := startQuerySpan(ctx, "mysql", r.name, "query", query)
+	query, stop := r.watchCancellation(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		stop()
+		tracing.End(span, err)
+		return nil, err
+	}
+	// Rows stream until closed, so the statement is watched until then
+	return newRows(rows, func(err error) {
+		stop()
+		tracing.End(span, err)
+	}), nil
 }
 
 // QueryRow executes a parameterized query that returns at most one row
 func (r *MySQLRepository) QueryRow(ctx context.Context, query string, params ...any) *sql.Row {
-	return r.db.QueryRowContext(ctx, query, params...)
+	ctx, span := startQuerySpan(ctx, "mysql", r.name, "query_row", query)
+	query, stop := r.watchCancellation(ctx, query)
+	row := r.db.QueryRowContext(ctx, query, params...)
+	stop()
+	tracing.End(span, row.Err())
+	return row
 }
 
 // Exec executes a parameterized statement (INSERT, UPDATE, DELETE)
 // CRITICAL: Always use parameterized queries. Never concatenate user input!
 func (r *MySQLRepository) Exec(ctx context.Context, query string, params ...any) (sql.Result, error) {
-	return r.db.ExecContext(ctx, query, params...)
+	ctx, span := startQuerySpan(ctx, "mysql", r.name, "exec", query)
+	query, stop := r.watchCancellation(ctx, query)
+	result, err := r.db.ExecContext(ctx, query, params...)
+	stop()
+	tracing.End(span, err)
+	return result, err
+}
+
+// watchCancellation cancels the statement server-side if ctx is cancelled while it runs
+func (r *MySQLRepository) watchCancellation(ctx context.Context, query string) (string, func()) {
+	return watchCancellation(ctx, query, func(ctx context.Context, tag string) error {
+		return killMySQL(ctx, r, tag)
+	})
 }
 
 // Close closes the database connection
@@ -81,17 +125,43 @@ func (r *MySQLRepository) GetDriver() string {
 	return "mysql"
 }
 
+// Schemas returns the schemas tables are listed from; the first is the default
+func (r *MySQLRepository) Schemas() []string {
+	return r.config.Schemas
+}
+
 // Ping checks if the database connection is alive
 func (r *MySQLRepository) Ping(ctx context.Context) error {
 	return r.db.PingContext(ctx)
 }
 
-// GetTableList returns a list of all tables in the database
+// Stats returns connection pool statistics
+func (r *MySQLRepository) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+// GetTableList returns a list of all tables in the configured schemas.
+// Tables outside the default schema are qualified as schema.table.
 func (r *MySQLRepository) GetTableList(ctx context.Context) ([]string, error) {
+	tables := []string{}
+	for _, schema := range r.Schemas() {
+		schemaTables, err := r.getSchemaTables(ctx, schema)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, schemaTables...)
+	}
+	return tables, nil
+}
+
+// getSchemaTables returns the qualified names of the tables in one schema
+func (r *MySQLRepository) getSchemaTables(ctx context.Context, schema string) ([]string, error) {
 	qb := NewQueryBuilder("mysql")
-	query, params := qb.BuildTableList("")
+	built := StartBuildSpan(ctx, "table_list")
+	query, params := qb.BuildTableList(schema)
+	built(query, nil)
 
-	rows, err := r.db.QueryContext(ctx, query, params...)
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table list: %w", err)
 	}
@@ -103,7 +173,7 @@ func (r *MySQLRepository) GetTableList(ctx context.Context) ([]string, error) {
 		if err := rows.Scan(&tableName); err != nil {
 			return nil, fmt.Errorf("failed to scan table name: %w", err)
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, QualifyTableName(r, schema, tableName))
 	}
 
 	if err := rows.Err(); err != nil {
@@ -113,21 +183,80 @@ func (r *MySQLRepository) GetTableList(ctx context.Context) ([]string, error) {
 	return tables, nil
 }
 
+// schemaFilter returns an IN condition restricting column to the configured
+// schemas, with its parameters
+func (r *MySQLRepository) schemaFilter(column string) (string, []any) {
+	schemas := r.Schemas()
+	placeholders := make([]string, len(schemas))
+	params := make([]any, len(schemas))
+	for i, schema := range schemas {
+		placeholders[i] = "?"
+		params[i] = schema
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), params
+}
+
+// GetTableFingerprints returns a cheap change fingerprint for every table,
+// hashed from its create/update times, columns, indexes and constraints
+func (r *MySQLRepository) GetTableFingerprints(ctx context.Context) (map[string]string, error) {
+	var params []any
+	filter := func(column string) string {
+		condition, schemaParams := r.schemaFilter(column)
+		params = append(params, schemaParams...)
+		return condition
+	}
+	query := `
+		SELECT table_schema, table_name, 'table', CAST(create_time AS CHAR), CAST(update_time AS CHAR), NULL, NULL, NULL
+		FROM information_schema.tables
+		WHERE ` + filter("table_schema") + ` AND table_type = 'BASE TABLE'
+		UNION ALL
+		SELECT table_schema, table_name, 'column', LPAD(ordinal_position, 5, '0'), column_name, column_type, is_nullable, column_default
+		FROM information_schema.columns
+		WHERE ` + filter("table_schema") + `
+		UNION ALL
+		SELECT table_schema, table_name, 'index', index_name, LPAD(seq_in_index, 5, '0'), column_name, CAST(non_unique AS CHAR), index_type
+		FROM information_schema.statistics
+		WHERE ` + filter("table_schema") + `
+		UNION ALL
+		SELECT k.table_schema, k.table_name, 'foreign key', k.constraint_name, LPAD(k.ordinal_position, 5, '0'), k.column_name, rc.delete_rule, rc.update_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints rc
+			ON rc.constraint_schema = k.constraint_schema
+			AND rc.constraint_name = k.constraint_name
+		WHERE ` + filter("k.table_schema") + `
+		UNION ALL
+		SELECT table_schema, table_name, 'constraint', constraint_name, constraint_type, NULL, NULL, NULL
+		FROM information_schema.table_constraints
+		WHERE ` + filter("table_schema") + `
+		ORDER BY 1, 2, 3, 4, 5`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table fingerprints: %w", err)
+	}
+	return scanFingerprints(rows, 8, func(schema, table string) string {
+		return QualifyTableName(r, schema, table)
+	})
+}
+
 // GetTableInfo returns detailed information about a table
 func (r *MySQLRepository) GetTableInfo(ctx context.Context, tableName string) (*TableInfo, error) {
+	schema, table := SplitTableName(r, tableName)
 	qb := NewQueryBuilder("mysql")
-	query, params, err := qb.BuildTableSchema(tableName, "")
+	built := StartBuildSpan(ctx, "table_schema")
+	query, params, err := qb.BuildTableSchema(table, schema)
+	built(query, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build table schema query: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, params...)
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table schema: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []ColumnInfo
+	columns := []ColumnInfo{}
 	for rows.Next() {
 		var col ColumnInfo
 		var isNullable string
@@ -149,37 +278,244 @@ func (r *MySQLRepository) GetTableInfo(ctx context.Context, tableName string) (*
 		return nil, fmt.Errorf("error iterating columns: %w", err)
 	}
 
-	// Get row count (approximate)
-	var rowCount int64
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM `%s`", tableName)
-	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&rowCount); err != nil {
-		// Row count is optional, don't fail if we can't get it
-		rowCount = 0
+	// Row count is optional, a failed count leaves it unset
+	rowCount, accuracy := r.rowCounter.count(ctx,
+		func(ctx context.Context) (int64, bool, error) {
+			return r.estimateRowCount(ctx, schema, table)
+		},
+		func(ctx context.Context) (int64, error) {
+			var n int64
+			err := r.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", qb.quoteIdentifier(schema+"."+table))).Scan(&n)
+			return n, err
+		})
+
+	indexes, err := r.GetIndexes(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := r.GetConstraints(ctx, tableName)
+	if err != nil {
+		return nil, err
 	}
 
 	return &TableInfo{
-		TableName: tableName,
-		Columns:   columns,
-		RowCount:  &rowCount,
+		TableName:        QualifyTableName(r, schema, table),
+		Schema:           schema,
+		Columns:          columns,
+		Indexes:          indexes,
+		PrimaryKey:       primaryKeyColumns(constraints),
+		Constraints:      constraints,
+		RowCount:         rowCount,
+		RowCountAccuracy: accuracy,
 	}, nil
 }
 
-// GetForeignKeys returns foreign key information for a table
-func (r *MySQLRepository) GetForeignKeys(ctx context.Context, tableName string) ([]ForeignKeyInfo, error) {
+// GetAllColumns returns the columns and primary keys of every table in the
+// configured schemas, read in one catalog query without the rest of the
+// table metadata
+func (r *MySQLRepository) GetAllColumns(ctx context.Context) (map[string]*TableInfo, error) {
+	condition, params := r.schemaFilter("c.table_schema")
+	query := `
+		SELECT c.table_schema, c.table_name, c.column_name, c.data_type, c.is_nullable, c.column_key = 'PRI'
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+			ON t.table_schema = c.table_schema
+			AND t.table_name = c.table_name
+		WHERE t.table_type = 'BASE TABLE' AND ` + condition + `
+		ORDER BY c.table_schema, c.table_name, c.ordinal_position`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+	return scanTableColumns(r, rows)
+}
+
+// estimateRowCount reads the InnoDB row estimate kept in information_schema
+func (r *MySQLRepository) estimateRowCount(ctx context.Context, schema, table string) (int64, bool, error) {
+	var rows sql.NullInt64
+	err := r.QueryRow(ctx, "SELECT table_rows FROM information_schema.tables WHERE table_schema = ? AND table_name = ?", schema, table).Scan(&rows)
+	return rows.Int64, rows.Valid, err
+}
+
+// GetIndexes returns the indexes of a table with their key columns in order
+func (r *MySQLRepository) GetIndexes(ctx context.Context, tableName string) ([]IndexInfo, error) {
 	query := `
 		SELECT
-			constraint_name,
-			table_name,
+			index_name,
 			column_name,
-			referenced_table_name,
-			referenced_column_name
-		FROM information_schema.key_column_usage
-		WHERE table_schema = DATABASE()
+			sub_part,
+			collation,
+			non_unique,
+			index_type
+		FROM information_schema.statistics
+		WHERE table_schema = ?
 			AND table_name = ?
-			AND referenced_table_name IS NOT NULL
-		ORDER BY constraint_name, ordinal_position`
+		ORDER BY index_name, seq_in_index`
+
+	schema, table := SplitTableName(r, tableName)
+	rows, err := r.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var index IndexInfo
+		var column, collation sql.NullString
+		var subPart sql.NullInt64
+		var nonUnique bool
+		if err := rows.Scan(&index.Name, &column, &subPart, &collation, &nonUnique, &index.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		index.IsUnique = !nonUnique
+		index.IsPrimary = index.Name == "PRIMARY"
+
+		// Functional key parts have no column name
+		name := column.String
+		if !column.Valid {
+			name = "(expression)"
+		} else if subPart.Valid {
+			name = fmt.Sprintf("%s(%d)", name, subPart.Int64)
+		}
+		order := "ASC"
+		if collation.String == "D" {
+			order = "DESC"
+		}
+		indexes = appendIndexColumn(indexes, index, name, order)
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, tableName)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// GetConstraints returns the primary key, unique and check constraints of a table
+func (r *MySQLRepository) GetConstraints(ctx context.Context, tableName string) ([]ConstraintInfo, error) {
+	query := `
+		SELECT
+			tc.constraint_name,
+			tc.constraint_type,
+			k.column_name
+		FROM information_schema.table_constraints tc
+		LEFT JOIN information_schema.key_column_usage k
+			ON k.constraint_schema = tc.constraint_schema
+			AND k.constraint_name = tc.constraint_name
+			AND k.table_name = tc.table_name
+		WHERE tc.table_schema = ?
+			AND tc.table_name = ?
+			AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'CHECK')
+		ORDER BY tc.constraint_name, k.ordinal_position`
+
+	schema, table := SplitTableName(r, tableName)
+	rows, err := r.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var constraints []ConstraintInfo
+	for rows.Next() {
+		var constraint ConstraintInfo
+		var column sql.NullString
+		if err := rows.Scan(&constraint.Name, &constraint.Type, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		constraints = appendConstraintColumn(constraints, constraint, column.String)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating constraints: %w", err)
+	}
+
+	// Check clauses live in check_constraints (MySQL 8.0.16+); older servers
+	// have no enforced checks, so a failing lookup is not an error
+	definitions, err := r.checkClauses(ctx, tableName)
+	if err != nil {
+		return constraints, nil
+	}
+	for i := range constraints {
+		if constraints[i].Type == ConstraintCheck {
+			constraints[i].Definition = definitions[constraints[i].Name]
+		}
+	}
+
+	return constraints, nil
+}
+
+// checkClauses returns the check constraint expressions of a table by name
+func (r *MySQLRepository) checkClauses(ctx context.Context, tableName string) (map[string]string, error) {
+	query := `
+		SELECT
+			cc.constraint_name,
+			cc.check_clause
+		FROM information_schema.check_constraints cc
+		JOIN information_schema.table_constraints tc
+			ON tc.constraint_schema = cc.constraint_schema
+			AND tc.constraint_name = cc.constraint_name
+		WHERE tc.table_schema = ?
+			AND tc.table_name = ?
+			AND tc.constraint_type = 'CHECK'`
+
+	schema, table := SplitTableName(r, tableName)
+	rows, err := r.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	definitions := map[string]string{}
+	for rows.Next() {
+		var name, clause string
+		if err := rows.Scan(&name, &clause); err != nil {
+			return nil, err
+		}
+		definitions[name] = clause
+	}
+	return definitions, rows.Err()
+}
+
+// GetForeignKeys returns foreign key information for a table. Composite keys
+// are returned as one entry with their columns in key order.
+func (r *MySQLRepository) GetForeignKeys(ctx context.Context, tableName string) ([]ForeignKeyInfo, error) {
+	schema, table := SplitTableName(r, tableName)
+	return r.queryForeignKeys(ctx, "k.table_schema = ? AND k.table_name = ?", schema, table)
+}
+
+// GetAllForeignKeys returns the foreign keys of every table in the
+// configured schemas, read in one catalog query
+func (r *MySQLRepository) GetAllForeignKeys(ctx context.Context) ([]ForeignKeyInfo, error) {
+	condition, params := r.schemaFilter("k.table_schema")
+	return r.queryForeignKeys(ctx, condition, params...)
+}
+
+// queryForeignKeys reads the foreign keys of the key columns matching condition
+func (r *MySQLRepository) queryForeignKeys(ctx context.Context, condition string, params ...any) ([]ForeignKeyInfo, error) {
+	query := `
+		SELECT
+			k.constraint_name,
+			k.table_schema,
+			k.table_name,
+			k.column_name,
+			k.referenced_table_schema,
+			k.referenced_table_name,
+			k.referenced_column_name,
+			rc.delete_rule,
+			rc.update_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints rc
+			ON rc.constraint_schema = k.constraint_schema
+			AND rc.constraint_name = k.constraint_name
+			AND rc.table_name = k.table_name
+		WHERE ` + condition + `
+			AND k.referenced_table_name IS NOT NULL
+		ORDER BY k.table_schema, k.table_name, k.constraint_name, k.ordinal_position`
+
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
 	}
@@ -188,10 +524,13 @@ func (r *MySQLRepository) GetForeignKeys(ctx context.Context, tableName string)
 	var foreignKeys []ForeignKeyInfo
 	for rows.Next() {
 		var fk ForeignKeyInfo
-		if err := rows.Scan(&fk.Name, &fk.SourceTable, &fk.SourceColumn, &fk.ReferencedTable, &fk.ReferencedColumn); err != nil {
+		var sourceSchema, sourceTable, sourceColumn, referencedSchema, referencedTable, referencedColumn string
+		if err := rows.Scan(&fk.Name, &sourceSchema, &sourceTable, &sourceColumn, &referencedSchema, &referencedTable, &referencedColumn, &fk.OnDelete, &fk.OnUpdate); err != nil {
 			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
 		}
-		foreignKeys = append(foreignKeys, fk)
+		fk.SourceTable = QualifyTableName(r, sourceSchema, sourceTable)
+		fk.ReferencedTable = QualifyTableName(r, referencedSchema, referencedTable)
+		foreignKeys = appendForeignKeyColumn(foreignKeys, fk, sourceColumn, referencedColumn)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -200,3 +539,265 @@ func (r *MySQLRepository) GetForeignKeys(ctx context.Context, tableName string)
 
 	return foreignKeys, nil
 }
+
+// GetObjects lists the tables, views, routines and triggers of the configured schemas
+func (r *MySQLRepository) GetObjects(ctx context.Context) ([]ObjectInfo, error) {
+	tables, err := r.GetTableList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schemaObjects, err := r.GetSchemaObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]ObjectInfo, 0, len(tables))
+	for _, table := range tables {
+		objects = append(objects, ObjectInfo{Name: table, Type: ObjectTable})
+	}
+	objects = append(objects, schemaObjects.Objects()...)
+	sortObjects(objects)
+	return objects, nil
+}
+
+// GetSchemaObjects returns the views, routines and triggers of the configured schemas.
+// MySQL has no materialized views or sequences.
+func (r *MySQLRepository) GetSchemaObjects(ctx context.Context) (*SchemaObjects, error) {
+	views, err := r.getViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routines, err := r.getRoutines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := r.getTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaObjects{Views: views, Routines: routines, Triggers: triggers}, nil
+}
+
+// getViews returns the views of the configured schemas with their SELECT statements
+func (r *MySQLRepository) getViews(ctx context.Context) ([]ViewInfo, error) {
+	filter, params := r.schemaFilter("table_schema")
+	query := `
+		SELECT table_schema, table_name, view_definition
+		FROM information_schema.views
+		WHERE ` + filter + `
+		ORDER BY table_schema, table_name`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []ViewInfo
+	for rows.Next() {
+		var view ViewInfo
+		var schema, name string
+		var definition sql.NullString
+		if err := rows.Scan(&schema, &name, &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		view.Name = QualifyTableName(r, schema, name)
+		view.Definition = definition.String
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
+	}
+
+	return views, nil
+}
+
+// getRoutines returns the stored procedures and functions of the configured schemas
+func (r *MySQLRepository) getRoutines(ctx context.Context) ([]RoutineInfo, error) {
+	parameters, err := r.routineParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, params := r.schemaFilter("routine_schema")
+	query := `
+		SELECT
+			routine_schema,
+			specific_name,
+			routine_name,
+			LOWER(routine_type),
+			dtd_identifier,
+			routine_body,
+			is_deterministic,
+			sql_data_access
+		FROM information_schema.routines
+		WHERE ` + filter + `
+		ORDER BY routine_schema, routine_name`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []RoutineInfo
+	for rows.Next() {
+		var routine RoutineInfo
+		var schema, specificName, name, deterministic string
+		var returnType sql.NullString
+		if err := rows.Scan(&schema, &specificName, &name, &routine.Type, &returnType,
+			&routine.Language, &deterministic, &routine.DataAccess); err != nil {
+			return nil, fmt.Errorf("failed to scan routine: %w", err)
+		}
+		routine.Name = QualifyTableName(r, schema, name)
+		routine.Signature = fmt.Sprintf("%s(%s)", routine.Name, strings.Join(parameters[schema+"."+specificName], ", "))
+		routine.ReturnType = returnType.String
+		routine.Volatility = "NOT DETERMINISTIC"
+		if deterministic == "YES" {
+			routine.Volatility = "DETERMINISTIC"
+		}
+		routines = append(routines, routine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routines: %w", err)
+	}
+
+	return routines, nil
+}
+
+// routineParameters returns the formatted parameter list of every routine,
+// keyed by schema.specific_name
+func (r *MySQLRepository) routineParameters(ctx context.Context) (map[string][]string, error) {
+	filter, params := r.schemaFilter("specific_schema")
+	query := `
+		SELECT specific_schema, specific_name, parameter_mode, parameter_name, dtd_identifier
+		FROM information_schema.parameters
+		WHERE ` + filter + `
+			AND ordinal_position > 0
+		ORDER BY specific_schema, specific_name, ordinal_position`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routine parameters: %w", err)
+	}
+	defer rows.Close()
+
+	parameters := map[string][]string{}
+	for rows.Next() {
+		var schema, specificName, dataType string
+		var mode, name sql.NullString
+		if err := rows.Scan(&schema, &specificName, &mode, &name, &dataType); err != nil {
+			return nil, fmt.Errorf("failed to scan routine parameter: %w", err)
+		}
+		// Function parameters have no mode
+		parameter := strings.TrimSpace(strings.Join([]string{mode.String, name.String, dataType}, " "))
+		key := schema + "." + specificName
+		parameters[key] = append(parameters[key], parameter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routine parameters: %w", err)
+	}
+
+	return parameters, nil
+}
+
+// getTriggers returns the triggers of the configured schemas with their target tables
+func (r *MySQLRepository) getTriggers(ctx context.Context) ([]TriggerInfo, error) {
+	filter, params := r.schemaFilter("trigger_schema")
+	query := `
+		SELECT trigger_schema, trigger_name, event_object_schema, event_object_table, action_timing, event_manipulation, action_statement
+		FROM information_schema.triggers
+		WHERE ` + filter + `
+		ORDER BY trigger_schema, trigger_name, event_manipulation`
+
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []TriggerInfo
+	for rows.Next() {
+		var trigger TriggerInfo
+		var schema, name, tableSchema, table, event string
+		if err := rows.Scan(&schema, &name, &tableSchema, &table, &trigger.Timing, &event, &trigger.Statement); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		trigger.Name = QualifyTableName(r, schema, name)
+		trigger.Table = QualifyTableName(r, tableSchema, table)
+		triggers = appendTriggerEvent(triggers, trigger, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return triggers, nil
+}
+
+// GetObjectDefinition returns the DDL of an object as reported by SHOW CREATE.
+// The object must exist; use ResolveObject first.
+func (r *MySQLRepository) GetObjectDefinition(ctx context.Context, objectType, name string) (string, error) {
+	var keyword string
+	switch objectType {
+	case ObjectTable:
+		keyword = "TABLE"
+	case ObjectView:
+		keyword = "VIEW"
+	case ObjectProcedure:
+		keyword = "PROCEDURE"
+	case ObjectFunction:
+		keyword = "FUNCTION"
+	case ObjectTrigger:
+		keyword = "TRIGGER"
+	default:
+		return "", toolerrors.Newf(toolerrors.CodeUnsupported, "MySQL has no %s objects", strings.ReplaceAll(objectType, "_", " "))
+	}
+
+	// SHOW statements take no placeholders; the name is validated and quoted
+	if err := ValidateIdentifier(objectType, name); err != nil {
+		return "", err
+	}
+	schema, object := SplitTableName(r, name)
+	qb := NewQueryBuilder("mysql")
+	rows, err := r.Query(ctx, fmt.Sprintf("SHOW CREATE %s %s", keyword, qb.quoteIdentifier(schema+"."+object)))
+	if err != nil {
+		return "", fmt.Errorf("failed to show %s definition: %w", objectType, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s definition: %w", objectType, err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("failed to read %s definition: %w", objectType, err)
+		}
+		return "", fmt.Errorf("no definition returned for %s %s", objectType, name)
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", fmt.Errorf("failed to scan %s definition: %w", objectType, err)
+	}
+
+	// The DDL column is "Create <Keyword>", or "SQL Original Statement" for triggers
+	for i, column := range columns {
+		if !strings.HasPrefix(column, "Create ") && column != "SQL Original Statement" {
+			continue
+		}
+		if !values[i].Valid {
+			return "", toolerrors.Newf(toolerrors.CodePermissionDenied, "the definition of %s %s is not visible to this user", objectType, name).
+				WithHint("Routine bodies require the SHOW_ROUTINE privilege or ownership of the routine.")
+		}
+		return values[i].String, nil
+	}
+	return "", fmt.Errorf("no definition column returned for %s %s", objectType, name)
+}
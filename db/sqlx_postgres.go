@@ -4,24 +4,33 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
 )
 
 // PostgresRepository implements Repository for PostgreSQL databases
 type PostgresRepository struct {
-	db     *sqlx.DB
-	name   string
-	config config.PostgresConfig
+	db         *sqlx.DB
+	name       string
+	config     config.PostgresConfig
+	rowCounter rowCounter
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
 // CRITICAL: Uses parameterized queries throughout to prevent SQL injection
 func NewPostgresRepository(cfg config.PostgresConfig) (*PostgresRepository, error) {
+	counter, err := newRowCounter(cfg.RowCount, cfg.RowCountTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgreSQL config %s: %w", cfg.Name, err)
+	}
+
 	// Connect to PostgreSQL database
 	db, err := sqlx.Connect("postgres", cfg.DSN())
 	if err != nil {
@@ -42,28 +51,63 @@ func NewPostgresRepository(cfg config.PostgresConfig) (*PostgresRepository, erro
 		return nil, fmt.Errorf("failed to ping PostgreSQL %s: %w", cfg.Name, err)
 	}
 
+	// Tables outside the search path are reached through their schema
+	if len(cfg.Schemas) == 0 {
+		cfg.Schemas = []string{"public"}
+	}
+
 	return &PostgresRepository{
-		db:     db,
-		name:   cfg.Name,
-		config: cfg,
+		db:         db,
+		name:       cfg.Name,
+		config:     cfg,
+		rowCounter: counter,
 	}, nil
 }
 
 // Query executes a parameterized SELECT query
 // CRITICAL: Always use parameterized queries. Never concatenate user input into SQL!
-func (r *PostgresRepository) Query(ctx context.Context, query string, params ...any) (*sql.Rows, error) {
-	return r.db.QueryContext(ctx, query, params...)
+func (r *PostgresRepository) Query(ctx context.Context, query string, params ...any) (*Rows, error) {
+	ctx, span := startQuerySpan(ctx, "postgresql", r.name, "query", query)
+	query, stop := r.watchCancellation(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		stop()
+		tracing.End(span, err)
+		return nil, err
+	}
+	// Rows stream until closed, so the statement is watched until then
+	return newRows(rows, func(err error) {
+		stop()
+		tracing.End(span, err)
+	}), nil
 }
 
 // QueryRow executes a parameterized query that returns at most one row
 func (r *PostgresRepository) QueryRow(ctx context.Context, query string, params ...any) *sql.Row {
-	return r.db.QueryRowContext(ctx, query, params...)
+	ctx, span := startQuerySpan(ctx, "postgresql", r.name, "query_row", query)
+	query, stop := r.watchCancellation(ctx, query)
+	row := r.db.QueryRowContext(ctx, query, params...)
+	stop()
+	tracing.End(span, row.Err())
+	return row
 }
 
 // Exec executes a parameterized statement (INSERT, UPDATE, DELETE)
 // CRITICAL: Always use parameterized queries. Never concatenate user input!
 func (r *PostgresRepository) Exec(ctx context.Context, query string, params ...any) (sql.Result, error) {
-	return r.db.ExecContext(ctx, query, params...)
+	ctx, span := startQuerySpan(ctx, "postgresql", r.name, "exec", query)
+	query, stop := r.watchCancellation(ctx, query)
+	result, err := r.db.ExecContext(ctx, query, params...)
+	stop()
+	tracing.End(span, err)
+	return result, err
+}
+
+// watchCancellation cancels the statement server-side if ctx is cancelled while it runs
+func (r *PostgresRepository) watchCancellation(ctx context.Context, query string) (string, func()) {
+	return watchCancellation(ctx, query, func(ctx context.Context, tag string) error {
+		return cancelPostgres(ctx, r, tag)
+	})
 }
 
 // Close closes the database connection
@@ -81,17 +125,43 @@ func (r *PostgresRepository) GetDriver() string {
 	return "postgres"
 }
 
+// Schemas returns the schemas tables are listed from; the first is the default
+func (r *PostgresRepository) Schemas() []string {
+	return r.config.Schemas
+}
+
 // Ping checks if the database connection is alive
 func (r *PostgresRepository) Ping(ctx context.Context) error {
 	return r.db.PingContext(ctx)
 }
 
-// GetTableList returns a list of all tables in the database
+// Stats returns connection pool statistics
+func (r *PostgresRepository) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+// GetTableList returns a list of all tables in the configured schemas.
+// Tables outside the default schema are qualified as schema.table.
 func (r *PostgresRepository) GetTableList(ctx context.Context) ([]string, error) {
+	tables := []string{}
+	for _, schema := range r.Schemas() {
+		schemaTables, err := r.getSchemaTables(ctx, schema)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, schemaTables...)
+	}
+	return tables, nil
+}
+
+// getSchemaTables returns the qualified names of the tables in one schema
+func (r *PostgresRepository) getSchemaTables(ctx context.Context, schema string) ([]string, error) {
 	qb := NewQueryBuilder("postgres")
-	query, params := qb.BuildTableList("")
+	built := StartBuildSpan(ctx, "table_list")
+	query, params := qb.BuildTableList(schema)
+	built(query, nil)
 
-	rows, err := r.db.QueryContext(ctx, query, params...)
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table list: %w", err)
 	}
@@ -103,7 +173,7 @@ func (r *PostgresRepository) GetTableList(ctx context.Context) ([]string, error)
 		if err := rows.Scan(&tableName); err != nil {
 			return nil, fmt.Errorf("failed to scan table name: %w", err)
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, QualifyTableName(r, schema, tableName))
 	}
 
 	if err := rows.Err(); err != nil {
@@ -113,21 +183,64 @@ func (r *PostgresRepository) GetTableList(ctx context.Context) ([]string, error)
 	return tables, nil
 }
 
+// GetTableFingerprints returns a cheap change fingerprint for every table,
+// hashed from its relkind, columns, indexes and constraints. Only structure
+// counts, so ANALYZE and autovacuum leave the fingerprint unchanged.
+func (r *PostgresRepository) GetTableFingerprints(ctx context.Context) (map[string]string, error) {
+	query := `
+		WITH t AS (
+			SELECT c.oid, n.nspname, c.relname, c.relkind
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p')
+		)
+		SELECT t.nspname, t.relname, 'table', t.relkind::text, NULL, NULL, NULL
+		FROM t
+		UNION ALL
+		SELECT t.nspname, t.relname, 'column', lpad(a.attnum::text, 5, '0'), a.attname::text,
+			format_type(a.atttypid, a.atttypmod) || ' ' || a.attnotnull::text,
+			pg_get_expr(d.adbin, d.adrelid)
+		FROM t
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum > 0 AND NOT a.attisdropped
+		LEFT JOIN pg_attrdef d ON d.adrelid = t.oid AND d.adnum = a.attnum
+		UNION ALL
+		SELECT t.nspname, t.relname, 'index', ic.relname::text, pg_get_indexdef(i.indexrelid), NULL, NULL
+		FROM t
+		JOIN pg_index i ON i.indrelid = t.oid
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		UNION ALL
+		SELECT t.nspname, t.relname, 'constraint', co.conname::text, pg_get_constraintdef(co.oid), NULL, NULL
+		FROM t
+		JOIN pg_constraint co ON co.conrelid = t.oid
+		ORDER BY 1, 2, 3, 4`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table fingerprints: %w", err)
+	}
+	return scanFingerprints(rows, 7, func(schema, table string) string {
+		return QualifyTableName(r, schema, table)
+	})
+}
+
 // GetTableInfo returns detailed information about a table
 func (r *PostgresRepository) GetTableInfo(ctx context.Context, tableName string) (*TableInfo, error) {
+	schema, table := SplitTableName(r, tableName)
 	qb := NewQueryBuilder("postgres")
-	query, params, err := qb.BuildTableSchema(tableName, "")
+	built := StartBuildSpan(ctx, "table_schema")
+	query, params, err := qb.BuildTableSchema(table, schema)
+	built(query, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build table schema query: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, params...)
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table schema: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []ColumnInfo
+	columns := []ColumnInfo{}
 	for rows.Next() {
 		var col ColumnInfo
 		var isNullable string
@@ -149,43 +262,240 @@ func (r *PostgresRepository) GetTableInfo(ctx context.Context, tableName string)
 		return nil, fmt.Errorf("error iterating columns: %w", err)
 	}
 
-	// Get row count (approximate from pg_class)
-	var rowCount int64
-	countQuery := fmt.Sprintf("SELECT reltuples::bigint FROM pg_class WHERE relname = $1")
-	if err := r.db.QueryRowContext(ctx, countQuery, tableName).Scan(&rowCount); err != nil {
-		// Row count is optional, don't fail if we can't get it
-		rowCount = 0
+	// Row count is optional, a failed count leaves it unset
+	rowCount, accuracy := r.rowCounter.count(ctx,
+		func(ctx context.Context) (int64, bool, error) {
+			return r.estimateRowCount(ctx, schema, table)
+		},
+		func(ctx context.Context) (int64, error) {
+			var n int64
+			err := r.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", qb.quoteIdentifier(schema+"."+table))).Scan(&n)
+			return n, err
+		})
+
+	indexes, err := r.GetIndexes(ctx, tableName)
+	if err != nil {
+		return nil, err
 	}
+	constraints, err := r.GetConstraints(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	primaryKey := primaryKeyColumns(constraints)
+	markPrimaryKey(columns, primaryKey)
 
 	return &TableInfo{
-		TableName: tableName,
-		Schema:    "public",
-		Columns:   columns,
-		RowCount:  &rowCount,
+		TableName:        QualifyTableName(r, schema, table),
+		Schema:           schema,
+		Columns:          columns,
+		Indexes:          indexes,
+		PrimaryKey:       primaryKey,
+		Constraints:      constraints,
+		RowCount:         rowCount,
+		RowCountAccuracy: accuracy,
 	}, nil
 }
 
-// GetForeignKeys returns foreign key information for a table
+// GetAllColumns returns the columns and primary keys of every table in the
+// configured schemas, read in one catalog query without the rest of the
+// table metadata
+func (r *PostgresRepository) GetAllColumns(ctx context.Context) (map[string]*TableInfo, error) {
+	query := `
+		SELECT
+			c.table_schema,
+			c.table_name,
+			c.column_name,
+			c.data_type,
+			c.is_nullable,
+			EXISTS (
+				SELECT 1
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON kcu.constraint_schema = tc.constraint_schema
+					AND kcu.constraint_name = tc.constraint_name
+					AND kcu.table_name = tc.table_name
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+					AND tc.table_schema = c.table_schema
+					AND tc.table_name = c.table_name
+					AND kcu.column_name = c.column_name
+			)
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+			ON t.table_schema = c.table_schema
+			AND t.table_name = c.table_name
+		WHERE t.table_type = 'BASE TABLE' AND c.table_schema = ANY($1)
+		ORDER BY c.table_schema, c.table_name, c.ordinal_position`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+	return scanTableColumns(r, rows)
+}
+
+// estimateRowCount reads the planner estimate from pg_class. Tables that were
+// never vacuumed or analyzed report -1 (PostgreSQL 14+) and have no estimate.
+func (r *PostgresRepository) estimateRowCount(ctx context.Context, schema, table string) (int64, bool, error) {
+	query := `
+		SELECT c.reltuples::bigint
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2`
+
+	var rows int64
+	err := r.QueryRow(ctx, query, schema, table).Scan(&rows)
+	return rows, rows >= 0, err
+}
+
+// GetIndexes returns the indexes of a table with their key columns in order
+func (r *PostgresRepository) GetIndexes(ctx context.Context, tableName string) ([]IndexInfo, error) {
+	query := `
+		SELECT
+			ic.relname,
+			pg_get_indexdef(i.indexrelid, k.ord::int, true),
+			(i.indoption[k.ord - 1] & 1) = 1,
+			i.indisunique,
+			i.indisprimary,
+			am.amname,
+			COALESCE(pg_get_expr(i.indpred, i.indrelid, true), '')
+		FROM pg_index i
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = ic.relam
+		CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		WHERE n.nspname = $1
+			AND t.relname = $2
+			AND k.ord <= i.indnkeyatts
+		ORDER BY ic.relname, k.ord`
+
+	schema, table := SplitTableName(r, tableName)
+	rows, err := r.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var index IndexInfo
+		var column string
+		var descending bool
+		if err := rows.Scan(&index.Name, &column, &descending, &index.IsUnique, &index.IsPrimary, &index.Type, &index.Predicate); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		order := "ASC"
+		if descending {
+			order = "DESC"
+		}
+		indexes = appendIndexColumn(indexes, index, column, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// GetConstraints returns the primary key, unique and check constraints of a table
+func (r *PostgresRepository) GetConstraints(ctx context.Context, tableName string) ([]ConstraintInfo, error) {
+	query := `
+		SELECT
+			c.conname,
+			c.contype::text,
+			ARRAY(
+				SELECT a.attname::text
+				FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			pg_get_constraintdef(c.oid, true)
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+			AND t.relname = $2
+			AND c.contype IN ('p', 'u', 'c')
+		ORDER BY c.conname`
+
+	schema, table := SplitTableName(r, tableName)
+	rows, err := r.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var constraints []ConstraintInfo
+	for rows.Next() {
+		var constraint ConstraintInfo
+		var contype, definition string
+		var columns []string
+		if err := rows.Scan(&constraint.Name, &contype, pq.Array(&columns), &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		constraint.Type = postgresConstraintType(contype)
+		constraint.Columns = columns
+		if constraint.Type == ConstraintCheck {
+			constraint.Definition = definition
+		}
+		constraints = append(constraints, constraint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating constraints: %w", err)
+	}
+
+	return constraints, nil
+}
+
+// GetForeignKeys returns foreign key information for a table. Composite keys
+// are returned as one entry with their columns in key order.
 func (r *PostgresRepository) GetForeignKeys(ctx context.Context, tableName string) ([]ForeignKeyInfo, error) {
+	schema, table := SplitTableName(r, tableName)
+	return r.queryForeignKeys(ctx, "n.nspname = $1 AND t.relname = $2", schema, table)
+}
+
+// GetAllForeignKeys returns the foreign keys of every table in the
+// configured schemas, read in one catalog query
+func (r *PostgresRepository) GetAllForeignKeys(ctx context.Context) ([]ForeignKeyInfo, error) {
+	return r.queryForeignKeys(ctx, "n.nspname = ANY($1)", pq.Array(r.Schemas()))
+}
+
+// queryForeignKeys reads the foreign keys of the tables matching condition
+func (r *PostgresRepository) queryForeignKeys(ctx context.Context, condition string, params ...any) ([]ForeignKeyInfo, error) {
 	query := `
 		SELECT
-			tc.constraint_name,
-			tc.table_name,
-			kcu.column_name,
-			ccu.table_name AS foreign_table_name,
-			ccu.column_name AS foreign_column_name
-		FROM information_schema.table_constraints AS tc
-		JOIN information_schema.key_column_usage AS kcu
-			ON tc.constraint_name = kcu.constraint_name
-			AND tc.table_schema = kcu.table_schema
-		JOIN information_schema.constraint_column_usage AS ccu
-			ON ccu.constraint_name = tc.constraint_name
-			AND ccu.table_schema = tc.table_schema
-		WHERE tc.constraint_type = 'FOREIGN KEY'
-			AND tc.table_name = $1
-		ORDER BY tc.constraint_name`
-
-	rows, err := r.db.QueryContext(ctx, query, tableName)
+			c.conname,
+			n.nspname,
+			t.relname,
+			rn.nspname,
+			rt.relname,
+			ARRAY(
+				SELECT a.attname::text
+				FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			ARRAY(
+				SELECT a.attname::text
+				FROM unnest(c.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			c.confdeltype::text,
+			c.confupdtype::text
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class rt ON rt.oid = c.confrelid
+		JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+		WHERE c.contype = 'f'
+			AND ` + condition + `
+		ORDER BY n.nspname, t.relname, c.conname`
+
+	rows, err := r.Query(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
 	}
@@ -194,10 +504,22 @@ func (r *PostgresRepository) GetForeignKeys(ctx context.Context, tableName strin
 	var foreignKeys []ForeignKeyInfo
 	for rows.Next() {
 		var fk ForeignKeyInfo
-		if err := rows.Scan(&fk.Name, &fk.SourceTable, &fk.SourceColumn, &fk.ReferencedTable, &fk.ReferencedColumn); err != nil {
+		var sourceSchema, sourceTable, referencedSchema, referencedTable string
+		var sourceColumns, referencedColumns []string
+		var onDelete, onUpdate string
+		if err := rows.Scan(&fk.Name, &sourceSchema, &sourceTable, &referencedSchema, &referencedTable,
+			pq.Array(&sourceColumns), pq.Array(&referencedColumns), &onDelete, &onUpdate); err != nil {
 			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
 		}
-		foreignKeys = append(foreignKeys, fk)
+		fk.SourceTable = QualifyTableName(r, sourceSchema, sourceTable)
+		fk.ReferencedTable = QualifyTableName(r, referencedSchema, referencedTable)
+		fk.OnDelete = postgresReferentialAction(onDelete)
+		fk.OnUpdate = postgresReferentialAction(onUpdate)
+		for i := range sourceColumns {
+			if i < len(referencedColumns) {
+				foreignKeys = appendForeignKeyColumn(foreignKeys, fk, sourceColumns[i], referencedColumns[i])
+			}
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -206,3 +528,333 @@ func (r *PostgresRepository) GetForeignKeys(ctx context.Context, tableName strin
 
 	return foreignKeys, nil
 }
+
+// GetObjects lists the tables, views, routines, triggers and sequences of the configured schemas
+func (r *PostgresRepository) GetObjects(ctx context.Context) ([]ObjectInfo, error) {
+	tables, err := r.GetTableList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schemaObjects, err := r.GetSchemaObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]ObjectInfo, 0, len(tables))
+	for _, table := range tables {
+		objects = append(objects, ObjectInfo{Name: table, Type: ObjectTable})
+	}
+	objects = append(objects, schemaObjects.Objects()...)
+	sortObjects(objects)
+	return objects, nil
+}
+
+// GetSchemaObjects returns the views, materialized views, routines, triggers
+// and sequences of the configured schemas
+func (r *PostgresRepository) GetSchemaObjects(ctx context.Context) (*SchemaObjects, error) {
+	views, err := r.getViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routines, err := r.getRoutines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := r.getTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sequences, err := r.getSequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaObjects{Views: views, Routines: routines, Triggers: triggers, Sequences: sequences}, nil
+}
+
+// getViews returns the views and materialized views with their SELECT statements
+func (r *PostgresRepository) getViews(ctx context.Context) ([]ViewInfo, error) {
+	query := `
+		SELECT schemaname::text, viewname::text, false, definition FROM pg_views WHERE schemaname = ANY($1)
+		UNION ALL
+		SELECT schemaname::text, matviewname::text, true, definition FROM pg_matviews WHERE schemaname = ANY($1)
+		ORDER BY 1, 2`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []ViewInfo
+	for rows.Next() {
+		var view ViewInfo
+		var schema, name string
+		var definition sql.NullString
+		if err := rows.Scan(&schema, &name, &view.Materialized, &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		view.Name = QualifyTableName(r, schema, name)
+		view.Definition = strings.TrimSpace(definition.String)
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
+	}
+
+	return views, nil
+}
+
+// getRoutines returns the procedures and functions of the configured schemas, leaving
+// out aggregates and functions that belong to extensions
+func (r *PostgresRepository) getRoutines(ctx context.Context) ([]RoutineInfo, error) {
+	query := `
+		SELECT
+			n.nspname::text,
+			p.proname::text,
+			CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END,
+			p.proname || '(' || pg_get_function_arguments(p.oid) || ')',
+			COALESCE(pg_get_function_result(p.oid), ''),
+			l.lanname::text,
+			CASE p.provolatile WHEN 'i' THEN 'IMMUTABLE' WHEN 's' THEN 'STABLE' ELSE 'VOLATILE' END
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		JOIN pg_language l ON l.oid = p.prolang
+		WHERE n.nspname = ANY($1)
+			AND p.prokind IN ('f', 'p')
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_depend d
+				WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+			)
+		ORDER BY n.nspname, p.proname, p.oid`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []RoutineInfo
+	for rows.Next() {
+		var routine RoutineInfo
+		var schema, name string
+		if err := rows.Scan(&schema, &name, &routine.Type, &routine.Signature,
+			&routine.ReturnType, &routine.Language, &routine.Volatility); err != nil {
+			return nil, fmt.Errorf("failed to scan routine: %w", err)
+		}
+		routine.Name = QualifyTableName(r, schema, name)
+		routines = append(routines, routine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routines: %w", err)
+	}
+
+	return routines, nil
+}
+
+// getTriggers returns the triggers of the configured schemas with their target tables
+func (r *PostgresRepository) getTriggers(ctx context.Context) ([]TriggerInfo, error) {
+	query := `
+		SELECT trigger_schema, trigger_name, event_object_schema, event_object_table, action_timing, event_manipulation, action_statement
+		FROM information_schema.triggers
+		WHERE trigger_schema = ANY($1)
+		ORDER BY trigger_schema, trigger_name, event_object_table, event_manipulation`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []TriggerInfo
+	for rows.Next() {
+		var trigger TriggerInfo
+		var schema, name, tableSchema, table, event string
+		if err := rows.Scan(&schema, &name, &tableSchema, &table, &trigger.Timing, &event, &trigger.Statement); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		trigger.Name = QualifyTableName(r, schema, name)
+		trigger.Table = QualifyTableName(r, tableSchema, table)
+		triggers = appendTriggerEvent(triggers, trigger, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return triggers, nil
+}
+
+// getSequences returns the sequences of the configured schemas
+func (r *PostgresRepository) getSequences(ctx context.Context) ([]SequenceInfo, error) {
+	query := `
+		SELECT sequence_schema, sequence_name, data_type, start_value, increment, minimum_value, maximum_value, cycle_option
+		FROM information_schema.sequences
+		WHERE sequence_schema = ANY($1)
+		ORDER BY sequence_schema, sequence_name`
+
+	rows, err := r.Query(ctx, query, pq.Array(r.Schemas()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var sequences []SequenceInfo
+	for rows.Next() {
+		var sequence SequenceInfo
+		var schema, name, cycle string
+		if err := rows.Scan(&schema, &name, &sequence.DataType, &sequence.Start, &sequence.Increment,
+			&sequence.Minimum, &sequence.Maximum, &cycle); err != nil {
+			return nil, fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		sequence.Name = QualifyTableName(r, schema, name)
+		sequence.Cycle = cycle == "YES"
+		sequences = append(sequences, sequence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sequences: %w", err)
+	}
+
+	return sequences, nil
+}
+
+// GetObjectDefinition returns the DDL of an object, rebuilt from the catalog
+// with the pg_get_*def functions. The object must exist; use ResolveObject first.
+func (r *PostgresRepository) GetObjectDefinition(ctx context.Context, objectType, name string) (string, error) {
+	schema, object := SplitTableName(r, name)
+	switch objectType {
+	case ObjectTable:
+		return r.tableDefinition(ctx, schema, object)
+	case ObjectView, ObjectMaterializedView:
+		keyword := "CREATE OR REPLACE VIEW"
+		if objectType == ObjectMaterializedView {
+			keyword = "CREATE MATERIALIZED VIEW"
+		}
+		return r.definition(ctx, `
+			SELECT $3::text || ' ' || quote_ident($1) || '.' || quote_ident($2) || E' AS\n' ||
+				pg_get_viewdef(to_regclass(quote_ident($1) || '.' || quote_ident($2)), true)`,
+			schema, object, keyword)
+	case ObjectProcedure, ObjectFunction:
+		kind := "f"
+		if objectType == ObjectProcedure {
+			kind = "p"
+		}
+		// Overloads are returned together
+		return r.definition(ctx, `
+			SELECT string_agg(pg_get_functiondef(p.oid), E'\n' ORDER BY p.oid)
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2 AND p.prokind = $3`,
+			schema, object, kind)
+	case ObjectTrigger:
+		return r.definition(ctx, `
+			SELECT string_agg(pg_get_triggerdef(t.oid, true) || ';', E'\n' ORDER BY c.relname)
+			FROM pg_trigger t
+			JOIN pg_class c ON c.oid = t.tgrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND t.tgname = $2 AND NOT t.tgisinternal`,
+			schema, object)
+	case ObjectSequence:
+		sequences, err := r.getSequences(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, sequence := range sequences {
+			if sequence.Name == name {
+				return formatSequenceDDL(sequence), nil
+			}
+		}
+		return "", fmt.Errorf("sequence %s not found", name)
+	default:
+		return "", toolerrors.Newf(toolerrors.CodeUnsupported, "unsupported object type %q", objectType)
+	}
+}
+
+// definition runs a catalog query that returns a single DDL string
+func (r *PostgresRepository) definition(ctx context.Context, query string, params ...any) (string, error) {
+	var ddl sql.NullString
+	if err := r.QueryRow(ctx, query, params...).Scan(&ddl); err != nil {
+		return "", fmt.Errorf("failed to read definition: %w", err)
+	}
+	return ddl.String, nil
+}
+
+// tableDefinition assembles CREATE TABLE and CREATE INDEX statements for a
+// table, since PostgreSQL has no single function returning table DDL
+func (r *PostgresRepository) tableDefinition(ctx context.Context, schema, tableName string) (string, error) {
+	const relation = "to_regclass(quote_ident($1) || '.' || quote_ident($2))"
+
+	var qualified string
+	if err := r.QueryRow(ctx, "SELECT quote_ident($1) || '.' || quote_ident($2)", schema, tableName).Scan(&qualified); err != nil {
+		return "", fmt.Errorf("failed to quote table name: %w", err)
+	}
+
+	// Columns and constraints form the body of CREATE TABLE
+	lines, err := r.definitionLines(ctx, `
+		SELECT quote_ident(a.attname) || ' ' || format_type(a.atttypid, a.atttypmod) ||
+			CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END ||
+			COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '')
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = `+relation+`
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+		ORDER BY a.attnum`, schema, tableName)
+	if err != nil {
+		return "", err
+	}
+	constraints, err := r.definitionLines(ctx, `
+		SELECT 'CONSTRAINT ' || quote_ident(conname) || ' ' || pg_get_constraintdef(oid, true)
+		FROM pg_constraint
+		WHERE conrelid = `+relation+`
+		ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'c' THEN 2 WHEN 'f' THEN 3 ELSE 4 END, conname`,
+		schema, tableName)
+	if err != nil {
+		return "", err
+	}
+	lines = append(lines, constraints...)
+
+	// Indexes that back a constraint are created by it
+	indexes, err := r.definitionLines(ctx, `
+		SELECT pg_get_indexdef(i.indexrelid) || ';'
+		FROM pg_index i
+		LEFT JOIN pg_constraint c ON c.conindid = i.indexrelid AND c.conrelid = i.indrelid
+		WHERE i.indrelid = `+relation+`
+			AND c.oid IS NULL
+		ORDER BY 1`, schema, tableName)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE %s (\n    %s\n);", qualified, strings.Join(lines, ",\n    "))
+	for _, index := range indexes {
+		sb.WriteString("\n\n")
+		sb.WriteString(index)
+	}
+	return sb.String(), nil
+}
+
+// definitionLines runs a catalog query that returns one DDL fragment per row
+func (r *PostgresRepository) definitionLines(ctx context.Context, query string, params ...any) ([]string, error) {
+	rows, err := r.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read definition: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan definition: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating definition: %w", err)
+	}
+	return lines, nil
+}
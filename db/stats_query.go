@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// How the metrics of an aggregation are computed
+const (
+	MethodNative = "native" // an aggregate function of the engine
+	MethodWindow = "window" // emulated with window functions over ranked rows (MySQL percentiles)
+	MethodSample = "sample" // computed by the server over a bounded sample of rows
+	MethodApprox = "approx" // estimated by the server from a HyperLogLog sketch
+	MethodExact  = "exact"  // an approximate metric computed exactly, as no approximation is available
+)
+
+// MetricMethod returns how BuildAggregationQuery computes a normalized metric
+func (qb *QueryBuilder) MetricMethod(m Metric) string {
+	switch _, percentile := m.Percentile(); {
+	case m.Fn == "APPROX_COUNT_DISTINCT":
+		return MethodExact
+	case percentile && qb.driver != "postgres":
+		return MethodWindow
+	default:
+		return MethodNative
+	}
+}
+
+// SupportsWindowFunctions reports whether the database runs the window
+// functions that MySQL percentiles are emulated with: PostgreSQL, MySQL 8.0+
+// and MariaDB 10.2+
+func SupportsWindowFunctions(ctx context.Context, repo Repository) (bool, error) {
+	if repo.GetDriver() == "postgres" {
+		return true, nil
+	}
+	var version string
+	if err := repo.QueryRow(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return false, err
+	}
+	return windowFunctionVersion(version), nil
+}
+
+// windowFunctionVersion reports whether a MySQL or MariaDB version string
+// such as 8.0.35 or 10.6.12-MariaDB names a release with window functions
+func windowFunctionVersion(version string) bool {
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	major, _ := strconv.Atoi(parts[0])
+	minor := 0
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return major > 10 || (major == 10 && minor >= 2)
+	}
+	return major >= 8
+}
+
+// percentileWindows returns the window columns ranking the non-NULL values of
+// a column within each group, as _pct_rank_i, and counting them, as _pct_count_i
+func (qb *QueryBuilder) percentileWindows(column string, groupBy []string, i int) string {
+	col := qb.quoteIdentifier(column)
+	partition := ""
+	if len(groupBy) > 0 {
+		partition = "PARTITION BY " + strings.Join(groupBy, ", ")
+	}
+	order := strings.TrimSpace(fmt.Sprintf("%s ORDER BY %s IS NULL, %s", partition, col, col))
+	return fmt.Sprintf("ROW_NUMBER() OVER (%s) AS %s, COUNT(%s) OVER (%s) AS %s",
+		order, qb.quote(fmt.Sprintf("_pct_rank_%d", i)), col, partition, qb.quote(fmt.Sprintf("_pct_count_%d", i)))
+}
+
+// windowPercentile returns the aggregate computing the continuous percentile
+// p of a column from its percentileWindows: the values ranked around
+// p*(n-1), interpolated as percentile_cont does
+func (qb *QueryBuilder) windowPercentile(column string, p float64, i int) string {
+	col := qb.quoteIdentifier(column)
+	rank := qb.quote(fmt.Sprintf("_pct_rank_%d", i))
+	count := qb.quote(fmt.Sprintf("_pct_count_%d", i))
+	offset := fmt.Sprintf("%s * (%s - 1)", formatFraction(p), count)
+	lower := fmt.Sprintf("MAX(CASE WHEN %s = 1 + FLOOR(%s) THEN %s END)", rank, offset, col)
+	upper := fmt.Sprintf("MAX(CASE WHEN %s = 1 + CEIL(%s) THEN %s END)", rank, offset, col)
+	groupOffset := fmt.Sprintf("%s * (MAX(%s) - 1)", formatFraction(p), count)
+	return fmt.Sprintf("(%s + (%s - FLOOR(%s)) * (%s - %s))", lower, groupOffset, groupOffset, upper, lower)
+}
+
+// BuildStatsSample builds a query for at most limit rows of the table of an
+// aggregation, filtered by its conditions, holding its group-by columns
+// followed by the given columns. Metrics the engine cannot compute are
+// computed from these rows; see PercentileCont.
+func (qb *QueryBuilder) BuildStatsSample(spec AggregationSpec, columns []string, limit int) (string, []any, error) {
+	var selectList []string
+	for _, column := range slices.Concat(spec.GroupBy, columns) {
+		if !qb.isValidIdentifier(column) {
+			return "", nil, fmt.Errorf("invalid column: %q", column)
+		}
+		selectList = append(selectList, qb.quoteIdentifier(column))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), qb.quoteIdentifier(spec.Table))
+	where, params, err := qb.buildConditions(spec.Conditions, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return query + fmt.Sprintf(" LIMIT %d", limit), params, nil
+}
+
+// PercentileCont returns the continuous percentile p of sorted values,
+// interpolating between the values around p*(n-1) as percentile_cont does.
+// It reports false for no values.
+func PercentileCont(sorted []float64, p float64) (float64, bool) {
+	if len(sorted) == 0 {
+		return 0, false
+	}
+	offset := p * float64(len(sorted)-1)
+	lower, upper := sorted[int(math.Floor(offset))], sorted[int(math.Ceil(offset))]
+	return lower + (offset-math.Floor(offset))*(upper-lower), true
+}
+
+// formatFraction formats a validated fraction as an SQL numeric literal
+func formatFraction(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
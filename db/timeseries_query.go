@@ -0,0 +1,254 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Time bucket widths of time-series queries
+const (
+	BucketMinute = "minute"
+	BucketHour   = "hour"
+	BucketDay    = "day"
+	BucketWeek   = "week" // ISO weeks, starting on Monday
+	BucketMonth  = "month"
+)
+
+// TimeBuckets lists the supported bucket widths
+var TimeBuckets = []string{BucketMinute, BucketHour, BucketDay, BucketWeek, BucketMonth}
+
+// Kinds of time columns, which decide how values are converted to the
+// requested time zone
+const (
+	TimeKindTimestamp   = "timestamp"   // wall-clock values taken as UTC: DATETIME, timestamp without time zone
+	TimeKindTimestampTZ = "timestamptz" // instants: timestamp with time zone, MySQL TIMESTAMP
+	TimeKindDate        = "date"        // calendar dates, not converted
+	TimeKindEpoch       = "epoch"       // integer seconds since 1970-01-01 UTC
+)
+
+// offsetPattern matches fixed UTC offsets such as +08:00
+var offsetPattern = regexp.MustCompile(`^[+-]\d{2}:\d{2}$`)
+
+// TimeColumnKind returns the time kind of a MySQL or PostgreSQL column type,
+// or an empty string when the column cannot hold times
+func TimeColumnKind(dataType string) string {
+	t := strings.ToLower(dataType)
+	switch {
+	case t == "date":
+		return TimeKindDate
+	case t == "datetime", t == "timestamp without time zone":
+		return TimeKindTimestamp
+	case t == "timestamp", t == "timestamp with time zone", t == "timestamptz":
+		// MySQL TIMESTAMP stores instants like PostgreSQL timestamptz
+		return TimeKindTimestampTZ
+	case ColumnKind(t) == ColumnKindNumeric && strings.Contains(t, "int"):
+		return TimeKindEpoch
+	default:
+		return ""
+	}
+}
+
+// LoadTimezone parses an IANA time zone name or a fixed offset such as +08:00
+func LoadTimezone(name string) (*time.Location, error) {
+	if offsetPattern.MatchString(name) {
+		t, err := time.Parse("-07:00", name)
+		if err != nil {
+			return nil, err
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	return time.LoadLocation(name)
+}
+
+// TimeBoundValue converts a range bound into the parameter value compared
+// with a time column of the given kind; see BuildTimeseriesQuery
+func TimeBoundValue(kind string, t time.Time, loc *time.Location) any {
+	switch kind {
+	case TimeKindTimestampTZ, TimeKindEpoch:
+		return t.Unix()
+	case TimeKindDate:
+		return t.In(loc).Format(time.DateOnly)
+	default:
+		return t.UTC().Format(time.DateTime)
+	}
+}
+
+// TimeseriesSpec describes a time-series query: metrics per time bucket and,
+// with SplitBy, per value of a dimension column
+type TimeseriesSpec struct {
+	Table      string
+	TimeColumn string
+	TimeKind   string // one of the TimeKind constants
+	Bucket     string // one of TimeBuckets
+	Timezone   string // IANA name or fixed offset such as +08:00
+	Metrics    []Metric
+	SplitBy    string
+	Conditions map[string]any // as in AggregationSpec
+	From, To   any            // TimeBoundValue results; nil leaves the range open
+	Limit      int
+}
+
+// BuildTimeseriesQuery builds a parameterized time-series query. Its rows hold
+// the bucket start as local wall-clock time in the time zone (bucket), the
+// split value if any, then the metrics under their aliases, ordered by bucket.
+// From is inclusive and To exclusive.
+func (qb *QueryBuilder) BuildTimeseriesQuery(spec TimeseriesSpec) (string, []any, error) {
+	if !slices.Contains(TimeBuckets, spec.Bucket) {
+		return "", nil, fmt.Errorf("invalid bucket: %s (must be one of %s)", spec.Bucket, strings.Join(TimeBuckets, ", "))
+	}
+	if !qb.isValidIdentifier(spec.TimeColumn) {
+		return "", nil, fmt.Errorf("invalid time column: %q", spec.TimeColumn)
+	}
+	if len(spec.Metrics) == 0 {
+		return "", nil, fmt.Errorf("at least one metric is required")
+	}
+
+	var params []any
+	bucket, params, err := qb.timeBucket(spec, params)
+	if err != nil {
+		return "", nil, err
+	}
+	selectList := []string{bucket + " AS bucket"}
+	outputs := map[string]bool{"bucket": true}
+	groups := "1"
+	if spec.SplitBy != "" {
+		if !qb.isValidIdentifier(spec.SplitBy) {
+			return "", nil, fmt.Errorf("invalid split_by column: %q", spec.SplitBy)
+		}
+		selectList = append(selectList, qb.quoteIdentifier(spec.SplitBy))
+		outputs[spec.SplitBy] = true
+		groups = "1, 2"
+	}
+	for _, metric := range spec.Metrics {
+		m, err := NormalizeMetric(metric)
+		if err != nil {
+			return "", nil, err
+		}
+		if outputs[m.Alias] {
+			return "", nil, fmt.Errorf("duplicate result column %q; give the metric a distinct alias", m.Alias)
+		}
+		if m.Column != "" && !qb.isValidIdentifier(m.Column) {
+			return "", nil, fmt.Errorf("invalid metric column: %q", m.Column)
+		}
+		if _, ok := m.Percentile(); ok && qb.driver != "postgres" {
+			return "", nil, fmt.Errorf("%s per time bucket needs PostgreSQL", m.Fn)
+		}
+		selectList = append(selectList, fmt.Sprintf("%s AS %s", qb.aggregateExpr(m), qb.quoteIdentifier(m.Alias)))
+		outputs[m.Alias] = true
+	}
+
+	col := qb.quoteIdentifier(spec.TimeColumn)
+	where := []string{col + " IS NOT NULL"}
+	for _, bound := range []struct {
+		op    string
+		value any
+	}{{">=", spec.From}, {"<", spec.To}} {
+		if bound.value == nil {
+			continue
+		}
+		params = append(params, bound.value)
+		where = append(where, fmt.Sprintf("%s %s %s", col, bound.op, qb.timeBound(spec.TimeKind, qb.placeholder(len(params)))))
+	}
+	conditions, params, err := qb.buildConditions(spec.Conditions, params)
+	if err != nil {
+		return "", nil, err
+	}
+	where = append(where, conditions...)
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s GROUP BY %s ORDER BY %s",
+		strings.Join(selectList, ", "), qb.quoteIdentifier(spec.Table), strings.Join(where, " AND "), groups, groups)
+	if spec.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", spec.Limit)
+	}
+	return query, params, nil
+}
+
+// timeBucket returns the bucket expression of a time-series query, appending
+// the time zone parameters it uses
+func (qb *QueryBuilder) timeBucket(spec TimeseriesSpec, params []any) (string, []any, error) {
+	col := qb.quoteIdentifier(spec.TimeColumn)
+
+	// UTC as an offset needs no MySQL time zone tables
+	zone := spec.Timezone
+	if zone == "UTC" {
+		zone = "+00:00"
+	}
+
+	// tz adds one use of the time zone parameter
+	tz := func() string {
+		params = append(params, zone)
+		p := qb.placeholder(len(params))
+		if qb.driver == "postgres" && offsetPattern.MatchString(zone) {
+			// Offsets as strings follow the inverted POSIX sign convention
+			p += "::interval"
+		}
+		return p
+	}
+
+	if qb.driver == "postgres" {
+		var local string
+		switch spec.TimeKind {
+		case TimeKindTimestampTZ:
+			local = fmt.Sprintf("%s AT TIME ZONE %s", col, tz())
+		case TimeKindTimestamp:
+			local = fmt.Sprintf("(%s AT TIME ZONE 'UTC') AT TIME ZONE %s", col, tz())
+		case TimeKindDate:
+			local = col + "::timestamp"
+		case TimeKindEpoch:
+			local = fmt.Sprintf("to_timestamp(%s) AT TIME ZONE %s", col, tz())
+		default:
+			return "", nil, fmt.Errorf("unsupported time column kind: %q", spec.TimeKind)
+		}
+		return fmt.Sprintf("date_trunc('%s', %s)", spec.Bucket, local), params, nil
+	}
+
+	// MySQL: convert to local time, then format away the finer fields
+	local := func() string {
+		switch spec.TimeKind {
+		case TimeKindTimestampTZ:
+			return fmt.Sprintf("CONVERT_TZ(%s, @@session.time_zone, %s)", col, tz())
+		case TimeKindTimestamp:
+			return fmt.Sprintf("CONVERT_TZ(%s, '+00:00', %s)", col, tz())
+		case TimeKindEpoch:
+			return fmt.Sprintf("CONVERT_TZ(FROM_UNIXTIME(%s), @@session.time_zone, %s)", col, tz())
+		default:
+			return col
+		}
+	}
+	switch spec.TimeKind {
+	case TimeKindTimestampTZ, TimeKindTimestamp, TimeKindEpoch, TimeKindDate:
+	default:
+		return "", nil, fmt.Errorf("unsupported time column kind: %q", spec.TimeKind)
+	}
+
+	switch spec.Bucket {
+	case BucketMinute:
+		return "DATE_FORMAT(" + local() + ", '%Y-%m-%d %H:%i:00')", params, nil
+	case BucketHour:
+		return "DATE_FORMAT(" + local() + ", '%Y-%m-%d %H:00:00')", params, nil
+	case BucketDay:
+		return "DATE_FORMAT(" + local() + ", '%Y-%m-%d 00:00:00')", params, nil
+	case BucketWeek:
+		v := local()
+		w := local()
+		return "DATE_FORMAT(DATE_SUB(" + v + ", INTERVAL WEEKDAY(" + w + ") DAY), '%Y-%m-%d 00:00:00')", params, nil
+	default:
+		return "DATE_FORMAT(" + local() + ", '%Y-%m-01 00:00:00')", params, nil
+	}
+}
+
+// timeBound returns the expression a time column is compared with for a
+// bound parameter, matching TimeBoundValue
+func (qb *QueryBuilder) timeBound(kind, placeholder string) string {
+	if kind != TimeKindTimestampTZ {
+		return placeholder
+	}
+	if qb.driver == "postgres" {
+		return fmt.Sprintf("to_timestamp(%s)", placeholder)
+	}
+	return fmt.Sprintf("FROM_UNIXTIME(%s)", placeholder)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// startQuerySpan starts a span for a SQL statement.
+// Statements are always parameterized, so the query text never contains values.
+func startQuerySpan(ctx context.Context, system, name, operation, query string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "db."+operation,
+		tracing.AttrDBSystem.String(system),
+		tracing.AttrDBName.String(name),
+		tracing.AttrDBOperation.String(operation),
+		tracing.AttrDBStatement.String(strings.Join(strings.Fields(query), " ")),
+	)
+}
+
+// StartBuildSpan starts a query_builder.<op> span around the construction of
+// a statement by the QueryBuilder. The returned func ends it, recording the
+// built statement, which holds placeholders rather than values, and err.
+func StartBuildSpan(ctx context.Context, op string) func(query string, err error) {
+	_, span := tracing.Start(ctx, "query_builder."+op)
+	return func(query string, err error) {
+		if query != "" {
+			span.SetAttributes(tracing.AttrDBStatement.String(strings.Join(strings.Fields(query), " ")))
+		}
+		tracing.End(span, err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,6 +14,18 @@ import (
 
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+const (
+	// defaultMaxResultRows bounds analytics results when max_result_rows is unset
+	defaultMaxResultRows = 1000
+	// defaultStatsSampleRows bounds the rows fetched for metrics the engine
+	// cannot compute when stats_sample_rows is unset
+	defaultStatsSampleRows = 100000
 )
 
 // AnalyticsHandler provides analytical queries on database tables
@@ -27,6 +41,12 @@ func NewAnalyticsHandler(
 	cfg config.AnalyticsConfig,
 	logger *slog.Logger,
 ) *AnalyticsHandler {
+	if cfg.MaxResultRows <= 0 {
+		cfg.MaxResultRows = defaultMaxResultRows
+	}
+	if cfg.StatsSampleRows <= 0 {
+		cfg.StatsSampleRows = defaultStatsSampleRows
+	}
 	return &AnalyticsHandler{
 		repositories: repos,
 		config:       cfg,
@@ -34,82 +54,186 @@ func NewAnalyticsHandler(
 	}
 }
 
+// AnalyticsResult is the structured result of the analytics tool
+type AnalyticsResult struct {
+	Database     string            `json:"database"`
+	Table        string            `json:"table"`
+	Metrics      []db.Metric       `json:"metrics"`
+	GroupBy      []string          `json:"group_by,omitempty"`
+	Columns      []string          `json:"columns"` // result keys in select order
+	ResultCount  int               `json:"result_count"`
+	Truncated    bool              `json:"truncated,omitempty"` // more groups exist beyond max_result_rows
+	Results      []map[string]any  `json:"results"`
+	Methods      map[string]string `json:"methods"`                 // how each metric alias was computed: native, window, sample, approx or exact
+	SampleRows   int               `json:"sample_rows,omitempty"`   // rows behind the sample metrics
+	SampleCapped bool              `json:"sample_capped,omitempty"` // the sample stopped at stats_sample_rows, so sample metrics are estimates
+	Query        string            `json:"query"`
+}
+
 // HandleAnalytics performs analytical aggregations on table data
 // CRITICAL: Uses parameterized queries to prevent SQL injection
 func (h *AnalyticsHandler) HandleAnalytics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling analytics tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	tableName, err := request.RequireString("table")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
-	column, err := request.RequireString("column")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	// Metrics, or the single column/function pair of older clients
+	var aggregates []db.Metric
+	if err := toolargs.Decode(request, "metrics", &aggregates); err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if column, function := request.GetString("column", ""), request.GetString("function", ""); function != "" {
+		if len(aggregates) > 0 {
+			return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "pass either metrics or column and function, not both")), nil
+		}
+		aggregates = []db.Metric{{Fn: function, Column: column, Alias: "result"}}
+	}
+	if len(aggregates) == 0 {
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "at least one metric is required").
+			WithHint(`Pass metrics, e.g. [{"fn":"SUM","column":"amount","alias":"revenue"}]`)), nil
+	}
+	for i, metric := range aggregates {
+		if aggregates[i], err = db.NormalizeMetric(metric); err != nil {
+			return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "invalid metric").
+				WithHint("fn must be one of: " + strings.Join(db.AggregateFuncs, ", "))), nil
+		}
 	}
 
-	aggFunction, err := request.RequireString("function")
+	groupBy, err := toolargs.StringList(request, "group_by")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	var having []db.HavingFilter
+	if err := toolargs.Decode(request, "having", &having); err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
+	orderBy := request.GetString("order_by", "")
 
-	// Validate aggregate function
-	aggFunction = strings.ToUpper(aggFunction)
-	validFuncs := map[string]bool{
-		"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	// Limit rows in SQL; one extra row tells whether groups were cut off
+	limit := request.GetInt("limit", 0)
+	if limit < 0 {
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "limit must not be negative")), nil
 	}
-	if !validFuncs[aggFunction] {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid aggregate function: %s. Must be one of: COUNT, SUM, AVG, MIN, MAX", aggFunction)), nil
+	if limit == 0 || limit > h.config.MaxResultRows {
+		limit = h.config.MaxResultRows
+	}
+
+	// Validate identifiers before they reach the query builder
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	for _, metric := range aggregates {
+		if metric.Column != "" {
+			if err := db.ValidateIdentifier("column", metric.Column); err != nil {
+				return toolerrors.Result(err), nil
+			}
+		}
+	}
+	for _, column := range groupBy {
+		if err := db.ValidateIdentifier("group_by column", column); err != nil {
+			return toolerrors.Result(err), nil
+		}
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(formatDatabaseNotFoundError(dbName, h.repositories)), nil
+		return toolerrors.Result(databaseNotFoundError(dbName, h.repositories)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
 	}
 
-	// Optional: conditions and group_by
-	var conditions map[string]any
-	condStr := request.GetString("conditions", "")
-	if condStr != "" {
-		if err := json.Unmarshal([]byte(condStr), &conditions); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid conditions JSON: %v", err)), nil
+	// Optional: conditions
+	// Accepts a JSON object or, for older clients, a JSON-encoded string
+	conditions, err := toolargs.Object(request, "conditions")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	for key := range conditions {
+		if err := db.ValidateIdentifier("column", key); err != nil {
+			return toolerrors.Result(err), nil
 		}
 	}
 
-	groupBy := request.GetString("group_by", "")
+	// Execute queries with timeout
+	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.ExecutionTimeout)*time.Second)
+	defer cancel()
 
 	// Build aggregation query using QueryBuilder (always parameterized)
 	qb := db.NewQueryBuilder(repo.GetDriver())
-	query, params, err := qb.BuildAggregation(tableName, column, aggFunction, conditions, groupBy)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to build query: %v", err)), nil
+	spec := db.AggregationSpec{
+		Table:      db.FullTableName(repo, tableName),
+		Metrics:    aggregates,
+		GroupBy:    groupBy,
+		Conditions: conditions,
+		Having:     having,
+		OrderBy:    orderBy,
+		Limit:      limit + 1,
+	}
+	methods := make(map[string]string, len(aggregates))
+	for _, metric := range aggregates {
+		methods[metric.Alias] = qb.MetricMethod(metric)
 	}
 
-	// Execute query with timeout
-	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.ExecutionTimeout)*time.Second)
-	defer cancel()
+	// Without group-by, approximate distinct counts are estimated here from a
+	// sketch; grouped ones are counted exactly
+	var approx []db.Metric
+	if len(groupBy) == 0 {
+		spec, approx = approxSpec(spec)
+		for _, metric := range approx {
+			methods[metric.Alias] = db.MethodApprox
+		}
+	}
+
+	// Without window functions, percentiles are computed here from a sample
+	var sampled []db.Metric
+	if slices.ContainsFunc(aggregates, isPercentile) && repo.GetDriver() != "postgres" {
+		windows, err := db.SupportsWindowFunctions(queryCtx, repo)
+		if err != nil {
+			return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+		}
+		if !windows {
+			if spec, sampled, err = sampleSpec(spec); err != nil {
+				return toolerrors.Result(err), nil
+			}
+			for _, metric := range sampled {
+				methods[metric.Alias] = db.MethodSample
+			}
+		}
+	}
+
+	built := db.StartBuildSpan(ctx, "aggregation")
+	query, params, err := qb.BuildAggregationQuery(spec)
+	built(query, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
 
 	// CRITICAL: Execute parameterized query to prevent SQL injection
 	rows, err := repo.Query(queryCtx, query, params...)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Analytics query failed", "error", err, "query", query)
-		return mcp.NewToolResultError(fmt.Sprintf("query execution failed: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
 	}
 	defer rows.Close()
 
 	// Parse results
-	var results []map[string]any
+	_, decodeSpan := tracing.Start(ctx, "db.decode_rows")
+	results := []map[string]any{}
 	columns, _ := rows.Columns()
 
-	for rows.Next() && len(results) < h.config.MaxResultRows {
+	for rows.Next() {
 		values := make([]any, len(columns))
 		valuePtrs := make([]any, len(columns))
 		for i := range values {
@@ -132,23 +256,264 @@ func (h *AnalyticsHandler) HandleAnalytics(ctx context.Context, request mcp.Call
 		}
 		results = append(results, rowMap)
 	}
+	if err := rows.Err(); err != nil {
+		decodeSpan.End()
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	truncated := len(results) > limit
+	if truncated {
+		results = results[:limit]
+	}
+	decodeSpan.SetAttributes(tracing.AttrDBRowCount.Int(len(results)))
+	decodeSpan.End()
+	metrics.RecordRows(ctx, len(results))
+
+	var sampleRows int
+	var sampleCapped bool
+	if len(sampled) > 0 {
+		if sampleRows, sampleCapped, err = h.samplePercentiles(queryCtx, repo, qb, spec, sampled, results); err != nil {
+			h.logger.ErrorContext(ctx, "Analytics sample query failed", "error", err)
+			return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+		}
+	}
+	if len(approx) > 0 {
+		if err := approxDistinct(queryCtx, repo, qb, spec, approx, results); err != nil {
+			h.logger.ErrorContext(ctx, "Analytics distinct sketch query failed", "error", err)
+			return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+		}
+	}
+	if len(sampled) > 0 || len(approx) > 0 {
+		columns = slices.Clone(groupBy)
+		for _, metric := range aggregates {
+			columns = append(columns, metric.Alias)
+		}
+	}
 
 	// Build response
-	response := map[string]any{
-		"database":     dbName,
-		"table":        tableName,
-		"column":       column,
-		"function":     aggFunction,
-		"group_by":     groupBy,
-		"result_count": len(results),
-		"results":      results,
-		"query":        query,
+	response := AnalyticsResult{
+		Database:     dbName,
+		Table:        tableName,
+		Metrics:      aggregates,
+		GroupBy:      groupBy,
+		Columns:      columns,
+		ResultCount:  len(results),
+		Truncated:    truncated,
+		Results:      results,
+		Methods:      methods,
+		SampleRows:   sampleRows,
+		SampleCapped: sampleCapped,
+		Query:        query,
 	}
 
 	resultJSON, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal analytics response", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+	}
+	return mcp.NewToolResultStructured(response, string(resultJSON)), nil
+}
+
+// sampleRowsMetric counts the rows of each group when all metrics of an
+// aggregation are computed from a sample or sketch; it is removed from the
+// results
+const sampleRowsMetric = "sample_group_rows"
+
+// isPercentile reports whether a metric is MEDIAN or PERCENTILE
+func isPercentile(m db.Metric) bool {
+	_, ok := m.Percentile()
+	return ok
+}
+
+// sampleSpec splits the percentiles off an aggregation for computation from
+// a sample. The groups still come from the aggregation, which counts them if
+// no other metric is left. Percentiles cannot be filtered or sorted on then.
+func sampleSpec(spec db.AggregationSpec) (db.AggregationSpec, []db.Metric, error) {
+	var native, sampled []db.Metric
+	for _, metric := range spec.Metrics {
+		if isPercentile(metric) {
+			sampled = append(sampled, metric)
+		} else {
+			native = append(native, metric)
+		}
+	}
+
+	orderKeys := orderByKeys(spec.OrderBy)
+	for _, metric := range sampled {
+		used := orderKeys[metric.Alias] || slices.ContainsFunc(spec.Having, func(f db.HavingFilter) bool {
+			return f.Metric == metric.Alias
+		})
+		if used {
+			return spec, nil, toolerrors.Newf(toolerrors.CodeUnsupported,
+				"percentile %q cannot be used in having or order_by on this database", metric.Alias).
+				WithHint("Percentiles are computed from a sample without window functions (MySQL before 8.0); filter and sort the results instead")
+		}
+	}
+
+	if len(native) == 0 {
+		native = []db.Metric{{Fn: "COUNT", Alias: sampleRowsMetric}}
+	}
+	spec.Metrics = native
+	return spec, sampled, nil
+}
+
+// orderByKeys returns the result keys an order_by argument sorts on
+func orderByKeys(orderBy string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, term := range strings.Split(orderBy, ",") {
+		if fields := strings.Fields(term); len(fields) > 0 {
+			keys[fields[0]] = true
+		}
+	}
+	return keys
+}
+
+// approxSpec splits the approximate distinct counts off an ungrouped
+// aggregation for estimation from a sketch. Those used in having or order_by
+// stay in the aggregation and are counted exactly.
+func approxSpec(spec db.AggregationSpec) (db.AggregationSpec, []db.Metric) {
+	var native, approx []db.Metric
+	orderKeys := orderByKeys(spec.OrderBy)
+	for _, metric := range spec.Metrics {
+		used := orderKeys[metric.Alias] || slices.ContainsFunc(spec.Having, func(f db.HavingFilter) bool {
+			return f.Metric == metric.Alias
+		})
+		if metric.Fn == "APPROX_COUNT_DISTINCT" && metric.Column != "" && !used {
+			approx = append(approx, metric)
+		} else {
+			native = append(native, metric)
+		}
+	}
+	if len(native) == 0 {
+		native = []db.Metric{{Fn: "COUNT", Alias: sampleRowsMetric}}
+	}
+	spec.Metrics = native
+	return spec, approx
+}
+
+// approxDistinct estimates the approximate distinct counts of an ungrouped
+// aggregation, whose single result row they are added to, from a HyperLogLog
+// sketch of the rows matching its conditions
+func approxDistinct(
+	ctx context.Context,
+	repo db.Repository,
+	qb *db.QueryBuilder,
+	spec db.AggregationSpec,
+	approx []db.Metric,
+	results []map[string]any,
+) error {
+	for _, result := range results {
+		for _, metric := range approx {
+			built := db.StartBuildSpan(ctx, "distinct_sketch")
+			query, params, err := qb.BuildDistinctSketchWhere(spec.Table, metric.Column, spec.Conditions)
+			built(query, err)
+			if err != nil {
+				return err
+			}
+			registers, err := distinctSketch(ctx, repo, query, params...)
+			if err != nil {
+				return err
+			}
+			result[metric.Alias] = db.EstimateDistinct(registers)
+		}
+		delete(result, sampleRowsMetric)
+	}
+	return nil
+}
+
+// samplePercentiles computes percentile metrics for the result groups from one
+// sample of at most stats_sample_rows rows of the table, holding the group-by
+// columns, so that no group costs a query of its own. It returns the rows read
+// and whether the sample stopped at the limit, which makes the percentiles
+// estimates and may leave small groups without values.
+func (h *AnalyticsHandler) samplePercentiles(
+	ctx context.Context,
+	repo db.Repository,
+	qb *db.QueryBuilder,
+	spec db.AggregationSpec,
+	sampled []db.Metric,
+	results []map[string]any,
+) (int, bool, error) {
+	var columns []string
+	for _, metric := range sampled {
+		if !slices.Contains(columns, metric.Column) {
+			columns = append(columns, metric.Column)
+		}
+	}
+	built := db.StartBuildSpan(ctx, "stats_sample")
+	query, params, err := qb.BuildStatsSample(spec, columns, h.config.StatsSampleRows)
+	built(query, err)
+	if err != nil {
+		return 0, false, err
+	}
+	rows, err := repo.Query(ctx, query, params...)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+
+	// Sorted values by group key and column
+	values := make(map[string]map[string][]float64)
+	n := 0
+	width := len(spec.GroupBy) + len(columns)
+	for rows.Next() {
+		row := make([]any, width)
+		ptrs := make([]any, width)
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, false, err
+		}
+		n++
+		key := groupKey(row[:len(spec.GroupBy)])
+		if values[key] == nil {
+			values[key] = make(map[string][]float64)
+		}
+		for i, column := range columns {
+			if v, ok := profileFloat(row[len(spec.GroupBy)+i]); ok {
+				values[key][column] = append(values[key][column], v)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, false, err
+	}
+	for _, group := range values {
+		for _, vs := range group {
+			sort.Float64s(vs)
+		}
+	}
+
+	for _, result := range results {
+		group := make([]any, len(spec.GroupBy))
+		for i, column := range spec.GroupBy {
+			group[i] = result[column]
+		}
+		key := groupKey(group)
+		for _, metric := range sampled {
+			p, _ := metric.Percentile()
+			result[metric.Alias] = nil
+			if v, ok := db.PercentileCont(values[key][metric.Column], p); ok {
+				result[metric.Alias] = v
+			}
+		}
+		delete(result, sampleRowsMetric)
+	}
+	return n, n >= h.config.StatsSampleRows, nil
+}
+
+// groupKey returns a map key for the group-by values of a row
+func groupKey(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if v == nil {
+			parts[i] = "\x01NULL"
+			continue
+		}
+		parts[i] = fmt.Sprint(v)
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return strings.Join(parts, "\x00")
 }
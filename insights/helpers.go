@@ -1,11 +1,60 @@
 package insights
 
 import (
+	"context"
+
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
 )
 
-// formatDatabaseNotFoundError is a package-level wrapper for db.FormatDatabaseNotFoundError
+// databaseNotFoundError is a package-level wrapper for db.DatabaseNotFoundError
 // This provides a convenient local alias while using the shared implementation
-func formatDatabaseNotFoundError(dbName string, repositories map[string]db.Repository) string {
-	return db.FormatDatabaseNotFoundError(dbName, repositories)
+func databaseNotFoundError(dbName string, repositories map[string]db.Repository) *toolerrors.ToolError {
+	return db.DatabaseNotFoundError(dbName, repositories)
+}
+
+// unknownColumnError creates an UNKNOWN_COLUMN error suggesting the nearest
+// columns of the table
+func unknownColumnError(info *db.TableInfo, tableName, column string) *toolerrors.ToolError {
+	available := make([]string, len(info.Columns))
+	for i, col := range info.Columns {
+		available[i] = col.Name
+	}
+	return toolerrors.Newf(toolerrors.CodeUnknownColumn, "column '%s' not found in table '%s'", column, tableName).
+		WithTarget(column).
+		WithSuggestions(available)
+}
+
+// timeColumnKind looks up the time kind of a column
+func timeColumnKind(ctx context.Context, repo db.Repository, tableName, column string) (string, error) {
+	var info *db.TableInfo
+	var err error
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	case *db.PostgresRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	default:
+		return "", toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+	if err != nil {
+		return "", db.ClassifyError(ctx, repo, tableName, err)
+	}
+	if len(info.Columns) == 0 {
+		return "", db.TableNotFoundError(ctx, repo, tableName)
+	}
+
+	for _, col := range info.Columns {
+		if col.Name != column {
+			continue
+		}
+		kind := db.TimeColumnKind(col.DataType)
+		if kind == "" {
+			return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "column '%s' has type %s, which does not hold times", column, col.DataType).
+				WithTarget(column).
+				WithHint("Pass a date, timestamp or integer epoch column as time_column.")
+		}
+		return kind, nil
+	}
+	return "", unknownColumnError(info, tableName, column)
 }
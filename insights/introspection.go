@@ -5,6 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -12,6 +18,14 @@ import (
 	"github.com/SkillingX/mcp-localbridge/cache"
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/progress"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+const (
+	defaultIntrospectionWorkers  = 4
+	defaultIntrospectionPageSize = 100
+	maxIntrospectionPageSize     = 1000
 )
 
 // IntrospectionHandler provides database schema introspection capabilities
@@ -19,6 +33,7 @@ type IntrospectionHandler struct {
 	repositories map[string]db.Repository
 	redisClients map[string]*cache.RedisClient
 	config       config.IntrospectionConfig
+	listeners    []RefreshListener
 	logger       *slog.Logger
 }
 
@@ -29,6 +44,12 @@ func NewIntrospectionHandler(
 	cfg config.IntrospectionConfig,
 	logger *slog.Logger,
 ) *IntrospectionHandler {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultIntrospectionWorkers
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = defaultIntrospectionPageSize
+	}
 	return &IntrospectionHandler{
 		repositories: repos,
 		redisClients: redisClients,
@@ -37,113 +58,543 @@ func NewIntrospectionHandler(
 	}
 }
 
+// IntrospectionResult is the structured result of the introspection tool
+type IntrospectionResult struct {
+	Database      string            `json:"database"`
+	TableCount    int               `json:"table_count"` // tables matching the filter, across all pages
+	Tables        []db.TableInfo    `json:"tables"`
+	RebuiltTables int               `json:"rebuilt_tables"`          // tables re-read from the database by this call
+	FailedTables  []TableError      `json:"failed_tables,omitempty"` // tables left out because they could not be read
+	Views         []db.ViewInfo     `json:"views,omitempty"`
+	Routines      []db.RoutineInfo  `json:"routines,omitempty"`
+	Triggers      []db.TriggerInfo  `json:"triggers,omitempty"`
+	Sequences     []db.SequenceInfo `json:"sequences,omitempty"`
+	Page          int               `json:"page,omitempty"`
+	PageSize      int               `json:"page_size,omitempty"`
+	TotalPages    int               `json:"total_pages,omitempty"`
+	CachedAt      string            `json:"cached_at"`
+	CacheTTL      int               `json:"cache_ttl"`
+}
+
+// TableError names a table that could not be introspected
+type TableError struct {
+	Table string `json:"table"`
+	Error string `json:"error"`
+}
+
+// tableEntry is the cached schema of one table with the fingerprint it was built at
+type tableEntry struct {
+	Fingerprint string       `json:"fingerprint"`
+	Table       db.TableInfo `json:"table"`
+}
+
+// introspectionManifest records the table list of the last introspection so
+// cached reads can be served without querying the database
+type introspectionManifest struct {
+	Tables   []string          `json:"tables"`
+	Objects  *db.SchemaObjects `json:"objects,omitempty"`
+	CachedAt string            `json:"cached_at"`
+}
+
+// RefreshListener is called after the cached schema of a database is rebuilt
+type RefreshListener func(ctx context.Context, dbName string)
+
+// OnRefresh registers a listener that is notified whenever a database schema
+// is re-introspected on request or written back to the cache
+func (h *IntrospectionHandler) OnRefresh(listener RefreshListener) {
+	h.listeners = append(h.listeners, listener)
+}
+
 // HandleIntrospection performs database schema introspection
 func (h *IntrospectionHandler) HandleIntrospection(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling introspection tool request")
 
-	// Extract required parameter using mcp-go v0.43.2 best practices
+	// Extract required parameter
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	// Check if refresh is requested
+	refresh := request.GetBool("refresh", false)
+
+	var patterns []string
+	for _, pattern := range strings.Split(request.GetString("tables", ""), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	page := request.GetInt("page", 1)
+	if page < 1 {
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "page must be at least 1")), nil
+	}
+	pageSize := request.GetInt("page_size", h.config.PageSize)
+	if pageSize < 1 || pageSize > maxIntrospectionPageSize {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"page_size must be between 1 and %d", maxIntrospectionPageSize)), nil
+	}
+
+	result, err := h.IntrospectTables(ctx, dbName, request.GetString("schema", ""), patterns, refresh)
+	if err != nil {
+		return toolerrors.Result(err), nil
 	}
+	paged := paginate(*result, page, pageSize)
 
+	resultJSON, err := json.MarshalIndent(paged, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal introspection response", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+	}
+	return mcp.NewToolResultStructured(paged, string(resultJSON)), nil
+}
+
+// paginate returns one page of the tables of an introspection result
+func paginate(result IntrospectionResult, page, pageSize int) IntrospectionResult {
+	result.Page = page
+	result.PageSize = pageSize
+	result.TotalPages = (len(result.Tables) + pageSize - 1) / pageSize
+
+	start := min((page-1)*pageSize, len(result.Tables))
+	end := min(start+pageSize, len(result.Tables))
+	result.Tables = result.Tables[start:end]
+	return result
+}
+
+// Introspect returns the schema of every table in a database. Errors are
+// *toolerrors.ToolError values.
+func (h *IntrospectionHandler) Introspect(ctx context.Context, dbName string, refresh bool) (*IntrospectionResult, error) {
+	return h.IntrospectTables(ctx, dbName, "", nil, refresh)
+}
+
+// IntrospectTables returns the schema of the tables matching any of the glob
+// patterns, or of every table when there are none. A non-empty schema
+// restricts both to that schema. Each table is cached with
+// a change fingerprint: reads are served from the cache until it expires, and
+// a refresh re-reads only the tables whose fingerprint changed.
+// Errors are *toolerrors.ToolError values.
+func (h *IntrospectionHandler) IntrospectTables(ctx context.Context, dbName, schema string, patterns []string, refresh bool) (*IntrospectionResult, error) {
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(formatDatabaseNotFoundError(dbName, h.repositories)), nil
+		return nil, databaseNotFoundError(dbName, h.repositories)
 	}
 
-	// Check if refresh is requested
-	refresh := request.GetBool("refresh", false)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid table pattern %q", pattern).
+				WithHint("Use * and ? wildcards, e.g. orders_*, dim_? or sales.*")
+		}
+	}
+	if schema != "" {
+		var err error
+		if patterns, err = schemaPatterns(repo, schema, patterns); err != nil {
+			return nil, err
+		}
+	}
 
-	// Try to get from cache first (if not refresh and Redis cache is enabled)
-	cacheKey := fmt.Sprintf("introspection:%s", dbName)
-	if !refresh && h.config.UseRedisCache && len(h.redisClients) > 0 {
-		// Get first available Redis client
-		for _, redisClient := range h.redisClients {
-			cached, err := redisClient.Get(ctx, cacheKey)
-			if err == nil && cached != "" {
-				h.logger.InfoContext(ctx, "Returning introspection from cache", "database", dbName)
-				return mcp.NewToolResultText(cached), nil
-			}
-			break
+	redisClient := h.cacheClient()
+	manifestKey := fmt.Sprintf("introspection:%s:manifest", dbName)
+	tablesKey := fmt.Sprintf("introspection:%s:tables", dbName)
+	entries := h.cachedEntries(ctx, redisClient, tablesKey)
+
+	var manifest *introspectionManifest
+	if redisClient != nil {
+		manifest = h.cachedManifest(ctx, redisClient, manifestKey)
+	}
+
+	// Serve from cache when the last table list and every matching table are cached
+	if !refresh && manifest != nil {
+		if result, ok := h.fromCache(dbName, matchTables(manifest.Tables, patterns), entries, manifest.CachedAt); ok {
+			h.logger.InfoContext(ctx, "Returning introspection from cache", "database", dbName)
+			setSchemaObjects(result, manifest.Objects, patterns)
+			return result, nil
 		}
 	}
 
 	// Get table list
 	var tables []string
+	var fingerprints map[string]string
+	var schemaObjects *db.SchemaObjects
+	var err, objectsErr error
 	switch r := repo.(type) {
 	case *db.MySQLRepository:
 		tables, err = r.GetTableList(ctx)
+		if err == nil {
+			fingerprints, err = r.GetTableFingerprints(ctx)
+		}
+		if err == nil {
+			schemaObjects, objectsErr = r.GetSchemaObjects(ctx)
+		}
 	case *db.PostgresRepository:
 		tables, err = r.GetTableList(ctx)
+		if err == nil {
+			fingerprints, err = r.GetTableFingerprints(ctx)
+		}
+		if err == nil {
+			schemaObjects, objectsErr = r.GetSchemaObjects(ctx)
+		}
 	default:
-		return mcp.NewToolResultError("unsupported repository type"), nil
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
 	}
 
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to get table list", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get table list: %v", err)), nil
+		return nil, db.ClassifyError(ctx, repo, "", err)
+	}
+	// Views, routines and triggers are extra detail; tables are still useful without them
+	if objectsErr != nil {
+		h.logger.WarnContext(ctx, "Failed to get schema objects", "database", dbName, "error", objectsErr)
 	}
 
-	// Get detailed info for each table
-	var tableInfos []db.TableInfo
-	for _, tableName := range tables {
-		var info *db.TableInfo
-		switch r := repo.(type) {
-		case *db.MySQLRepository:
-			info, err = r.GetTableInfo(ctx, tableName)
-		case *db.PostgresRepository:
-			info, err = r.GetTableInfo(ctx, tableName)
+	// Rebuild tables that are new or whose fingerprint changed
+	matched := matchTables(tables, patterns)
+	var stale []string
+	for _, tableName := range matched {
+		entry, ok := entries[tableName]
+		if !ok || fingerprints[tableName] == "" || entry.Fingerprint != fingerprints[tableName] {
+			stale = append(stale, tableName)
 		}
+	}
+	rebuilt, failed := h.collectTables(ctx, repo, stale)
 
-		if err != nil {
-			h.logger.WarnContext(ctx, "Failed to get table info", "table", tableName, "error", err)
-			continue
+	// Stop when the caller cancels; the running statements are killed by the repository
+	if err := ctx.Err(); err != nil {
+		return nil, toolerrors.FromDBError(err)
+	}
+
+	tableInfos := []db.TableInfo{}
+	for _, tableName := range matched {
+		if info, ok := rebuilt[tableName]; ok {
+			tableInfos = append(tableInfos, info)
+		} else if entry, ok := entries[tableName]; ok && !slices.Contains(stale, tableName) {
+			tableInfos = append(tableInfos, entry.Table)
+		}
+	}
+
+	// Build result
+	result := &IntrospectionResult{
+		Database:      dbName,
+		TableCount:    len(tableInfos),
+		Tables:        tableInfos,
+		RebuiltTables: len(rebuilt),
+		FailedTables:  failed,
+		CachedAt:      time.Now().UTC().Format(time.RFC3339),
+		CacheTTL:      h.config.CacheTTL,
+	}
+	setSchemaObjects(result, schemaObjects, patterns)
+
+	// Cache the rebuilt tables and drop the ones that no longer exist
+	changed := len(rebuilt) > 0
+	cached := false
+	if redisClient != nil {
+		var removed []string
+		for tableName := range entries {
+			if !slices.Contains(tables, tableName) {
+				removed = append(removed, tableName)
+			}
+		}
+		changed = changed || len(removed) > 0 || manifest == nil ||
+			!slices.Equal(manifest.Tables, tables) || !reflect.DeepEqual(manifest.Objects, schemaObjects)
+
+		updated := introspectionManifest{Tables: tables, Objects: schemaObjects, CachedAt: result.CachedAt}
+		if err := h.storeEntries(ctx, redisClient, tablesKey, rebuilt, fingerprints, removed); err != nil {
+			h.logger.WarnContext(ctx, "Failed to cache introspection result", "error", err)
+		} else if err := h.storeManifest(ctx, redisClient, manifestKey, updated); err != nil {
+			h.logger.WarnContext(ctx, "Failed to cache introspection result", "error", err)
+		} else {
+			cached = true
 		}
+	}
+
+	// Without a cache every read rebuilds the schema, so only an explicit
+	// refresh counts as a change worth announcing
+	if refresh || (cached && changed) {
+		for _, listener := range h.listeners {
+			listener(ctx, dbName)
+		}
+	}
+
+	return result, nil
+}
+
+// collectTables reads the schema of the given tables with a bounded pool of
+// workers. Tables that fail are left out and returned with their errors, in
+// table order.
+func (h *IntrospectionHandler) collectTables(ctx context.Context, repo db.Repository, tables []string) (map[string]db.TableInfo, []TableError) {
+	infos := make(map[string]db.TableInfo, len(tables))
+	if len(tables) == 0 {
+		return infos, nil
+	}
+	var failed []TableError
+
+	var mu sync.Mutex
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	jobs := make(chan string)
 
-		// Get foreign keys
-		var fks []db.ForeignKeyInfo
-		switch r := repo.(type) {
-		case *db.MySQLRepository:
+	for range min(h.config.Workers, len(tables)) {
+		wg.Go(func() {
+			for tableName := range jobs {
+				info, err := h.tableInfo(ctx, repo, tableName)
+				progress.Report(ctx, int(done.Add(1)), len(tables), fmt.Sprintf("Introspected table %s", tableName))
+				if err != nil {
+					h.logger.WarnContext(ctx, "Failed to get table info", "table", tableName, "error", err)
+					mu.Lock()
+					failed = append(failed, TableError{Table: tableName, Error: err.Error()})
+					mu.Unlock()
+					continue
+				}
+				mu.Lock()
+				infos[tableName] = *info
+				mu.Unlock()
+			}
+		})
+	}
+
+send:
+	for _, tableName := range tables {
+		select {
+		case jobs <- tableName:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	slices.SortFunc(failed, func(a, b TableError) int {
+		return strings.Compare(a.Table, b.Table)
+	})
+	return infos, failed
+}
+
+// tableInfo reads the columns and foreign keys of one table
+func (h *IntrospectionHandler) tableInfo(ctx context.Context, repo db.Repository, tableName string) (*db.TableInfo, error) {
+	var info *db.TableInfo
+	var err error
+	var fks []db.ForeignKeyInfo
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		if info, err = r.GetTableInfo(ctx, tableName); err == nil {
 			fks, _ = r.GetForeignKeys(ctx, tableName)
-		case *db.PostgresRepository:
+		}
+	case *db.PostgresRepository:
+		if info, err = r.GetTableInfo(ctx, tableName); err == nil {
 			fks, _ = r.GetForeignKeys(ctx, tableName)
 		}
+	default:
+		return nil, fmt.Errorf("unsupported repository type")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Add relationship info to table metadata
+	info.ForeignKeys = fks
+	if len(fks) > 0 {
+		info.Description = fmt.Sprintf("Has %d foreign key(s)", len(fks))
+	}
+	return info, nil
+}
+
+// cacheClient returns the Redis client used for the introspection cache, or
+// nil when caching is disabled
+func (h *IntrospectionHandler) cacheClient() *cache.RedisClient {
+	if !h.config.UseRedisCache {
+		return nil
+	}
+	// Get first available Redis client
+	for _, redisClient := range h.redisClients {
+		return redisClient
+	}
+	return nil
+}
 
-		// Add relationship info to table metadata
-		if len(fks) > 0 {
-			info.Description = fmt.Sprintf("Has %d foreign key(s)", len(fks))
+// cachedManifest returns the cached table list, or nil when it is missing
+func (h *IntrospectionHandler) cachedManifest(ctx context.Context, redisClient *cache.RedisClient, key string) *introspectionManifest {
+	cached, err := redisClient.Get(ctx, key)
+	if err != nil || cached == "" {
+		return nil
+	}
+	var manifest introspectionManifest
+	if err := json.Unmarshal([]byte(cached), &manifest); err != nil {
+		h.logger.WarnContext(ctx, "Ignoring unreadable introspection cache entry", "key", key)
+		return nil
+	}
+	return &manifest
+}
+
+// cachedEntries returns the cached tables by name
+func (h *IntrospectionHandler) cachedEntries(ctx context.Context, redisClient *cache.RedisClient, key string) map[string]tableEntry {
+	entries := map[string]tableEntry{}
+	if redisClient == nil {
+		return entries
+	}
+	fields, err := redisClient.HGetAll(ctx, key)
+	if err != nil {
+		return entries
+	}
+	for tableName, value := range fields {
+		var entry tableEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			h.logger.WarnContext(ctx, "Ignoring unreadable introspection cache entry", "key", key, "table", tableName)
+			continue
 		}
+		entries[tableName] = entry
+	}
+	return entries
+}
 
-		tableInfos = append(tableInfos, *info)
+// fromCache assembles a result from cached entries, reporting false when any
+// of the tables is missing
+func (h *IntrospectionHandler) fromCache(dbName string, tables []string, entries map[string]tableEntry, cachedAt string) (*IntrospectionResult, bool) {
+	tableInfos := make([]db.TableInfo, 0, len(tables))
+	for _, tableName := range tables {
+		entry, ok := entries[tableName]
+		if !ok {
+			return nil, false
+		}
+		tableInfos = append(tableInfos, entry.Table)
 	}
+	return &IntrospectionResult{
+		Database:   dbName,
+		TableCount: len(tableInfos),
+		Tables:     tableInfos,
+		CachedAt:   cachedAt,
+		CacheTTL:   h.config.CacheTTL,
+	}, true
+}
 
-	// Build result
-	result := map[string]any{
-		"database":    dbName,
-		"table_count": len(tableInfos),
-		"tables":      tableInfos,
-		"cached_at":   time.Now().UTC().Format(time.RFC3339),
-		"cache_ttl":   h.config.CacheTTL,
+// storeEntries writes rebuilt tables to the cache hash and removes dropped ones
+func (h *IntrospectionHandler) storeEntries(
+	ctx context.Context,
+	redisClient *cache.RedisClient,
+	key string,
+	rebuilt map[string]db.TableInfo,
+	fingerprints map[string]string,
+	removed []string,
+) error {
+	if len(removed) > 0 {
+		if err := redisClient.HDel(ctx, key, removed...); err != nil {
+			return err
+		}
+	}
+	if len(rebuilt) > 0 {
+		if err := h.storeRebuilt(ctx, redisClient, key, rebuilt, fingerprints); err != nil {
+			return err
+		}
+	}
+	// Keep the entries alive as long as the manifest that points at them
+	return redisClient.Expire(ctx, key, time.Duration(h.config.CacheTTL)*time.Second)
+}
+
+// storeRebuilt writes rebuilt tables with their fingerprints to the cache hash
+func (h *IntrospectionHandler) storeRebuilt(
+	ctx context.Context,
+	redisClient *cache.RedisClient,
+	key string,
+	rebuilt map[string]db.TableInfo,
+	fingerprints map[string]string,
+) error {
+	values := make([]any, 0, 2*len(rebuilt))
+	for tableName, info := range rebuilt {
+		entryJSON, err := json.Marshal(tableEntry{Fingerprint: fingerprints[tableName], Table: info})
+		if err != nil {
+			return err
+		}
+		values = append(values, tableName, string(entryJSON))
 	}
+	return redisClient.HSet(ctx, key, values...)
+}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+// storeManifest writes the table list of this introspection to the cache
+func (h *IntrospectionHandler) storeManifest(ctx context.Context, redisClient *cache.RedisClient, key string, manifest introspectionManifest) error {
+	manifestJSON, err := json.Marshal(manifest)
 	if err != nil {
-		h.logger.ErrorContext(ctx, "Failed to marshal introspection response", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+		return err
 	}
+	return redisClient.Set(ctx, key, string(manifestJSON), time.Duration(h.config.CacheTTL)*time.Second)
+}
 
-	// Cache the result if Redis cache is enabled
-	if h.config.UseRedisCache && len(h.redisClients) > 0 {
-		for _, redisClient := range h.redisClients {
-			ttl := time.Duration(h.config.CacheTTL) * time.Second
-			if err := redisClient.Set(ctx, cacheKey, string(resultJSON), ttl); err != nil {
-				h.logger.WarnContext(ctx, "Failed to cache introspection result", "error", err)
-			}
-			break
+// setSchemaObjects adds the views, routines and sequences whose names match
+// the patterns, and the triggers of matching tables, to a result
+func setSchemaObjects(result *IntrospectionResult, objects *db.SchemaObjects, patterns []string) {
+	if objects == nil {
+		return
+	}
+	for _, view := range objects.Views {
+		if matchesAny(view.Name, patterns) {
+			result.Views = append(result.Views, view)
+		}
+	}
+	for _, routine := range objects.Routines {
+		if matchesAny(routine.Name, patterns) {
+			result.Routines = append(result.Routines, routine)
+		}
+	}
+	for _, trigger := range objects.Triggers {
+		if matchesAny(trigger.Table, patterns) {
+			result.Triggers = append(result.Triggers, trigger)
+		}
+	}
+	for _, sequence := range objects.Sequences {
+		if matchesAny(sequence.Name, patterns) {
+			result.Sequences = append(result.Sequences, sequence)
 		}
 	}
+}
+
+// schemaPatterns qualifies table patterns with schema so they only match
+// tables of that schema
+func schemaPatterns(repo db.Repository, schema string, patterns []string) ([]string, error) {
+	if err := db.ResolveSchema(repo, schema); err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return []string{db.QualifyTableName(repo, schema, "*")}, nil
+	}
+	qualified := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if strings.Contains(pattern, ".") {
+			return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "table pattern %q is already qualified; omit schema or pass bare patterns", pattern).
+				WithTarget(pattern)
+		}
+		qualified[i] = db.QualifyTableName(repo, schema, pattern)
+	}
+	return qualified, nil
+}
+
+// matchesAny reports whether name matches one of the glob patterns, or
+// whether there are no patterns. A pattern only matches names with as many
+// dots, so orders_* stays in the default schema and sales.* in sales.
+// Patterns must already be valid.
+func matchesAny(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if strings.Count(pattern, ".") != strings.Count(name, ".") {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+// matchTables returns the tables matching any of the glob patterns, or all
+// tables when there are none. Patterns must already be valid.
+func matchTables(tables, patterns []string) []string {
+	if len(patterns) == 0 {
+		return tables
+	}
+	var matched []string
+	for _, tableName := range tables {
+		if matchesAny(tableName, patterns) {
+			matched = append(matched, tableName)
+		}
+	}
+	return matched
 }
@@ -10,48 +10,80 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
 )
 
 // MetadataHandler retrieves database metadata (table/column comments, etc.)
 type MetadataHandler struct {
 	repositories map[string]db.Repository
+	summarizer   *Summarizer
 	logger       *slog.Logger
 }
 
 // NewMetadataHandler creates a new metadata handler
 func NewMetadataHandler(
 	repos map[string]db.Repository,
+	summarizer *Summarizer,
 	logger *slog.Logger,
 ) *MetadataHandler {
 	return &MetadataHandler{
 		repositories: repos,
+		summarizer:   summarizer,
 		logger:       logger,
 	}
 }
 
+// ColumnMetadata describes a column and its comment
+type ColumnMetadata struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Key      string `json:"key,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Comment  string `json:"comment"`
+}
+
+// MetadataResult is the structured result of the metadata tool
+type MetadataResult struct {
+	Database     string           `json:"database"`
+	Table        string           `json:"table"`
+	TableComment string           `json:"table_comment"`
+	Columns      []ColumnMetadata `json:"columns"`
+	ColumnCount  int              `json:"column_count"`
+	Summary      *Summary         `json:"summary,omitempty"`
+	Warning      string           `json:"warning,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
 // HandleMetadata retrieves metadata for tables and columns
 func (h *MetadataHandler) HandleMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling metadata tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	tableName, err := request.RequireString("table")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(formatDatabaseNotFoundError(dbName, h.repositories)), nil
+		return toolerrors.Result(databaseNotFoundError(dbName, h.repositories)), nil
+	}
+
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
 	}
 
 	// Get table metadata based on database type
-	var metadata map[string]any
+	var metadata *MetadataResult
 
 	switch r := repo.(type) {
 	case *db.MySQLRepository:
@@ -59,40 +91,71 @@ func (h *MetadataHandler) HandleMetadata(ctx context.Context, request mcp.CallTo
 	case *db.PostgresRepository:
 		metadata, err = h.getPostgresMetadata(ctx, r, tableName)
 	default:
-		return mcp.NewToolResultError("unsupported repository type"), nil
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")), nil
 	}
 
 	if err != nil {
 		h.logger.WarnContext(ctx, "Failed to retrieve metadata", "error", err)
 		// Return empty metadata instead of error
-		metadata = map[string]any{
-			"database":      dbName,
-			"table":         tableName,
-			"warning":       "Metadata retrieval failed or not supported",
-			"error":         err.Error(),
-			"columns":       []string{},
-			"table_comment": "",
+		metadata = &MetadataResult{
+			Database: dbName,
+			Table:    tableName,
+			Warning:  "Metadata retrieval failed or not supported",
+			Error:    err.Error(),
+			Columns:  []ColumnMetadata{},
 		}
 	}
 
+	// Surface a summary generated earlier by semantic_summary, if the schema is unchanged
+	metadata.Summary = h.cachedSummary(ctx, repo, dbName, tableName)
+
 	resultJSON, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal metadata response", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(metadata, string(resultJSON)), nil
+}
+
+// cachedSummary looks up the cached semantic_summary summary of a table
+func (h *MetadataHandler) cachedSummary(ctx context.Context, repo db.Repository, dbName, tableName string) *Summary {
+	if h.summarizer == nil {
+		return nil
+	}
+
+	var info *db.TableInfo
+	var err error
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	case *db.PostgresRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+
+	schemaHash, err := SchemaHash(tableFingerprint(info))
+	if err != nil {
+		return nil
+	}
+	return h.summarizer.Cached(ctx, prompts.SummarizeTable, dbName, tableName, schemaHash)
 }
 
 // getMySQLMetadata retrieves metadata from MySQL information_schema
-func (h *MetadataHandler) getMySQLMetadata(ctx context.Context, repo *db.MySQLRepository, tableName string) (map[string]any, error) {
+func (h *MetadataHandler) getMySQLMetadata(ctx context.Context, repo *db.MySQLRepository, tableName string) (*MetadataResult, error) {
+	schema, table := db.SplitTableName(repo, tableName)
+
 	// Query for table comment
 	tableCommentQuery := `
 		SELECT table_comment
 		FROM information_schema.tables
-		WHERE table_schema = DATABASE() AND table_name = ?`
+		WHERE table_schema = ? AND table_name = ?`
 
 	var tableComment string
-	row := repo.QueryRow(ctx, tableCommentQuery, tableName)
+	row := repo.QueryRow(ctx, tableCommentQuery, schema, table)
 	if err := row.Scan(&tableComment); err != nil {
 		h.logger.WarnContext(ctx, "Failed to get table comment", "error", err)
 		tableComment = ""
@@ -102,16 +165,16 @@ func (h *MetadataHandler) getMySQLMetadata(ctx context.Context, repo *db.MySQLRe
 	columnCommentQuery := `
 		SELECT column_name, column_comment, column_type, is_nullable, column_key
 		FROM information_schema.columns
-		WHERE table_schema = DATABASE() AND table_name = ?
+		WHERE table_schema = ? AND table_name = ?
 		ORDER BY ordinal_position`
 
-	rows, err := repo.Query(ctx, columnCommentQuery, tableName)
+	rows, err := repo.Query(ctx, columnCommentQuery, schema, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get column metadata: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []map[string]any
+	columns := []ColumnMetadata{}
 	for rows.Next() {
 		var colName, colComment, colType, isNullable, colKey string
 		if err := rows.Scan(&colName, &colComment, &colType, &isNullable, &colKey); err != nil {
@@ -119,32 +182,34 @@ func (h *MetadataHandler) getMySQLMetadata(ctx context.Context, repo *db.MySQLRe
 			continue
 		}
 
-		columns = append(columns, map[string]any{
-			"name":     colName,
-			"type":     colType,
-			"nullable": isNullable == "YES",
-			"key":      colKey,
-			"comment":  colComment,
+		columns = append(columns, ColumnMetadata{
+			Name:     colName,
+			Type:     colType,
+			Nullable: isNullable == "YES",
+			Key:      colKey,
+			Comment:  colComment,
 		})
 	}
 
-	return map[string]any{
-		"database":      repo.GetName(),
-		"table":         tableName,
-		"table_comment": tableComment,
-		"columns":       columns,
-		"column_count":  len(columns),
+	return &MetadataResult{
+		Database:     repo.GetName(),
+		Table:        tableName,
+		TableComment: tableComment,
+		Columns:      columns,
+		ColumnCount:  len(columns),
 	}, nil
 }
 
 // getPostgresMetadata retrieves metadata from PostgreSQL information_schema
-func (h *MetadataHandler) getPostgresMetadata(ctx context.Context, repo *db.PostgresRepository, tableName string) (map[string]any, error) {
+func (h *MetadataHandler) getPostgresMetadata(ctx context.Context, repo *db.PostgresRepository, tableName string) (*MetadataResult, error) {
+	schema, table := db.SplitTableName(repo, tableName)
+
 	// PostgreSQL table comments require accessing pg_catalog
 	tableCommentQuery := `
-		SELECT obj_description($1::regclass, 'pg_class')`
+		SELECT obj_description(to_regclass(quote_ident($1) || '.' || quote_ident($2)), 'pg_class')`
 
 	var tableComment sql.NullString
-	row := repo.QueryRow(ctx, tableCommentQuery, tableName)
+	row := repo.QueryRow(ctx, tableCommentQuery, schema, table)
 	if err := row.Scan(&tableComment); err != nil {
 		h.logger.WarnContext(ctx, "Failed to get table comment", "error", err)
 	}
@@ -162,16 +227,16 @@ func (h *MetadataHandler) getPostgresMetadata(ctx context.Context, repo *db.Post
 			ON c.table_schema = st.schemaname AND c.table_name = st.relname
 		LEFT JOIN pg_catalog.pg_description pgd
 			ON pgd.objoid = st.relid AND pgd.objsubid = c.ordinal_position
-		WHERE c.table_schema = 'public' AND c.table_name = $1
+		WHERE c.table_schema = $1 AND c.table_name = $2
 		ORDER BY c.ordinal_position`
 
-	rows, err := repo.Query(ctx, columnCommentQuery, tableName)
+	rows, err := repo.Query(ctx, columnCommentQuery, schema, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get column metadata: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []map[string]any
+	columns := []ColumnMetadata{}
 	for rows.Next() {
 		var colName, dataType, isNullable string
 		var colDefault, colComment sql.NullString
@@ -181,27 +246,21 @@ func (h *MetadataHandler) getPostgresMetadata(ctx context.Context, repo *db.Post
 			continue
 		}
 
-		columns = append(columns, map[string]any{
-			"name":     colName,
-			"type":     dataType,
-			"nullable": isNullable == "YES",
-			"default":  colDefault.String,
-			"comment":  colComment.String,
+		columns = append(columns, ColumnMetadata{
+			Name:     colName,
+			Type:     dataType,
+			Nullable: isNullable == "YES",
+			Default:  colDefault.String,
+			Comment:  colComment.String,
 		})
 	}
 
-	result := map[string]any{
-		"database":     repo.GetName(),
-		"table":        tableName,
-		"columns":      columns,
-		"column_count": len(columns),
-	}
-
-	if tableComment.Valid {
-		result["table_comment"] = tableComment.String
-	} else {
-		result["table_comment"] = ""
-	}
-
-	return result, nil
+	// An invalid (NULL) comment leaves TableComment empty
+	return &MetadataResult{
+		Database:     repo.GetName(),
+		Table:        tableName,
+		TableComment: tableComment.String,
+		Columns:      columns,
+		ColumnCount:  len(columns),
+	}, nil
 }
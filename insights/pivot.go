@@ -0,0 +1,451 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// Output formats of the pivot tool
+const (
+	FormatJSON     = "json"     // one object per row
+	FormatMarkdown = "markdown" // a Markdown table with totals
+	FormatColumnar = "columnar" // one array per column
+)
+
+const (
+	defaultPivotColumns = 20
+	maxPivotColumns     = 100
+)
+
+// PivotHandler builds cross-tabs of a metric by two dimensions
+type PivotHandler struct {
+	repositories map[string]db.Repository
+	config       config.AnalyticsConfig
+	logger       *slog.Logger
+}
+
+// NewPivotHandler creates a new pivot handler
+func NewPivotHandler(
+	repos map[string]db.Repository,
+	cfg config.AnalyticsConfig,
+	logger *slog.Logger,
+) *PivotHandler {
+	if cfg.MaxResultRows <= 0 {
+		cfg.MaxResultRows = defaultMaxResultRows
+	}
+	return &PivotHandler{
+		repositories: repos,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// PivotResult is the structured result of the pivot tool. Columns holds the
+// column dimension values as labels; the cells of each row and the column
+// totals follow their order. Rows, Columnar or Markdown is set by format.
+type PivotResult struct {
+	Database         string         `json:"database"`
+	Table            string         `json:"table"`
+	RowDimension     string         `json:"row_dimension"`
+	RowBucket        string         `json:"row_bucket,omitempty"`
+	ColumnDimension  string         `json:"column_dimension"`
+	ColumnBucket     string         `json:"column_bucket,omitempty"`
+	Timezone         string         `json:"timezone,omitempty"`
+	Metric           db.Metric      `json:"metric"`
+	Format           string         `json:"format"`
+	Columns          []string       `json:"columns"`
+	ColumnsTruncated bool           `json:"columns_truncated,omitempty"` // the column dimension has more values than max_columns
+	RowCount         int            `json:"row_count"`
+	RowsTruncated    bool           `json:"rows_truncated,omitempty"` // more rows exist beyond max_result_rows
+	Rows             []PivotRow     `json:"rows,omitempty"`
+	Columnar         *PivotColumnar `json:"columnar,omitempty"`
+	Markdown         string         `json:"markdown,omitempty"`
+	ColumnTotals     []any          `json:"column_totals"`
+	GrandTotal       any            `json:"grand_total"`
+	Query            string         `json:"query"`
+}
+
+// PivotRow is one row of a pivot. Total covers the whole row, including
+// column values beyond max_columns.
+type PivotRow struct {
+	Key    any   `json:"key"`
+	Values []any `json:"values"`
+	Total  any   `json:"total"`
+}
+
+// PivotColumnar holds the rows of a pivot column by column: the row keys,
+// each column dimension value, then the row totals
+type PivotColumnar struct {
+	Names  []string `json:"names"`
+	Values [][]any  `json:"values"`
+}
+
+// HandlePivot computes a metric for each pair of row and column dimension values
+// CRITICAL: Uses parameterized queries to prevent SQL injection
+func (h *PivotHandler) HandlePivot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.InfoContext(ctx, "Handling pivot tool request")
+
+	dbName, err := request.RequireString("database")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	tableName, err := request.RequireString("table")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	rowDim, err := request.RequireString("row_dimension")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	columnDim, err := request.RequireString("column_dimension")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	rowBucket := strings.ToLower(request.GetString("row_bucket", ""))
+	columnBucket := strings.ToLower(request.GetString("column_bucket", ""))
+	for _, bucket := range []string{rowBucket, columnBucket} {
+		if bucket != "" && !slices.Contains(db.TimeBuckets, bucket) {
+			return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid bucket: %s", bucket).
+				WithHint("Must be one of: " + strings.Join(db.TimeBuckets, ", "))), nil
+		}
+	}
+
+	timezone := request.GetString("timezone", "UTC")
+	if _, err := db.LoadTimezone(timezone); err != nil {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid timezone: %s", timezone).
+			WithTarget(timezone).
+			WithHint("Use an IANA name such as Asia/Shanghai, or a UTC offset such as +08:00.")), nil
+	}
+
+	metric := db.Metric{Fn: "COUNT"}
+	if err := toolargs.Decode(request, "metric", &metric); err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if metric, err = db.NormalizeMetric(metric); err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "invalid metric").
+			WithHint("fn must be one of: " + strings.Join(db.AggregateFuncs, ", "))), nil
+	}
+	if isPercentile(metric) {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeUnsupported, "%s is not supported in pivots", metric.Fn).
+			WithHint("Use the analytics tool with group_by on both dimensions for percentiles.")), nil
+	}
+
+	maxColumns := request.GetInt("max_columns", defaultPivotColumns)
+	if maxColumns < 1 || maxColumns > maxPivotColumns {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"max_columns must be between 1 and %d", maxPivotColumns)), nil
+	}
+	format := request.GetString("format", FormatJSON)
+	if format != FormatJSON && format != FormatMarkdown && format != FormatColumnar {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid format: %s", format).
+			WithHint("Must be one of: json, markdown, columnar")), nil
+	}
+
+	// Validate identifiers before they reach the query builder
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	for _, column := range []string{rowDim, columnDim, metric.Column} {
+		if column == "" {
+			continue
+		}
+		if err := db.ValidateIdentifier("column", column); err != nil {
+			return toolerrors.Result(err), nil
+		}
+	}
+
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return toolerrors.Result(databaseNotFoundError(dbName, h.repositories)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	conditions, err := toolargs.Object(request, "conditions")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	for key := range conditions {
+		if err := db.ValidateIdentifier("column", key); err != nil {
+			return toolerrors.Result(err), nil
+		}
+	}
+
+	spec := db.PivotSpec{
+		Table:      db.FullTableName(repo, tableName),
+		Rows:       db.PivotDimension{Column: rowDim, Bucket: rowBucket},
+		Columns:    db.PivotDimension{Column: columnDim, Bucket: columnBucket},
+		Timezone:   timezone,
+		Metric:     metric,
+		Conditions: conditions,
+	}
+
+	// The execution timeout bounds the whole pivot, not each statement
+	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.ExecutionTimeout)*time.Second)
+	defer cancel()
+
+	for _, dim := range []*db.PivotDimension{&spec.Rows, &spec.Columns} {
+		if dim.Bucket != "" {
+			if dim.TimeKind, err = timeColumnKind(queryCtx, repo, tableName, dim.Column); err != nil {
+				return toolerrors.Result(err), nil
+			}
+		}
+	}
+
+	// Discover the column dimension values first; one extra tells whether
+	// there are more than max_columns
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	built := db.StartBuildSpan(ctx, "pivot_columns")
+	query, params, err := qb.BuildPivotColumns(spec, maxColumns+1)
+	built(query, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
+	keys, err := h.query(queryCtx, repo, query, params)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Pivot column query failed", "error", err, "query", query)
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, key[0])
+	}
+	columnsTruncated := len(values) > maxColumns
+	if columnsTruncated {
+		values = values[:maxColumns]
+	}
+
+	built = db.StartBuildSpan(ctx, "pivot")
+	query, params, err = qb.BuildPivotQuery(spec, values, h.config.MaxResultRows+1)
+	built(query, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
+	rows, err := h.query(queryCtx, repo, query, params)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Pivot query failed", "error", err, "query", query)
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	rowsTruncated := len(rows) > h.config.MaxResultRows
+	if rowsTruncated {
+		rows = rows[:h.config.MaxResultRows]
+	}
+
+	built = db.StartBuildSpan(ctx, "pivot_totals")
+	totalsQuery, params, err := qb.BuildPivotTotals(spec, values)
+	built(totalsQuery, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
+	totals, err := h.query(queryCtx, repo, totalsQuery, params)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Pivot totals query failed", "error", err, "query", totalsQuery)
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	metrics.RecordRows(ctx, len(rows))
+
+	response := PivotResult{
+		Database:         dbName,
+		Table:            tableName,
+		RowDimension:     rowDim,
+		RowBucket:        rowBucket,
+		ColumnDimension:  columnDim,
+		ColumnBucket:     columnBucket,
+		Metric:           metric,
+		Format:           format,
+		Columns:          make([]string, len(values)),
+		ColumnsTruncated: columnsTruncated,
+		RowCount:         len(rows),
+		RowsTruncated:    rowsTruncated,
+		ColumnTotals:     make([]any, len(values)),
+		Query:            query,
+	}
+	if rowBucket != "" || columnBucket != "" {
+		response.Timezone = timezone
+	}
+	for i, v := range values {
+		response.Columns[i] = pivotLabel(v, columnBucket)
+	}
+	if len(totals) == 1 {
+		copy(response.ColumnTotals, totals[0])
+		response.GrandTotal = totals[0][len(values)]
+	}
+
+	pivotRows := make([]PivotRow, len(rows))
+	for i, row := range rows {
+		pivotRows[i] = PivotRow{
+			Key:    pivotKey(row[0], rowBucket),
+			Values: row[1 : len(row)-1],
+			Total:  row[len(row)-1],
+		}
+	}
+
+	text := ""
+	switch format {
+	case FormatMarkdown:
+		response.Markdown = pivotMarkdown(response, pivotRows)
+		text = response.Markdown
+	case FormatColumnar:
+		response.Columnar = pivotColumnar(response, pivotRows)
+	default:
+		response.Rows = pivotRows
+	}
+
+	if text == "" {
+		resultJSON, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			h.logger.ErrorContext(ctx, "Failed to marshal pivot response", "error", err)
+			return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+		}
+		text = string(resultJSON)
+	}
+	return mcp.NewToolResultStructured(response, text), nil
+}
+
+// query runs a pivot query and returns its rows as value slices, with byte
+// slices converted to strings
+func (h *PivotHandler) query(ctx context.Context, repo db.Repository, query string, params []any) ([][]any, error) {
+	rows, err := repo.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	_, decodeSpan := tracing.Start(ctx, "db.decode_rows")
+	defer decodeSpan.End()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result = append(result, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	decodeSpan.SetAttributes(tracing.AttrDBRowCount.Int(len(result)))
+	return result, nil
+}
+
+// pivotKey returns a dimension value for the result, formatting time buckets
+// as labels
+func pivotKey(v any, bucket string) any {
+	if v == nil || bucket == "" {
+		return v
+	}
+	return pivotLabel(v, bucket)
+}
+
+// pivotLabel formats a dimension value as a column label. Time buckets are
+// shortened to their resolution, e.g. 2024-03 for months.
+func pivotLabel(v any, bucket string) string {
+	if v == nil {
+		return "NULL"
+	}
+	if bucket != "" {
+		if w, ok := wallTime(v); ok {
+			switch bucket {
+			case db.BucketMonth:
+				return w.Format("2006-01")
+			case db.BucketDay, db.BucketWeek:
+				return w.Format(time.DateOnly)
+			default:
+				return w.Format("2006-01-02 15:04")
+			}
+		}
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// pivotMarkdown renders a pivot as a Markdown table with a total column and
+// a total row
+func pivotMarkdown(result PivotResult, rows []PivotRow) string {
+	var b strings.Builder
+	header := slices.Concat([]string{result.RowDimension}, result.Columns, []string{"total"})
+	markdownRow(&b, header)
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		cells := []string{markdownCell(row.Key)}
+		for _, v := range row.Values {
+			cells = append(cells, markdownCell(v))
+		}
+		markdownRow(&b, append(cells, markdownCell(row.Total)))
+	}
+	totals := []string{"**total**"}
+	for _, v := range result.ColumnTotals {
+		totals = append(totals, markdownCell(v))
+	}
+	markdownRow(&b, append(totals, markdownCell(result.GrandTotal)))
+	if result.ColumnsTruncated || result.RowsTruncated {
+		b.WriteString("\nSome rows or columns were left out; totals cover all of them.\n")
+	}
+	return b.String()
+}
+
+// markdownRow writes one table row, escaping pipes in its cells
+func markdownRow(b *strings.Builder, cells []string) {
+	for _, cell := range cells {
+		b.WriteString("| " + strings.ReplaceAll(cell, "|", `\|`) + " ")
+	}
+	b.WriteString("|\n")
+}
+
+// markdownCell formats a value for a Markdown table; NULL cells stay empty
+func markdownCell(v any) string {
+	if v == nil {
+		return ""
+	}
+	return pivotLabel(v, "")
+}
+
+// pivotColumnar transposes the pivot rows into columns
+func pivotColumnar(result PivotResult, rows []PivotRow) *PivotColumnar {
+	columnar := &PivotColumnar{
+		Names:  slices.Concat([]string{result.RowDimension}, result.Columns, []string{"total"}),
+		Values: make([][]any, len(result.Columns)+2),
+	}
+	for i := range columnar.Values {
+		columnar.Values[i] = make([]any, len(rows))
+	}
+	for r, row := range rows {
+		columnar.Values[0][r] = row.Key
+		for c, v := range row.Values {
+			columnar.Values[c+1][r] = v
+		}
+		columnar.Values[len(columnar.Values)-1][r] = row.Total
+	}
+	return columnar
+}
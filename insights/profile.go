@@ -0,0 +1,571 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/progress"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+const (
+	defaultProfileSampleRows = 100000
+	defaultProfileTopN       = 10
+	maxProfileTopN           = 100
+	defaultProfileBins       = 10
+	maxProfileBins           = 100
+
+	// sampleOversample widens sampling fractions so that a random sample
+	// still reaches the requested size
+	sampleOversample = 1.1
+)
+
+// profilePercentiles are the percentiles reported for numeric columns
+var profilePercentiles = []int{1, 5, 25, 50, 75, 95, 99}
+
+// ProfileHandler computes per-column data profiles
+type ProfileHandler struct {
+	repositories map[string]db.Repository
+	config       config.AnalyticsConfig
+	logger       *slog.Logger
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(
+	repos map[string]db.Repository,
+	cfg config.AnalyticsConfig,
+	logger *slog.Logger,
+) *ProfileHandler {
+	if cfg.ProfileSampleRows <= 0 {
+		cfg.ProfileSampleRows = defaultProfileSampleRows
+	}
+	return &ProfileHandler{
+		repositories: repos,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// ProfileResult is the structured result of the profile tool
+type ProfileResult struct {
+	Database         string          `json:"database"`
+	Table            string          `json:"table"`
+	TableRows        *int64          `json:"table_rows,omitempty"` // row count of the table, see row_count_accuracy
+	RowCountAccuracy string          `json:"row_count_accuracy,omitempty"`
+	RowsProfiled     int64           `json:"rows_profiled"`
+	Sampled          bool            `json:"sampled"`
+	SampleMethod     string          `json:"sample_method,omitempty"` // tablesample, random or first_rows
+	Columns          []ColumnProfile `json:"columns"`
+	Warnings         []string        `json:"warnings,omitempty"`
+}
+
+// ColumnProfile holds the statistics of one column. Statistics that do not
+// apply to the column kind are omitted.
+type ColumnProfile struct {
+	Name                string            `json:"name"`
+	DataType            string            `json:"data_type"`
+	Kind                string            `json:"kind"`
+	NullCount           int64             `json:"null_count"`
+	NullRatio           float64           `json:"null_ratio"`
+	DistinctCount       *int64            `json:"distinct_count,omitempty"`
+	DistinctApproximate bool              `json:"distinct_approximate,omitempty"` // HyperLogLog estimate over the full table
+	Min                 any               `json:"min,omitempty"`
+	Max                 any               `json:"max,omitempty"`
+	Mean                *float64          `json:"mean,omitempty"`
+	StdDev              *float64          `json:"stddev,omitempty"`
+	Percentiles         []PercentileValue `json:"percentiles,omitempty"`
+	Length              *LengthStats      `json:"length,omitempty"`
+	TopValues           []ValueFrequency  `json:"top_values,omitempty"`
+	Histogram           []HistogramBucket `json:"histogram,omitempty"`
+	Error               string            `json:"error,omitempty"`
+}
+
+// PercentileValue is the value at a percentile of a numeric column
+type PercentileValue struct {
+	Percentile int `json:"percentile"`
+	Value      any `json:"value"`
+}
+
+// LengthStats describes the character lengths of a string column
+type LengthStats struct {
+	Min  *int64   `json:"min,omitempty"`
+	Max  *int64   `json:"max,omitempty"`
+	Mean *float64 `json:"mean,omitempty"`
+}
+
+// ValueFrequency is a value with the number of rows holding it
+type ValueFrequency struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// HistogramBucket is one equi-width bucket; the last bucket includes its upper bound
+type HistogramBucket struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int64   `json:"count"`
+}
+
+// HandleProfile profiles the columns of a table
+func (h *ProfileHandler) HandleProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.InfoContext(ctx, "Handling profile tool request")
+
+	dbName, err := request.RequireString("database")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	tableName, err := request.RequireString("table")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	columns := request.GetStringSlice("columns", nil)
+	for _, column := range columns {
+		if err := db.ValidateIdentifier("column", column); err != nil {
+			return toolerrors.Result(err), nil
+		}
+	}
+
+	topN := request.GetInt("top_n", defaultProfileTopN)
+	if topN < 0 || topN > maxProfileTopN {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"top_n must be between 0 and %d", maxProfileTopN)), nil
+	}
+	bins := request.GetInt("bins", defaultProfileBins)
+	if bins < 0 || bins > maxProfileBins {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"bins must be between 0 and %d", maxProfileBins)), nil
+	}
+
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return toolerrors.Result(databaseNotFoundError(dbName, h.repositories)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	// The execution timeout bounds the whole profile, not each statement
+	profileCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.ExecutionTimeout)*time.Second)
+	defer cancel()
+
+	result, err := h.profile(profileCtx, repo, dbName, tableName, columns, topN, bins)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal profile response", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+	}
+	return mcp.NewToolResultStructured(*result, string(resultJSON)), nil
+}
+
+// profile collects the statistics of the selected columns, or of all columns
+// when none are selected. Errors are *toolerrors.ToolError values.
+func (h *ProfileHandler) profile(ctx context.Context, repo db.Repository, dbName, tableName string, selected []string, topN, bins int) (*ProfileResult, error) {
+	var info *db.TableInfo
+	var err error
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	case *db.PostgresRepository:
+		info, err = r.GetTableInfo(ctx, tableName)
+	default:
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get table schema", "error", err)
+		return nil, db.ClassifyError(ctx, repo, tableName, err)
+	}
+	if len(info.Columns) == 0 {
+		return nil, db.TableNotFoundError(ctx, repo, tableName)
+	}
+
+	columns, err := profileColumns(info, tableName, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProfileResult{
+		Database:         dbName,
+		Table:            tableName,
+		TableRows:        info.RowCount,
+		RowCountAccuracy: info.RowCountAccuracy,
+		Columns:          make([]ColumnProfile, 0, len(columns)),
+	}
+
+	// Tables larger than the sample size are profiled from a random sample.
+	// Without a row count, the first rows stand in for the sample.
+	table := db.FullTableName(repo, tableName)
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	sampleRows := h.config.ProfileSampleRows
+	source := qb.BuildSampleSource(table, names, 0, 1)
+	switch {
+	case info.RowCount == nil:
+		source = qb.BuildSampleSource(table, names, sampleRows, 0)
+		result.SampleMethod = "first_rows"
+	case *info.RowCount > int64(sampleRows):
+		fraction := math.Min(1, sampleOversample*float64(sampleRows)/float64(*info.RowCount))
+		source = qb.BuildSampleSource(table, names, sampleRows, fraction)
+		result.Sampled = true
+		result.SampleMethod = "random"
+		if repo.GetDriver() == "postgres" {
+			result.SampleMethod = "tablesample"
+		}
+	}
+
+	for i, col := range columns {
+		progress.Report(ctx, i, len(columns), fmt.Sprintf("Profiling column %s", col.Name))
+
+		profile, rows, err := h.profileColumn(ctx, repo, qb, table, source, col, result.Sampled, topN, bins)
+		if err != nil {
+			// A timeout or cancellation ends the profile; other failures
+			// (e.g. an unsupported function on an old server) stay with the column
+			if ctx.Err() != nil {
+				return nil, db.ClassifyError(ctx, repo, tableName, err)
+			}
+			h.logger.WarnContext(ctx, "Failed to profile column", "table", tableName, "column", col.Name, "error", err)
+			profile.Error = err.Error()
+		}
+		if rows > result.RowsProfiled {
+			result.RowsProfiled = rows
+		}
+		result.Columns = append(result.Columns, profile)
+	}
+	progress.Report(ctx, len(columns), len(columns), "Profile complete")
+
+	if result.SampleMethod == "first_rows" {
+		if result.RowsProfiled < int64(sampleRows) {
+			result.SampleMethod = ""
+		} else {
+			result.Sampled = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"Row count of %s is unknown; profiled its first %d rows, which may not be representative", tableName, sampleRows))
+		}
+	}
+	metrics.RecordRows(ctx, int(result.RowsProfiled))
+
+	return result, nil
+}
+
+// profileColumns returns the columns to profile in table order, or an
+// UNKNOWN_COLUMN error naming the first selected column that does not exist
+func profileColumns(info *db.TableInfo, tableName string, selected []string) ([]db.ColumnInfo, error) {
+	if len(selected) == 0 {
+		return info.Columns, nil
+	}
+
+	wanted := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		wanted[name] = true
+	}
+	columns := make([]db.ColumnInfo, 0, len(selected))
+	for _, col := range info.Columns {
+		if wanted[col.Name] {
+			columns = append(columns, col)
+			delete(wanted, col.Name)
+		}
+	}
+	for _, name := range selected {
+		if wanted[name] {
+			return nil, unknownColumnError(info, tableName, name)
+		}
+	}
+	return columns, nil
+}
+
+// profileColumn computes the statistics of one column over source and returns
+// them with the number of rows profiled. A partial profile is returned with
+// the first error.
+func (h *ProfileHandler) profileColumn(
+	ctx context.Context,
+	repo db.Repository,
+	qb *db.QueryBuilder,
+	table, source string,
+	col db.ColumnInfo,
+	sampled bool,
+	topN, bins int,
+) (ColumnProfile, int64, error) {
+	kind := db.ColumnKind(col.DataType)
+	profile := ColumnProfile{Name: col.Name, DataType: col.DataType, Kind: kind}
+
+	stats := make([]any, 10)
+	ptrs := make([]any, len(stats))
+	for i := range stats {
+		ptrs[i] = &stats[i]
+	}
+	built := db.StartBuildSpan(ctx, "column_stats")
+	query := qb.BuildColumnStats(source, col.Name, kind, !sampled)
+	built(query, nil)
+	if err := repo.QueryRow(ctx, query).Scan(ptrs...); err != nil {
+		return profile, 0, err
+	}
+
+	rows, _ := profileInt(stats[0])
+	nonNull, _ := profileInt(stats[1])
+	profile.NullCount = rows - nonNull
+	if rows > 0 {
+		profile.NullRatio = float64(profile.NullCount) / float64(rows)
+	}
+	if n, ok := profileInt(stats[2]); ok {
+		profile.DistinctCount = &n
+	}
+	profile.Min, profile.Max = profileValue(stats[3]), profileValue(stats[4])
+	profile.Mean = profileFloatPtr(stats[5])
+	profile.StdDev = profileFloatPtr(stats[6])
+	if kind == db.ColumnKindString {
+		profile.Length = &LengthStats{Mean: profileFloatPtr(stats[9])}
+		if n, ok := profileInt(stats[7]); ok {
+			profile.Length.Min = &n
+		}
+		if n, ok := profileInt(stats[8]); ok {
+			profile.Length.Max = &n
+		}
+	}
+
+	if kind == db.ColumnKindOther || nonNull == 0 {
+		return profile, rows, nil
+	}
+
+	// Distinct counts of sampled tables come from a sketch of the full table
+	if sampled {
+		built := db.StartBuildSpan(ctx, "distinct_sketch")
+		query := qb.BuildDistinctSketch(table, col.Name)
+		built(query, nil)
+		registers, err := distinctSketch(ctx, repo, query)
+		if err != nil {
+			return profile, rows, err
+		}
+		n := db.EstimateDistinct(registers)
+		profile.DistinctCount = &n
+		profile.DistinctApproximate = true
+	}
+
+	if topN > 0 {
+		built := db.StartBuildSpan(ctx, "top_values")
+		query := qb.BuildTopValues(source, col.Name, topN)
+		built(query, nil)
+		values, err := h.topValues(ctx, repo, query)
+		if err != nil {
+			return profile, rows, err
+		}
+		profile.TopValues = values
+	}
+
+	if kind != db.ColumnKindNumeric {
+		return profile, rows, nil
+	}
+
+	built = db.StartBuildSpan(ctx, "percentile_tiles")
+	query = qb.BuildPercentileTiles(source, col.Name)
+	built(query, nil)
+	percentiles, err := h.percentiles(ctx, repo, query)
+	if err != nil {
+		return profile, rows, err
+	}
+	profile.Percentiles = percentiles
+
+	if bins > 0 {
+		histogram, err := h.histogram(ctx, repo, qb, source, col.Name, stats[3], stats[4], nonNull, bins)
+		if err != nil {
+			return profile, rows, err
+		}
+		profile.Histogram = histogram
+	}
+	return profile, rows, nil
+}
+
+// distinctSketch reads the HyperLogLog registers of a column
+func distinctSketch(ctx context.Context, repo db.Repository, query string, params ...any) (map[int]int, error) {
+	rows, err := repo.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registers := make(map[int]int)
+	for rows.Next() {
+		var register, rank int
+		if err := rows.Scan(&register, &rank); err != nil {
+			return nil, err
+		}
+		registers[register] = rank
+	}
+	return registers, rows.Err()
+}
+
+// topValues reads the most frequent values of a column
+func (h *ProfileHandler) topValues(ctx context.Context, repo db.Repository, query string) ([]ValueFrequency, error) {
+	rows, err := repo.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []ValueFrequency{}
+	for rows.Next() {
+		var value any
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		values = append(values, ValueFrequency{Value: profileValue(value), Count: count})
+	}
+	return values, rows.Err()
+}
+
+// percentiles reads the percentile tiles of a numeric column and picks the
+// reported percentiles. With fewer than 100 values there is one tile per value.
+func (h *ProfileHandler) percentiles(ctx context.Context, repo db.Repository, query string) ([]PercentileValue, error) {
+	rows, err := repo.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiles []any
+	for rows.Next() {
+		var tile int64
+		var value any
+		if err := rows.Scan(&tile, &value); err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, profileValue(value))
+	}
+	if err := rows.Err(); err != nil || len(tiles) == 0 {
+		return nil, err
+	}
+
+	percentiles := make([]PercentileValue, len(profilePercentiles))
+	for i, p := range profilePercentiles {
+		tile := int(math.Ceil(float64(p) * float64(len(tiles)) / 100))
+		percentiles[i] = PercentileValue{Percentile: p, Value: tiles[max(tile, 1)-1]}
+	}
+	return percentiles, nil
+}
+
+// histogram buckets a numeric column into equi-width bins between its min and max
+func (h *ProfileHandler) histogram(
+	ctx context.Context,
+	repo db.Repository,
+	qb *db.QueryBuilder,
+	source, column string,
+	minValue, maxValue any,
+	nonNull int64,
+	bins int,
+) ([]HistogramBucket, error) {
+	lower, ok := profileFloat(minValue)
+	if !ok {
+		return nil, nil
+	}
+	upper, ok := profileFloat(maxValue)
+	if !ok {
+		return nil, nil
+	}
+	if lower == upper {
+		return []HistogramBucket{{Lower: lower, Upper: upper, Count: nonNull}}, nil
+	}
+
+	width := (upper - lower) / float64(bins)
+	buckets := make([]HistogramBucket, bins)
+	for i := range buckets {
+		buckets[i] = HistogramBucket{Lower: lower + float64(i)*width, Upper: lower + float64(i+1)*width}
+	}
+	buckets[bins-1].Upper = upper
+
+	built := db.StartBuildSpan(ctx, "histogram")
+	query, params := qb.BuildHistogram(source, column, lower, width, bins)
+	built(query, nil)
+	rows, err := repo.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket float64
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		// Rounding can push values just outside the range
+		i := min(max(int(bucket), 0), bins-1)
+		buckets[i].Count += count
+	}
+	return buckets, rows.Err()
+}
+
+// profileValue converts driver values for JSON output
+func profileValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// profileFloat converts a numeric driver value to float64
+func profileFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// profileFloatPtr converts a numeric driver value, or returns nil for NULL
+func profileFloatPtr(v any) *float64 {
+	if f, ok := profileFloat(v); ok {
+		return &f
+	}
+	return nil
+}
+
+// profileInt converts an integer driver value to int64
+func profileInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case []byte:
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			return i, true
+		}
+	}
+	f, ok := profileFloat(v)
+	return int64(f), ok
+}
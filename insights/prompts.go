@@ -0,0 +1,202 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// PromptHandler renders the analysis prompts with live schema and sample data
+type PromptHandler struct {
+	introspection   *IntrospectionHandler
+	relationship    *RelationshipHandler
+	semanticSummary *SemanticSummaryHandler
+	templates       *prompts.Templates
+	logger          *slog.Logger
+}
+
+// NewPromptHandler creates a new prompt handler
+func NewPromptHandler(
+	introspection *IntrospectionHandler,
+	relationship *RelationshipHandler,
+	semanticSummary *SemanticSummaryHandler,
+	templates *prompts.Templates,
+	logger *slog.Logger,
+) *PromptHandler {
+	return &PromptHandler{
+		introspection:   introspection,
+		relationship:    relationship,
+		semanticSummary: semanticSummary,
+		templates:       templates,
+		logger:          logger,
+	}
+}
+
+// HandleSummarizeTable renders the summarize_table prompt
+func (h *PromptHandler) HandleSummarizeTable(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	dbName, tableName, err := requireDatabaseAndTable(request)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := h.tableData(ctx, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.render(ctx, prompts.SummarizeTable, fmt.Sprintf("Semantic summary of %s.%s", dbName, tableName), data)
+}
+
+// HandleExplainDataModel renders the explain_data_model prompt
+func (h *PromptHandler) HandleExplainDataModel(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	dbName, err := requireArgument(request, "database")
+	if err != nil {
+		return nil, err
+	}
+	tableName := request.Params.Arguments["table"]
+
+	schema, err := h.introspection.Introspect(ctx, dbName, false)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := h.relationship.Relationships(ctx, dbName, "", tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	data := prompts.Data{
+		Database:      dbName,
+		Table:         tableName,
+		Tables:        schema.Tables,
+		Relationships: relationships.Relationships,
+	}
+	return h.render(ctx, prompts.ExplainDataModel, fmt.Sprintf("Data model of %s", dbName), data)
+}
+
+// HandleWriteQueryForQuestion renders the write_query_for_question prompt.
+// With a table the prompt carries that table's schema and a sample, otherwise
+// the schema of every table.
+func (h *PromptHandler) HandleWriteQueryForQuestion(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	dbName, err := requireArgument(request, "database")
+	if err != nil {
+		return nil, err
+	}
+	question, err := requireArgument(request, "question")
+	if err != nil {
+		return nil, err
+	}
+	tableName := request.Params.Arguments["table"]
+
+	var data prompts.Data
+	if tableName != "" {
+		if data, err = h.tableData(ctx, dbName, tableName); err != nil {
+			return nil, err
+		}
+		data.Tables = []db.TableInfo{*data.Schema}
+	} else {
+		schema, err := h.introspection.Introspect(ctx, dbName, false)
+		if err != nil {
+			return nil, err
+		}
+		relationships, err := h.relationship.Relationships(ctx, dbName, "", "")
+		if err != nil {
+			return nil, err
+		}
+		data = prompts.Data{
+			Database:    dbName,
+			Tables:      schema.Tables,
+			ForeignKeys: flattenRelationships(relationships.Relationships),
+		}
+	}
+	data.Question = question
+
+	return h.render(ctx, prompts.WriteQueryForQuestion, fmt.Sprintf("Query for a question about %s", dbName), data)
+}
+
+// HandleInvestigateAnomaly renders the investigate_anomaly prompt
+func (h *PromptHandler) HandleInvestigateAnomaly(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	dbName, tableName, err := requireDatabaseAndTable(request)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := h.tableData(ctx, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	data.Column = request.Params.Arguments["column"]
+	data.Symptom = request.Params.Arguments["symptom"]
+
+	return h.render(ctx, prompts.InvestigateAnomaly, fmt.Sprintf("Anomaly investigation for %s.%s", dbName, tableName), data)
+}
+
+// tableData collects the schema, foreign keys and a data sample of a table
+func (h *PromptHandler) tableData(ctx context.Context, dbName, tableName string) (prompts.Data, error) {
+	summary, err := h.semanticSummary.Summarize(ctx, dbName, "", tableName, SampleOptions{})
+	if err != nil {
+		return prompts.Data{}, err
+	}
+	tableName = summary.Table
+	relationships, err := h.relationship.Relationships(ctx, dbName, "", tableName)
+	if err != nil {
+		return prompts.Data{}, err
+	}
+
+	return prompts.Data{
+		Database:    dbName,
+		Table:       tableName,
+		Schema:      summary.Schema,
+		ForeignKeys: relationships.Relationships[tableName],
+		SampleData:  summary.SampleData,
+	}, nil
+}
+
+// render executes a prompt template into a single user message
+func (h *PromptHandler) render(ctx context.Context, name, description string, data prompts.Data) (*mcp.GetPromptResult, error) {
+	text, err := h.templates.Render(name, data)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to render prompt", "prompt", name, "error", err)
+		return nil, toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to render prompt")
+	}
+
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	}), nil
+}
+
+// requireArgument returns a required prompt argument
+func requireArgument(request mcp.GetPromptRequest, name string) (string, error) {
+	value := request.Params.Arguments[name]
+	if value == "" {
+		return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "required argument %q not found", name)
+	}
+	return value, nil
+}
+
+// requireDatabaseAndTable returns the database and table prompt arguments
+func requireDatabaseAndTable(request mcp.GetPromptRequest) (string, string, error) {
+	dbName, err := requireArgument(request, "database")
+	if err != nil {
+		return "", "", err
+	}
+	tableName, err := requireArgument(request, "table")
+	if err != nil {
+		return "", "", err
+	}
+	return dbName, tableName, nil
+}
+
+// flattenRelationships lists every foreign key of a relationship graph
+func flattenRelationships(graph map[string][]db.ForeignKeyInfo) []db.ForeignKeyInfo {
+	fks := []db.ForeignKeyInfo{}
+	for _, tableFKs := range graph {
+		fks = append(fks, tableFKs...)
+	}
+	return fks
+}
@@ -1,10 +1,12 @@
 package insights
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -12,6 +14,8 @@ import (
 	"github.com/SkillingX/mcp-localbridge/cache"
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
 )
 
 // RelationshipHandler analyzes relationships between database tables
@@ -19,6 +23,7 @@ type RelationshipHandler struct {
 	repositories map[string]db.Repository
 	redisClients map[string]*cache.RedisClient
 	config       config.RelationshipConfig
+	summarizer   *Summarizer
 	logger       *slog.Logger
 }
 
@@ -27,112 +32,239 @@ func NewRelationshipHandler(
 	repos map[string]db.Repository,
 	redisClients map[string]*cache.RedisClient,
 	cfg config.RelationshipConfig,
+	summarizer *Summarizer,
 	logger *slog.Logger,
 ) *RelationshipHandler {
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = defaultRelationshipDepth
+	}
+	if cfg.InferSampleRows <= 0 {
+		cfg.InferSampleRows = defaultInferSampleRows
+	}
+	if cfg.InferMinConfidence <= 0 {
+		cfg.InferMinConfidence = defaultInferMinConfidence
+	}
+
 	return &RelationshipHandler{
 		repositories: repos,
 		redisClients: redisClients,
 		config:       cfg,
+		summarizer:   summarizer,
 		logger:       logger,
 	}
 }
 
+// defaultRelationshipDepth bounds relationship paths when max_depth is unset
+const defaultRelationshipDepth = 3
+
+// RelationshipResult is the structured result of the relationship tool. For
+// a single table, ReferencedBy lists the foreign keys pointing at it; in path
+// mode, Relationships holds the foreign keys along the paths. Inferred
+// relationships are kept apart from the declared ones.
+type RelationshipResult struct {
+	Database              string                             `json:"database"`
+	Schema                string                             `json:"schema,omitempty"`
+	TableFilter           string                             `json:"table_filter"`
+	Relationships         map[string][]db.ForeignKeyInfo     `json:"relationships"`
+	RelationshipCount     int                                `json:"relationship_count"`
+	ReferencedBy          []db.ForeignKeyInfo                `json:"referenced_by,omitempty"`
+	InferredRelationships map[string][]db.InferredForeignKey `json:"inferred_relationships,omitempty"`
+	InferredCount         int                                `json:"inferred_count,omitempty"`
+	FromTable             string                             `json:"from_table,omitempty"`
+	ToTable               string                             `json:"to_table,omitempty"`
+	MaxDepth              int                                `json:"max_depth,omitempty"`
+	Paths                 []RelationshipPath                 `json:"paths,omitempty"`
+	CachedAt              string                             `json:"cached_at"`
+	Summary               *Summary                           `json:"summary,omitempty"`
+}
+
+// RelationshipPath is one shortest join path between two tables
+type RelationshipPath struct {
+	Path       string        `json:"path"` // e.g. orders -[fk_orders_customer]-> customers
+	Length     int           `json:"length"`
+	Joins      []db.JoinStep `json:"joins"`
+	Predicates []string      `json:"predicates"` // join condition of each step
+	JoinClause string        `json:"join_clause"`
+}
+
 // HandleRelationship analyzes table relationships (foreign keys)
 func (h *RelationshipHandler) HandleRelationship(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling relationship tool request")
 
-	// Extract required parameter using mcp-go v0.43.2 best practices
+	// Extract required parameter
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	// Optional: specific table to analyze, or two tables to find paths between
+	tableName := request.GetString("table", "")
+	fromTable := request.GetString("from_table", "")
+	toTable := request.GetString("to_table", "")
+	schema := request.GetString("schema", "")
+
+	var result *RelationshipResult
+	switch {
+	case fromTable != "" || toTable != "":
+		if fromTable == "" || toTable == "" {
+			return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "from_table and to_table must be passed together").
+				WithHint("Pass both to find join paths, or table for the relationships of one table.")), nil
+		}
+		result, err = h.Paths(ctx, dbName, schema, fromTable, toTable)
+	default:
+		result, err = h.Relationships(ctx, dbName, schema, tableName)
+	}
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+	tableName = result.TableFilter
+
+	// Propose undeclared foreign keys unless the caller opts out
+	if result.Paths == nil && request.GetBool("infer", h.config.Infer) {
+		inferred, err := h.InferredRelationships(ctx, dbName, result.Schema, tableName)
+		if err != nil {
+			return toolerrors.Result(err), nil
+		}
+		// Copy so the cached relationship result is not modified
+		withInferred := *result
+		withInferred.InferredRelationships = inferred
+		withInferred.InferredCount = countInferred(inferred)
+		result = &withInferred
+	}
+
+	// Explain the data model through client sampling unless the caller opts out
+	if h.summarizer != nil && request.GetBool("summarize", h.summarizer.Enabled()) {
+		subject := tableName
+		if subject == "" {
+			subject = "*"
+		}
+		data := prompts.Data{
+			Database:      dbName,
+			Table:         tableName,
+			Relationships: result.Relationships,
+		}
+		summary, err := h.summarizer.Summarize(ctx, prompts.ExplainDataModel, dbName, subject, result.Relationships, data)
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to summarize relationships", "database", dbName, "error", err)
+		} else {
+			// Copy so the cached relationship result is not modified
+			withSummary := *result
+			withSummary.Summary = summary
+			result = &withSummary
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal relationship response", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
 	}
+	return mcp.NewToolResultStructured(*result, string(resultJSON)), nil
+}
 
+// Relationships returns the foreign key graph of a database, of one schema
+// when only schema is set, or of a single table when tableName is set.
+// Errors are *toolerrors.ToolError values.
+func (h *RelationshipHandler) Relationships(ctx context.Context, dbName, schema, tableName string) (*RelationshipResult, error) {
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(formatDatabaseNotFoundError(dbName, h.repositories)), nil
+		return nil, databaseNotFoundError(dbName, h.repositories)
 	}
 
-	// Optional: specific table to analyze
-	tableName := request.GetString("table", "")
+	var err error
+	if tableName != "" {
+		if tableName, err = db.ResolveTableName(repo, schema, tableName); err != nil {
+			return nil, err
+		}
+		schema = ""
+	} else if schema != "" {
+		if err := db.ResolveSchema(repo, schema); err != nil {
+			return nil, err
+		}
+	}
 
 	// Check cache
 	cacheKey := fmt.Sprintf("relationships:%s", dbName)
 	if tableName != "" {
 		cacheKey = fmt.Sprintf("relationships:%s:%s", dbName, tableName)
+	} else if schema != "" {
+		cacheKey = fmt.Sprintf("relationships:%s:%s.*", dbName, schema)
 	}
 
 	if h.config.CacheEnabled && len(h.redisClients) > 0 {
 		for _, redisClient := range h.redisClients {
 			cached, err := redisClient.Get(ctx, cacheKey)
 			if err == nil && cached != "" {
-				h.logger.InfoContext(ctx, "Returning relationships from cache", "database", dbName)
-				return mcp.NewToolResultText(cached), nil
+				var result RelationshipResult
+				if err := json.Unmarshal([]byte(cached), &result); err == nil {
+					h.logger.InfoContext(ctx, "Returning relationships from cache", "database", dbName)
+					return &result, nil
+				}
+				h.logger.WarnContext(ctx, "Ignoring unreadable relationship cache entry", "database", dbName)
 			}
 			break
 		}
 	}
 
-	// Get table list
-	var tables []string
-	if tableName != "" {
-		tables = []string{tableName}
-	} else {
-		switch r := repo.(type) {
-		case *db.MySQLRepository:
-			tables, err = r.GetTableList(ctx)
-		case *db.PostgresRepository:
-			tables, err = r.GetTableList(ctx)
-		default:
-			return mcp.NewToolResultError("unsupported repository type"), nil
-		}
-
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get table list: %v", err)), nil
-		}
+	// Read the foreign keys of every table in one catalog query, the way
+	// db.LoadJoinGraph does for joins
+	var fks []db.ForeignKeyInfo
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		fks, err = r.GetAllForeignKeys(ctx)
+	case *db.PostgresRepository:
+		fks, err = r.GetAllForeignKeys(ctx)
+	default:
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+	if err != nil {
+		return nil, db.ClassifyError(ctx, repo, "", err)
 	}
 
 	// Build relationship graph
-	relationshipGraph := make(map[string][]db.ForeignKeyInfo)
-
-	for _, table := range tables {
-		var fks []db.ForeignKeyInfo
-		var fkErr error
-		switch r := repo.(type) {
-		case *db.MySQLRepository:
-			fks, fkErr = r.GetForeignKeys(ctx, table)
-		case *db.PostgresRepository:
-			fks, fkErr = r.GetForeignKeys(ctx, table)
-		}
-
-		if fkErr != nil {
-			h.logger.WarnContext(ctx, "Failed to get foreign keys", "table", table, "error", fkErr)
-			continue
+	allGraph := make(map[string][]db.ForeignKeyInfo)
+	for _, fk := range fks {
+		allGraph[fk.SourceTable] = append(allGraph[fk.SourceTable], fk)
+	}
+	relationshipGraph := allGraph
+	switch {
+	case tableName != "":
+		relationshipGraph = make(map[string][]db.ForeignKeyInfo)
+		if own := allGraph[tableName]; len(own) > 0 {
+			relationshipGraph[tableName] = own
 		}
-
-		if len(fks) > 0 {
-			relationshipGraph[table] = fks
+	case schema != "":
+		relationshipGraph = make(map[string][]db.ForeignKeyInfo)
+		for table, own := range allGraph {
+			if tableSchema, _ := db.SplitTableName(repo, table); tableSchema == schema {
+				relationshipGraph[table] = own
+			}
 		}
 	}
 
 	// Build result
-	result := map[string]any{
-		"database":           dbName,
-		"table_filter":       tableName,
-		"relationships":      relationshipGraph,
-		"relationship_count": countRelationships(relationshipGraph),
-		"cached_at":          time.Now().UTC().Format(time.RFC3339),
-		"llm_prompt":         buildRelationshipPrompt(dbName, relationshipGraph),
+	result := &RelationshipResult{
+		Database:          dbName,
+		Schema:            schema,
+		TableFilter:       tableName,
+		Relationships:     relationshipGraph,
+		RelationshipCount: countRelationships(relationshipGraph),
+		CachedAt:          time.Now().UTC().Format(time.RFC3339),
 	}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		h.logger.ErrorContext(ctx, "Failed to marshal relationship response", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	// A single table also lists the foreign keys referencing it, found in
+	// the graph of the whole database
+	if tableName != "" {
+		result.ReferencedBy = referencesTo(allGraph, tableName)
 	}
 
 	// Cache the result
 	if h.config.CacheEnabled && len(h.redisClients) > 0 {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal relationship result")
+		}
 		for _, redisClient := range h.redisClients {
 			ttl := time.Duration(h.config.CacheTTL) * time.Second
 			if err := redisClient.Set(ctx, cacheKey, string(resultJSON), ttl); err != nil {
@@ -142,40 +274,115 @@ func (h *RelationshipHandler) HandleRelationship(ctx context.Context, request mc
 		}
 	}
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return result, nil
 }
 
-// countRelationships counts total number of foreign key relationships
-func countRelationships(graph map[string][]db.ForeignKeyInfo) int {
-	count := 0
-	for _, fks := range graph {
-		count += len(fks)
+// Paths returns every shortest join path between two tables, following
+// foreign keys in either direction up to the configured max_depth. Errors are
+// *toolerrors.ToolError values.
+func (h *RelationshipHandler) Paths(ctx context.Context, dbName, schema, fromTable, toTable string) (*RelationshipResult, error) {
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return nil, databaseNotFoundError(dbName, h.repositories)
+	}
+	var err error
+	if fromTable, err = db.ResolveTableName(repo, schema, fromTable); err != nil {
+		return nil, err
+	}
+	if toTable, err = db.ResolveTableName(repo, schema, toTable); err != nil {
+		return nil, err
 	}
-	return count
-}
-
-// buildRelationshipPrompt creates an LLM prompt for relationship analysis
-func buildRelationshipPrompt(dbName string, graph map[string][]db.ForeignKeyInfo) string {
-	graphJSON, _ := json.MarshalIndent(graph, "", "  ")
 
-	return fmt.Sprintf(`# Task: Analyze Database Relationships
+	all, err := h.Relationships(ctx, dbName, "", "")
+	if err != nil {
+		return nil, err
+	}
+	graph := db.NewJoinGraph(flattenRelationships(all.Relationships))
+	found := graph.ShortestPaths([]string{fromTable}, toTable, h.config.MaxDepth)
+	if len(found) == 0 {
+		return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"no foreign key path joins %s to %s within %d joins", fromTable, toTable, h.config.MaxDepth).
+			WithTarget(toTable).
+			WithHint("Check the table names or raise tools.insights.relationship.max_depth; paths follow declared foreign keys only.")
+	}
 
-You are analyzing the relationships (foreign keys) in the "%s" database to understand the data model.
+	// The graph comes from a map, so paths are sorted for a stable result
+	slices.SortFunc(found, func(a, b []db.JoinStep) int {
+		return cmp.Compare(db.FormatJoinPath(a), db.FormatJoinPath(b))
+	})
+	fullNames := map[string]string{fromTable: db.FullTableName(repo, fromTable)}
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	used := make(map[string]bool) // source table and name of each foreign key on a path
+	paths := make([]RelationshipPath, len(found))
+	for i, steps := range found {
+		path := RelationshipPath{
+			Path:       db.FormatJoinPath(steps),
+			Length:     len(steps),
+			Joins:      steps,
+			Predicates: make([]string, len(steps)),
+		}
+		for j, step := range steps {
+			path.Predicates[j] = step.Predicate()
+			fullNames[step.ToTable] = db.FullTableName(repo, step.ToTable)
+			source := step.FromTable
+			if step.Direction == db.JoinInbound {
+				source = step.ToTable
+			}
+			used[source+"."+step.Constraint] = true
+		}
+		built := db.StartBuildSpan(ctx, "join_clause")
+		path.JoinClause, err = qb.BuildJoinClause(fromTable, steps, fullNames, false)
+		built(path.JoinClause, err)
+		if err != nil {
+			return nil, toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to build join clause")
+		}
+		paths[i] = path
+	}
 
-## Relationship Graph:
-%s
+	// Relationships keeps the foreign keys used by the paths
+	relationshipGraph := make(map[string][]db.ForeignKeyInfo)
+	for table, fks := range all.Relationships {
+		for _, fk := range fks {
+			if used[fk.SourceTable+"."+fk.Name] {
+				relationshipGraph[table] = append(relationshipGraph[table], fk)
+			}
+		}
+	}
 
-## Your Task:
-Please analyze the foreign key relationships and provide:
+	return &RelationshipResult{
+		Database:          dbName,
+		Relationships:     relationshipGraph,
+		RelationshipCount: countRelationships(relationshipGraph),
+		FromTable:         fromTable,
+		ToTable:           toTable,
+		MaxDepth:          h.config.MaxDepth,
+		Paths:             paths,
+		CachedAt:          all.CachedAt,
+	}, nil
+}
 
-1. **Entity Relationship Overview**: Describe the main entities and how they relate to each other
-2. **Central Tables**: Identify which tables are most connected (hub tables)
-3. **Data Flow**: Describe typical data flow patterns based on relationships
-4. **Potential Issues**: Identify any missing relationships or potential design issues
-5. **Query Recommendations**: Suggest useful JOIN queries based on these relationships
+// referencesTo returns the foreign keys referencing a table, ordered by
+// referencing table and name
+func referencesTo(graph map[string][]db.ForeignKeyInfo, table string) []db.ForeignKeyInfo {
+	var refs []db.ForeignKeyInfo
+	for _, fks := range graph {
+		for _, fk := range fks {
+			if fk.ReferencedTable == table {
+				refs = append(refs, fk)
+			}
+		}
+	}
+	slices.SortFunc(refs, func(a, b db.ForeignKeyInfo) int {
+		return cmp.Or(cmp.Compare(a.SourceTable, b.SourceTable), cmp.Compare(a.Name, b.Name))
+	})
+	return refs
+}
 
-Please provide your response in a clear, structured format.`,
-		dbName,
-		graphJSON,
-	)
+// countRelationships counts total number of foreign key relationships
+func countRelationships(graph map[string][]db.ForeignKeyInfo) int {
+	count := 0
+	for _, fks := range graph {
+		count += len(fks)
+	}
+	return count
 }
@@ -0,0 +1,185 @@
+package insights
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/progress"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+const (
+	defaultInferSampleRows    = 10000
+	defaultInferMinConfidence = 0.3
+)
+
+// InferredRelationships proposes foreign keys that are not declared, keyed
+// by referencing table like RelationshipResult.Relationships: columns named
+// after a table's primary key with a compatible type, checked against the
+// values of the key in a sample of rows. Candidates that fail the check, or
+// have no values to check, are left out.
+//
+// With tableName, only the table's own columns and the keys referencing it
+// are proposed; with schema, only the columns of its tables. schema and
+// tableName are resolved names, as in a RelationshipResult. Errors are
+// *toolerrors.ToolError values.
+func (h *RelationshipHandler) InferredRelationships(ctx context.Context, dbName, schema, tableName string) (map[string][]db.InferredForeignKey, error) {
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return nil, databaseNotFoundError(dbName, h.repositories)
+	}
+
+	cacheKey := fmt.Sprintf("relationships:inferred:%s", dbName)
+	if tableName != "" {
+		cacheKey = fmt.Sprintf("relationships:inferred:%s:%s", dbName, tableName)
+	} else if schema != "" {
+		cacheKey = fmt.Sprintf("relationships:inferred:%s:%s.*", dbName, schema)
+	}
+	if h.config.CacheEnabled && len(h.redisClients) > 0 {
+		for _, redisClient := range h.redisClients {
+			cached, err := redisClient.Get(ctx, cacheKey)
+			if err == nil && cached != "" {
+				var inferred map[string][]db.InferredForeignKey
+				if err := json.Unmarshal([]byte(cached), &inferred); err == nil {
+					h.logger.InfoContext(ctx, "Returning inferred relationships from cache", "database", dbName)
+					return inferred, nil
+				}
+				h.logger.WarnContext(ctx, "Ignoring unreadable inferred relationship cache entry", "database", dbName)
+			}
+			break
+		}
+	}
+
+	// Candidates come from the columns of every table, since any table may
+	// be referenced, read without row counts or the rest of the metadata
+	declared, err := h.Relationships(ctx, dbName, "", "")
+	if err != nil {
+		return nil, err
+	}
+	tables, err := h.tableColumns(ctx, dbName, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := db.InferForeignKeys(tables, flattenRelationships(declared.Relationships), tableName)
+	if tableName == "" && schema != "" {
+		candidates = slices.DeleteFunc(candidates, func(fk db.InferredForeignKey) bool {
+			tableSchema, _ := db.SplitTableName(repo, fk.SourceTable)
+			return tableSchema != schema
+		})
+	}
+
+	// Check the sampled values of each candidate against its referenced key
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	inferred := make(map[string][]db.InferredForeignKey)
+	for i, fk := range candidates {
+		if err := ctx.Err(); err != nil {
+			return nil, toolerrors.FromDBError(err)
+		}
+		progress.Report(ctx, i, len(candidates), fmt.Sprintf("Checking %s.%s against %s", fk.SourceTable, fk.SourceColumn, fk.ReferencedTable))
+
+		built := db.StartBuildSpan(ctx, "containment_check")
+		query := qb.BuildContainmentCheck(
+			db.FullTableName(repo, fk.SourceTable), fk.SourceColumn,
+			db.FullTableName(repo, fk.ReferencedTable), fk.ReferencedColumn,
+			h.config.InferSampleRows)
+		built(query, nil)
+		var sampled, matched int64
+		// Names and types alone are not evidence enough, so a candidate
+		// whose values could not be checked is left out
+		if err := repo.QueryRow(ctx, query).Scan(&sampled, &matched); err != nil {
+			h.logger.WarnContext(ctx, "Failed to check inferred relationship", "table", fk.SourceTable, "column", fk.SourceColumn, "error", err)
+			continue
+		}
+		if !fk.ApplyContainment(sampled, matched) {
+			continue
+		}
+		if fk.Confidence >= h.config.InferMinConfidence {
+			inferred[fk.SourceTable] = append(inferred[fk.SourceTable], fk)
+		}
+	}
+	progress.Report(ctx, len(candidates), len(candidates), "Relationship inference complete")
+
+	for _, fks := range inferred {
+		slices.SortStableFunc(fks, func(a, b db.InferredForeignKey) int {
+			return cmp.Compare(b.Confidence, a.Confidence)
+		})
+	}
+
+	if h.config.CacheEnabled && len(h.redisClients) > 0 {
+		inferredJSON, err := json.Marshal(inferred)
+		if err != nil {
+			return nil, toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal inferred relationships")
+		}
+		for _, redisClient := range h.redisClients {
+			ttl := time.Duration(h.config.CacheTTL) * time.Second
+			if err := redisClient.Set(ctx, cacheKey, string(inferredJSON), ttl); err != nil {
+				h.logger.WarnContext(ctx, "Failed to cache inferred relationships", "error", err)
+			}
+			break
+		}
+	}
+
+	return inferred, nil
+}
+
+// tableColumns returns the columns and primary keys of every table, the only
+// metadata inference needs, from the cache or one catalog query
+func (h *RelationshipHandler) tableColumns(ctx context.Context, dbName string, repo db.Repository) (map[string]*db.TableInfo, error) {
+	cacheKey := fmt.Sprintf("relationships:columns:%s", dbName)
+	if h.config.CacheEnabled && len(h.redisClients) > 0 {
+		for _, redisClient := range h.redisClients {
+			cached, err := redisClient.Get(ctx, cacheKey)
+			if err == nil && cached != "" {
+				var tables map[string]*db.TableInfo
+				if err := json.Unmarshal([]byte(cached), &tables); err == nil {
+					return tables, nil
+				}
+				h.logger.WarnContext(ctx, "Ignoring unreadable column cache entry", "database", dbName)
+			}
+			break
+		}
+	}
+
+	progress.Report(ctx, 0, 1, "Reading table columns")
+	var tables map[string]*db.TableInfo
+	var err error
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		tables, err = r.GetAllColumns(ctx)
+	case *db.PostgresRepository:
+		tables, err = r.GetAllColumns(ctx)
+	default:
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
+	}
+	if err != nil {
+		return nil, db.ClassifyError(ctx, repo, "", err)
+	}
+
+	if h.config.CacheEnabled && len(h.redisClients) > 0 {
+		if tablesJSON, err := json.Marshal(tables); err == nil {
+			for _, redisClient := range h.redisClients {
+				ttl := time.Duration(h.config.CacheTTL) * time.Second
+				if err := redisClient.Set(ctx, cacheKey, string(tablesJSON), ttl); err != nil {
+					h.logger.WarnContext(ctx, "Failed to cache table columns", "error", err)
+				}
+				break
+			}
+		}
+	}
+	return tables, nil
+}
+
+// countInferred counts the inferred relationships of a graph
+func countInferred(graph map[string][]db.InferredForeignKey) int {
+	count := 0
+	for _, fks := range graph {
+		count += len(fks)
+	}
+	return count
+}
@@ -1,21 +1,42 @@
 package insights
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
 )
 
+const (
+	defaultSummarySampleSize = 100
+	defaultSummaryMaxColumns = 50
+
+	// samplePoolRows bounds the rows that random, stratified and hash sampling
+	// sort; larger tables are pre-sampled down to this size
+	samplePoolRows = 10000
+)
+
+// recentColumnNames are the preferred timestamp columns for recent sampling, in order
+var recentColumnNames = []string{"created_at", "inserted_at", "created", "updated_at", "modified_at", "updated", "timestamp"}
+
 // SemanticSummaryHandler generates semantic summaries of table data
 type SemanticSummaryHandler struct {
 	repositories map[string]db.Repository
 	config       config.SemanticSummaryConfig
+	summarizer   *Summarizer
 	logger       *slog.Logger
 }
 
@@ -23,35 +44,129 @@ type SemanticSummaryHandler struct {
 func NewSemanticSummaryHandler(
 	repos map[string]db.Repository,
 	cfg config.SemanticSummaryConfig,
+	summarizer *Summarizer,
 	logger *slog.Logger,
 ) *SemanticSummaryHandler {
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = defaultSummarySampleSize
+	}
+	if cfg.MaxColumns <= 0 {
+		cfg.MaxColumns = defaultSummaryMaxColumns
+	}
+	if cfg.SampleMode == "" {
+		cfg.SampleMode = db.SampleRandom
+	}
 	return &SemanticSummaryHandler{
 		repositories: repos,
 		config:       cfg,
+		summarizer:   summarizer,
 		logger:       logger,
 	}
 }
 
+// SemanticSummaryResult is the structured result of semantic_summary
+type SemanticSummaryResult struct {
+	Database       string           `json:"database"`
+	Table          string           `json:"table"`
+	Schema         *db.TableInfo    `json:"schema"`
+	SampleMode     string           `json:"sample_mode"`
+	SampleColumn   string           `json:"sample_column,omitempty"` // timestamp or categorical column the sample was drawn by
+	SampleCount    int              `json:"sample_count"`
+	SampleData     []map[string]any `json:"sample_data"`
+	OmittedColumns []string         `json:"omitted_columns,omitempty"` // least informative columns beyond max_columns
+	Warnings       []string         `json:"warnings,omitempty"`
+	Description    string           `json:"description"`
+	Summary        *Summary         `json:"summary,omitempty"`
+
+	tableInfo *db.TableInfo // full schema, before columns were omitted
+}
+
+// SampleOptions selects how rows are sampled for a summary
+type SampleOptions struct {
+	Mode   string // one of db.SampleModes; empty uses the configured default
+	Column string // timestamp column for recent, categorical column for stratified
+	Seed   string // seed for hash sampling
+}
+
 // HandleSemanticSummary generates a semantic summary of table data
-// This handler provides an LLM prompt template that MCP clients can use to generate summaries
 func (h *SemanticSummaryHandler) HandleSemanticSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling semantic_summary tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	tableName, err := request.RequireString("table")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	opts := SampleOptions{
+		Mode:   request.GetString("sample_mode", ""),
+		Column: request.GetString("sample_column", ""),
+		Seed:   request.GetString("seed", ""),
+	}
+	result, err := h.Summarize(ctx, dbName, request.GetString("schema", ""), tableName, opts)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+	tableName = result.Table
+
+	// Generate the summary through client sampling unless the caller opts out
+	if h.summarizer != nil && request.GetBool("summarize", h.summarizer.Enabled()) {
+		data := prompts.Data{
+			Database:   dbName,
+			Table:      tableName,
+			Schema:     result.Schema,
+			SampleData: result.SampleData,
+		}
+		summary, err := h.summarizer.Summarize(ctx, prompts.SummarizeTable, dbName, tableName, tableFingerprint(result.tableInfo), data)
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to summarize table", "table", tableName, "error", err)
+		} else {
+			result.Summary = summary
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal semantic summary response", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+	}
+	return mcp.NewToolResultStructured(*result, string(resultJSON)), nil
+}
+
+// Summarize collects the schema and a data sample of a table. schema may be
+// empty when tableName is unqualified or already schema.table. Errors are
+// *toolerrors.ToolError values.
+func (h *SemanticSummaryHandler) Summarize(ctx context.Context, dbName, schema, tableName string, opts SampleOptions) (*SemanticSummaryResult, error) {
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return nil, err
+	}
+	if opts.Mode == "" {
+		opts.Mode = h.config.SampleMode
+	}
+	if !slices.Contains(db.SampleModes, opts.Mode) {
+		return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid sample_mode: %s", opts.Mode).
+			WithTarget(opts.Mode).
+			WithHint("Must be one of: " + strings.Join(db.SampleModes, ", "))
+	}
+	if opts.Column != "" {
+		if err := db.ValidateIdentifier("sample_column", opts.Column); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(formatDatabaseNotFoundError(dbName, h.repositories)), nil
+		return nil, databaseNotFoundError(dbName, h.repositories)
+	}
+	tableName, err := db.ResolveTableName(repo, schema, tableName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get table schema
@@ -62,26 +177,43 @@ func (h *SemanticSummaryHandler) HandleSemanticSummary(ctx context.Context, requ
 	case *db.PostgresRepository:
 		tableInfo, err = r.GetTableInfo(ctx, tableName)
 	default:
-		return mcp.NewToolResultError("unsupported repository type"), nil
+		return nil, toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")
 	}
 
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to get table schema", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get table schema: %v", err)), nil
+		return nil, db.ClassifyError(ctx, repo, tableName, err)
+	}
+	if len(tableInfo.Columns) == 0 {
+		return nil, db.TableNotFoundError(ctx, repo, tableName)
+	}
+
+	result := &SemanticSummaryResult{
+		Database:    dbName,
+		Table:       tableName,
+		Schema:      tableInfo,
+		SampleMode:  opts.Mode,
+		Description: "Use the summarize_table prompt, or set summarize to have the server sample a summary from the client, to turn this schema and sample into business-meaningful insights.",
+		tableInfo:   tableInfo,
 	}
 
 	// Sample data from the table
-	qb := db.NewQueryBuilder(repo.GetDriver())
-	query, params := qb.BuildSelect(tableName, nil, h.config.SampleSize, 0, "")
+	built := db.StartBuildSpan(ctx, "sample")
+	query, params, err := h.sampleQuery(repo, tableInfo, tableName, opts, result)
+	built(query, err)
+	if err != nil {
+		return nil, err
+	}
 
 	rows, err := repo.Query(ctx, query, params...)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to sample table data", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to sample table data: %v", err)), nil
+		return nil, db.ClassifyError(ctx, repo, tableName, err)
 	}
 	defer rows.Close()
 
 	// Parse sample data
+	_, decodeSpan := tracing.Start(ctx, "db.decode_rows")
 	sampleData := []map[string]any{}
 	columns, _ := rows.Columns()
 
@@ -106,80 +238,207 @@ func (h *SemanticSummaryHandler) HandleSemanticSummary(ctx context.Context, requ
 				rowMap[col] = val
 			}
 		}
+		// Drop the helper column of stratified sampling
+		delete(rowMap, "sample_rank")
 		sampleData = append(sampleData, rowMap)
 	}
+	decodeSpan.SetAttributes(tracing.AttrDBRowCount.Int(len(sampleData)))
+	decodeSpan.End()
+	metrics.RecordRows(ctx, len(sampleData))
+
+	// Keep the most informative columns of wide tables
+	if len(tableInfo.Columns) > h.config.MaxColumns {
+		kept, omitted := informativeColumns(tableInfo, sampleData, h.config.MaxColumns)
+		trimmed := *tableInfo
+		trimmed.Columns = kept
+		result.Schema = &trimmed
+		result.OmittedColumns = omitted
+		for _, row := range sampleData {
+			for _, name := range omitted {
+				delete(row, name)
+			}
+		}
+	}
+
+	result.SampleCount = len(sampleData)
+	result.SampleData = sampleData
+	return result, nil
+}
 
-	// Build LLM prompt template for MCP clients
-	llmPromptTemplate := buildSemanticSummaryPrompt(tableName, tableInfo, sampleData)
+// sampleQuery builds the query for a data sample of a table following the
+// sampling mode, recording the sampling column and any caveats in result
+func (h *SemanticSummaryHandler) sampleQuery(
+	repo db.Repository,
+	info *db.TableInfo,
+	tableName string,
+	opts SampleOptions,
+	result *SemanticSummaryResult,
+) (string, []any, error) {
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	table := db.FullTableName(repo, tableName)
+	size := h.config.SampleSize
 
-	result := map[string]any{
-		"database":     dbName,
-		"table":        tableName,
-		"schema":       tableInfo,
-		"sample_count": len(sampleData),
-		"sample_data":  sampleData,
-		"llm_prompt":   llmPromptTemplate,
-		"description":  "This result includes an LLM prompt template for generating semantic summaries. MCP clients (like Vibe Coding) can use this prompt to call their LLM and get business-meaningful insights.",
+	if opts.Column != "" {
+		if !slices.ContainsFunc(info.Columns, func(col db.ColumnInfo) bool { return col.Name == opts.Column }) {
+			return "", nil, unknownColumnError(info, tableName, opts.Column)
+		}
+		if opts.Mode != db.SampleRecent && opts.Mode != db.SampleStratified {
+			return "", nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "sample_column only applies to the recent and stratified sample modes, not %s", opts.Mode).
+				WithTarget(opts.Column)
+		}
 	}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		h.logger.ErrorContext(ctx, "Failed to marshal semantic summary response", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	// Random, stratified and hash sampling sort their candidate rows, so large
+	// tables are first reduced to a random pool of samplePoolRows rows
+	names := make([]string, len(info.Columns))
+	for i, col := range info.Columns {
+		names[i] = col.Name
+	}
+	poolFraction := 1.0
+	switch {
+	case info.RowCount == nil:
+		poolFraction = 0
+	case *info.RowCount > samplePoolRows:
+		poolFraction = math.Min(1, sampleOversample*samplePoolRows/float64(*info.RowCount))
+	}
+	pool := qb.BuildSampleSource(table, names, samplePoolRows, poolFraction)
+	if poolFraction == 0 && (opts.Mode == db.SampleRandom || opts.Mode == db.SampleStratified || opts.Mode == db.SampleHash) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"Row count of %s is unknown; sampled from its first %d rows", tableName, samplePoolRows))
+	}
+
+	switch opts.Mode {
+	case db.SampleRandom:
+		return qb.BuildRandomSample(pool, size), nil, nil
+
+	case db.SampleRecent:
+		column := opts.Column
+		if column == "" {
+			column = recentColumn(info)
+		}
+		if column == "" {
+			return "", nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "table '%s' has no timestamp column to sample recent rows by", tableName).
+				WithTarget(tableName).
+				WithHint("Pass sample_column with the column that orders rows by recency.")
+		}
+		result.SampleColumn = column
+		return qb.BuildRecentSample(table, column, size), nil, nil
+
+	case db.SampleStratified:
+		if opts.Column == "" {
+			return "", nil, toolerrors.New(toolerrors.CodeInvalidArgument, "stratified sampling requires sample_column").
+				WithHint("Pass sample_column with a categorical column such as a status or type.")
+		}
+		result.SampleColumn = opts.Column
+		return qb.BuildStratifiedSample(pool, opts.Column, size), nil, nil
+
+	case db.SampleHash:
+		key := info.PrimaryKey
+		if len(key) == 0 {
+			return "", nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "hash sampling requires a primary key, and table '%s' has none", tableName).
+				WithTarget(tableName).
+				WithHint("Use the random sample mode instead.")
+		}
+		// Filtering by hash keeps the sample reproducible on large tables,
+		// where the random pool would not be
+		source, fraction := pool, 1.0
+		if poolFraction > 0 && poolFraction < 1 {
+			source = qb.BuildSampleSource(table, names, 0, 1)
+			fraction = math.Min(1, 2*float64(size)/float64(*info.RowCount))
+		}
+		query, params := qb.BuildHashSample(source, key, opts.Seed, fraction, size)
+		return query, params, nil
+
+	default:
+		query, params := qb.BuildSelect(table, nil, size, 0, "")
+		return query, params, nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
 }
 
-// buildSemanticSummaryPrompt creates an LLM prompt template for semantic summarization
-// This template is provided to MCP clients for them to invoke their own LLM
-func buildSemanticSummaryPrompt(tableName string, tableInfo *db.TableInfo, sampleData []map[string]any) string {
-	sampleJSON, _ := json.MarshalIndent(sampleData, "", "  ")
-
-	return fmt.Sprintf(`# Task: Generate a Semantic Summary of Database Table
-
-You are analyzing a database table named "%s" to provide business-meaningful insights.
-
-## Table Schema:
-- Table Name: %s
-- Column Count: %d
-- Columns:
-%s
-
-## Sample Data (%d rows):
-%s
-
-## Your Task:
-Please analyze the table schema and sample data, then provide:
-
-1. **Business Purpose**: What is this table likely used for in the business context?
-2. **Data Patterns**: What patterns or trends do you observe in the sample data?
-3. **Key Insights**: What are the most important characteristics of this data?
-4. **Data Quality**: Are there any potential data quality issues visible in the sample?
-5. **Recommendations**: Any suggestions for data usage or further analysis?
-
-Please provide your response in a clear, structured format.`,
-		tableName,
-		tableInfo.TableName,
-		len(tableInfo.Columns),
-		formatColumnsForPrompt(tableInfo.Columns),
-		len(sampleData),
-		sampleJSON,
-	)
+// recentColumn picks the timestamp column that best orders rows by recency:
+// a well-known name first, then any date or timestamp column
+func recentColumn(info *db.TableInfo) string {
+	var candidates []string
+	for _, col := range info.Columns {
+		t := strings.ToLower(col.DataType)
+		if strings.HasPrefix(t, "date") || strings.HasPrefix(t, "timestamp") {
+			candidates = append(candidates, col.Name)
+		}
+	}
+	for _, name := range recentColumnNames {
+		for _, candidate := range candidates {
+			if strings.EqualFold(candidate, name) {
+				return candidate
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
 }
 
-// formatColumnsForPrompt formats column information for the LLM prompt
-func formatColumnsForPrompt(columns []db.ColumnInfo) string {
-	result := ""
-	for _, col := range columns {
-		nullable := "NOT NULL"
-		if col.IsNullable {
-			nullable = "NULL"
+// informativeColumns ranks the columns of a table by how much they tell about
+// its data and returns the top limit in table order, with the names of the rest.
+// Keys, indexed and documented columns rank higher; columns that are empty or
+// constant in the sample, and binary or document columns, rank lower.
+func informativeColumns(info *db.TableInfo, sample []map[string]any, limit int) ([]db.ColumnInfo, []string) {
+	indexed := make(map[string]bool)
+	for _, index := range info.Indexes {
+		for _, column := range index.Columns {
+			indexed[column] = true
 		}
-		primaryKey := ""
-		if col.IsPrimaryKey {
-			primaryKey = " [PRIMARY KEY]"
+	}
+
+	scores := make(map[string]float64, len(info.Columns))
+	for _, col := range info.Columns {
+		score := 0.5
+		if len(sample) > 0 {
+			nonNull := 0
+			distinct := make(map[string]bool)
+			for _, row := range sample {
+				if v := row[col.Name]; v != nil {
+					nonNull++
+					distinct[fmt.Sprint(v)] = true
+				}
+			}
+			score = float64(nonNull) / float64(len(sample))
+			if len(sample) > 1 && len(distinct) <= 1 {
+				score *= 0.25
+			}
+		}
+		switch {
+		case col.IsPrimaryKey:
+			score += 2
+		case indexed[col.Name]:
+			score += 0.5
+		}
+		if col.Description != "" {
+			score += 0.5
+		}
+		if db.ColumnKind(col.DataType) == db.ColumnKindOther {
+			score *= 0.5
+		}
+		scores[col.Name] = score
+	}
+
+	ranked := slices.Clone(info.Columns)
+	slices.SortStableFunc(ranked, func(a, b db.ColumnInfo) int {
+		return cmp.Compare(scores[b.Name], scores[a.Name])
+	})
+	keep := make(map[string]bool, limit)
+	for _, col := range ranked[:limit] {
+		keep[col.Name] = true
+	}
+
+	kept := make([]db.ColumnInfo, 0, limit)
+	var omitted []string
+	for _, col := range info.Columns {
+		if keep[col.Name] {
+			kept = append(kept, col)
+		} else {
+			omitted = append(omitted, col.Name)
 		}
-		result += fmt.Sprintf("  - %s (%s, %s)%s\n", col.Name, col.DataType, nullable, primaryKey)
 	}
-	return result
+	return kept, omitted
 }
@@ -0,0 +1,232 @@
+package insights
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+)
+
+// Summary sources
+const (
+	SummarySourceSampling = "sampling"
+	SummarySourceCache    = "cache"
+	SummarySourceTemplate = "template"
+)
+
+// defaultSummaryMaxTokens applies when no token limit is configured
+const defaultSummaryMaxTokens = 1024
+
+// summarySystemPrompt frames every sampling request
+const summarySystemPrompt = "You are a senior data analyst. Answer concisely and only from the schema and data provided."
+
+// Sampler requests an LLM completion from the connected client.
+// *server.MCPServer implements it.
+type Sampler interface {
+	RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+}
+
+// Summary is a summary generated through client sampling. When the client
+// cannot sample, only the rendered prompt is returned for it to run itself.
+type Summary struct {
+	Text        string `json:"text,omitempty"`
+	Source      string `json:"source"`
+	Model       string `json:"model,omitempty"`
+	SchemaHash  string `json:"schema_hash"`
+	GeneratedAt string `json:"generated_at,omitempty"`
+	Prompt      string `json:"prompt,omitempty"`
+}
+
+// Summarizer produces summaries with the client's sampling capability and
+// caches them in Redis keyed by a hash of the summarized schema
+type Summarizer struct {
+	sampler      Sampler
+	templates    *prompts.Templates
+	redisClients map[string]*cache.RedisClient
+	config       config.SummarizationConfig
+	logger       *slog.Logger
+}
+
+// NewSummarizer creates a new summarizer
+func NewSummarizer(
+	sampler Sampler,
+	templates *prompts.Templates,
+	redisClients map[string]*cache.RedisClient,
+	cfg config.SummarizationConfig,
+	logger *slog.Logger,
+) *Summarizer {
+	return &Summarizer{
+		sampler:      sampler,
+		templates:    templates,
+		redisClients: redisClients,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// Enabled reports whether summaries are generated by default
+func (s *Summarizer) Enabled() bool {
+	return s != nil && s.config.Enabled
+}
+
+// Summarize returns a cached summary for the schema, samples a new one from
+// the client, or falls back to the rendered prompt template. subject names
+// what is summarized within the database (a table, or "*" for all tables)
+// and schema is hashed into the cache key.
+func (s *Summarizer) Summarize(ctx context.Context, promptName, dbName, subject string, schema any, data prompts.Data) (*Summary, error) {
+	schemaHash, err := SchemaHash(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached := s.Cached(ctx, promptName, dbName, subject, schemaHash); cached != nil {
+		return cached, nil
+	}
+
+	prompt, err := s.templates.Render(promptName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ClientSupportsSampling(ctx) {
+		return &Summary{Source: SummarySourceTemplate, SchemaHash: schemaHash, Prompt: prompt}, nil
+	}
+
+	maxTokens := s.config.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultSummaryMaxTokens
+	}
+
+	result, err := s.sampler.RequestSampling(ctx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent(prompt)},
+			},
+			SystemPrompt:   summarySystemPrompt,
+			IncludeContext: "none",
+			MaxTokens:      maxTokens,
+		},
+	})
+	if err != nil {
+		// A declined or failed sampling request still leaves the caller the prompt
+		s.logger.WarnContext(ctx, "Sampling request failed, returning prompt template", "prompt", promptName, "error", err)
+		return &Summary{Source: SummarySourceTemplate, SchemaHash: schemaHash, Prompt: prompt}, nil
+	}
+
+	summary := &Summary{
+		Text:        samplingText(result),
+		Source:      SummarySourceSampling,
+		Model:       result.Model,
+		SchemaHash:  schemaHash,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	s.store(ctx, promptName, dbName, subject, summary)
+	return summary, nil
+}
+
+// Cached returns the cached summary for a schema hash, or nil
+func (s *Summarizer) Cached(ctx context.Context, promptName, dbName, subject, schemaHash string) *Summary {
+	if s == nil || len(s.redisClients) == 0 {
+		return nil
+	}
+
+	key := summaryCacheKey(promptName, dbName, subject, schemaHash)
+	for _, redisClient := range s.redisClients {
+		cached, err := redisClient.Get(ctx, key)
+		if err != nil || cached == "" {
+			return nil
+		}
+		var summary Summary
+		if err := json.Unmarshal([]byte(cached), &summary); err != nil {
+			s.logger.WarnContext(ctx, "Ignoring unreadable summary cache entry", "key", key)
+			return nil
+		}
+		summary.Source = SummarySourceCache
+		return &summary
+	}
+	return nil
+}
+
+// store caches a sampled summary
+func (s *Summarizer) store(ctx context.Context, promptName, dbName, subject string, summary *Summary) {
+	if len(s.redisClients) == 0 || summary.Text == "" {
+		return
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return
+	}
+	key := summaryCacheKey(promptName, dbName, subject, summary.SchemaHash)
+	for _, redisClient := range s.redisClients {
+		ttl := time.Duration(s.config.CacheTTL) * time.Second
+		if err := redisClient.Set(ctx, key, string(data), ttl); err != nil {
+			s.logger.WarnContext(ctx, "Failed to cache summary", "key", key, "error", err)
+		}
+		break
+	}
+}
+
+// SchemaHash returns a short stable hash of a schema value
+func SchemaHash(schema any) (string, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash schema: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// tableFingerprint keeps the structural part of a table schema, so that row
+// count estimates do not invalidate cached summaries
+func tableFingerprint(info *db.TableInfo) any {
+	return struct {
+		Table   string          `json:"table"`
+		Columns []db.ColumnInfo `json:"columns"`
+		Indexes []db.IndexInfo  `json:"indexes"`
+	}{info.TableName, info.Columns, info.Indexes}
+}
+
+// ClientSupportsSampling reports whether the client of the current request
+// declared the sampling capability
+func ClientSupportsSampling(ctx context.Context) bool {
+	if server.InProcessSamplingHandlerFromContext(ctx) != nil {
+		return true
+	}
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return false
+	}
+	return session.GetClientCapabilities().Sampling != nil
+}
+
+// summaryCacheKey builds the Redis key of a summary
+func summaryCacheKey(promptName, dbName, subject, schemaHash string) string {
+	return fmt.Sprintf("summary:%s:%s:%s:%s", promptName, dbName, subject, schemaHash)
+}
+
+// samplingText extracts the text of a sampling result
+func samplingText(result *mcp.CreateMessageResult) string {
+	switch content := result.Content.(type) {
+	case mcp.TextContent:
+		return content.Text
+	case *mcp.TextContent:
+		return content.Text
+	case map[string]any:
+		if text, ok := content["text"].(string); ok {
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,478 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// Gap filling modes for buckets without rows
+const (
+	FillZero = "zero"
+	FillNull = "null"
+	FillNone = "none"
+)
+
+// lastPattern matches relative ranges such as 90d or 24h
+var lastPattern = regexp.MustCompile(`^(\d+)\s*(m|h|d|w|mo)$`)
+
+// TimeseriesHandler computes metrics over time buckets
+type TimeseriesHandler struct {
+	repositories map[string]db.Repository
+	config       config.AnalyticsConfig
+	logger       *slog.Logger
+}
+
+// NewTimeseriesHandler creates a new time-series handler
+func NewTimeseriesHandler(
+	repos map[string]db.Repository,
+	cfg config.AnalyticsConfig,
+	logger *slog.Logger,
+) *TimeseriesHandler {
+	if cfg.MaxResultRows <= 0 {
+		cfg.MaxResultRows = defaultMaxResultRows
+	}
+	return &TimeseriesHandler{
+		repositories: repos,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// TimeseriesResult is the structured result of the timeseries tool
+type TimeseriesResult struct {
+	Database    string       `json:"database"`
+	Table       string       `json:"table"`
+	TimeColumn  string       `json:"time_column"`
+	Bucket      string       `json:"bucket"`
+	Timezone    string       `json:"timezone"`
+	From        string       `json:"from,omitempty"` // inclusive
+	To          string       `json:"to,omitempty"`   // exclusive
+	SplitBy     string       `json:"split_by,omitempty"`
+	Metrics     []db.Metric  `json:"metrics"`
+	Fill        string       `json:"fill"`
+	BucketCount int          `json:"bucket_count"`
+	Series      []TimeSeries `json:"series"`
+	Truncated   bool         `json:"truncated,omitempty"` // rows beyond max_result_rows were dropped and gaps left unfilled
+	Query       string       `json:"query"`
+}
+
+// TimeSeries is the metrics of one split value over time. Each point holds
+// the bucket start (RFC 3339, in the requested time zone) and the metrics.
+type TimeSeries struct {
+	Key    any              `json:"key,omitempty"` // split_by value
+	Points []map[string]any `json:"points"`
+}
+
+// HandleTimeseries groups table rows into time buckets and computes metrics per bucket
+// CRITICAL: Uses parameterized queries to prevent SQL injection
+func (h *TimeseriesHandler) HandleTimeseries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.InfoContext(ctx, "Handling timeseries tool request")
+
+	dbName, err := request.RequireString("database")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	tableName, err := request.RequireString("table")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	timeColumn, err := request.RequireString("time_column")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	bucket, err := request.RequireString("bucket")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	bucket = strings.ToLower(bucket)
+	if !slices.Contains(db.TimeBuckets, bucket) {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid bucket: %s", bucket).
+			WithHint("Must be one of: " + strings.Join(db.TimeBuckets, ", "))), nil
+	}
+	splitBy := request.GetString("split_by", "")
+
+	fill := request.GetString("fill", FillZero)
+	if fill != FillZero && fill != FillNull && fill != FillNone {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid fill: %s", fill).
+			WithHint("Must be one of: zero, null, none")), nil
+	}
+
+	timezone := request.GetString("timezone", "UTC")
+	loc, err := db.LoadTimezone(timezone)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid timezone: %s", timezone).
+			WithTarget(timezone).
+			WithHint("Use an IANA name such as Asia/Shanghai, or a UTC offset such as +08:00.")), nil
+	}
+
+	from, to, err := timeRange(request, bucket, loc)
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	var aggregates []db.Metric
+	if err := toolargs.Decode(request, "metrics", &aggregates); err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if len(aggregates) == 0 {
+		aggregates = []db.Metric{{Fn: "COUNT"}}
+	}
+	for i, metric := range aggregates {
+		if aggregates[i], err = db.NormalizeMetric(metric); err != nil {
+			return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "invalid metric").
+				WithHint("fn must be one of: " + strings.Join(db.AggregateFuncs, ", "))), nil
+		}
+	}
+
+	// Validate identifiers before they reach the query builder
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	if err := db.ValidateIdentifier("time column", timeColumn); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	if splitBy != "" {
+		if err := db.ValidateIdentifier("split_by column", splitBy); err != nil {
+			return toolerrors.Result(err), nil
+		}
+	}
+
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return toolerrors.Result(databaseNotFoundError(dbName, h.repositories)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	conditions, err := toolargs.Object(request, "conditions")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.ExecutionTimeout)*time.Second)
+	defer cancel()
+
+	// The column type decides how times convert to the requested time zone
+	kind, err := timeColumnKind(queryCtx, repo, tableName, timeColumn)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	spec := db.TimeseriesSpec{
+		Table:      db.FullTableName(repo, tableName),
+		TimeColumn: timeColumn,
+		TimeKind:   kind,
+		Bucket:     bucket,
+		Timezone:   timezone,
+		Metrics:    aggregates,
+		SplitBy:    splitBy,
+		Conditions: conditions,
+		Limit:      h.config.MaxResultRows + 1,
+	}
+	result := &TimeseriesResult{
+		Database:   dbName,
+		Table:      tableName,
+		TimeColumn: timeColumn,
+		Bucket:     bucket,
+		Timezone:   timezone,
+		SplitBy:    splitBy,
+		Metrics:    aggregates,
+		Fill:       fill,
+		Series:     []TimeSeries{},
+	}
+	if !from.IsZero() {
+		spec.From = db.TimeBoundValue(kind, from, loc)
+		result.From = from.In(loc).Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		spec.To = db.TimeBoundValue(kind, to, loc)
+		result.To = to.In(loc).Format(time.RFC3339)
+	}
+
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	built := db.StartBuildSpan(ctx, "timeseries")
+	query, params, err := qb.BuildTimeseriesQuery(spec)
+	built(query, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
+	result.Query = query
+
+	rows, err := repo.Query(queryCtx, query, params...)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Timeseries query failed", "error", err, "query", query)
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	defer rows.Close()
+
+	// Collect the points of each series in bucket order
+	type series struct {
+		key    any
+		points map[time.Time]map[string]any
+	}
+	var order []string
+	byKey := make(map[string]*series)
+	seen := make(map[time.Time]bool)
+	var minBucket, maxBucket time.Time
+	count := 0
+	for rows.Next() {
+		values := make([]any, 1+len(aggregates))
+		if splitBy != "" {
+			values = append(values, nil)
+		}
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeQueryFailed, err, "failed to read timeseries row")), nil
+		}
+		if count++; count > h.config.MaxResultRows {
+			result.Truncated = true
+			break
+		}
+
+		wall, ok := wallTime(values[0])
+		if !ok {
+			// CONVERT_TZ returns NULL for zones MySQL does not know
+			return toolerrors.Result(toolerrors.Newf(toolerrors.CodeUnsupported, "the database could not convert times to timezone %s", timezone).
+				WithTarget(timezone).
+				WithHint("Load the MySQL time zone tables (mysql_tzinfo_to_sql) or pass a UTC offset such as +08:00.")), nil
+		}
+		seen[wall] = true
+		if minBucket.IsZero() || wall.Before(minBucket) {
+			minBucket = wall
+		}
+		if wall.After(maxBucket) {
+			maxBucket = wall
+		}
+
+		metricValues := values[1:]
+		var key any
+		if splitBy != "" {
+			key, metricValues = profileValue(values[1]), values[2:]
+		}
+		id := fmt.Sprint(key)
+		s, ok := byKey[id]
+		if !ok {
+			s = &series{key: key, points: make(map[time.Time]map[string]any)}
+			byKey[id] = s
+			order = append(order, id)
+		}
+		point := make(map[string]any, len(aggregates))
+		for i, metric := range aggregates {
+			point[metric.Alias] = profileValue(metricValues[i])
+		}
+		s.points[wall] = point
+	}
+	if err := rows.Err(); err != nil {
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
+	}
+	metrics.RecordRows(ctx, min(count, h.config.MaxResultRows))
+
+	// The requested range bounds the buckets; open ends stop at the data
+	start, end := minBucket, maxBucket
+	if !from.IsZero() {
+		start = truncateWall(toWall(from, loc), bucket)
+	}
+	if !to.IsZero() {
+		end = truncateWall(toWall(to.Add(-time.Nanosecond), loc), bucket)
+	}
+
+	var buckets []time.Time
+	if count > 0 || !from.IsZero() {
+		if fill == FillNone || result.Truncated || end.Before(start) {
+			for b := range seen {
+				buckets = append(buckets, b)
+			}
+			slices.SortFunc(buckets, time.Time.Compare)
+		} else {
+			for b := start; !b.After(end); b = nextBucket(b, bucket) {
+				if len(buckets) == h.config.MaxResultRows {
+					return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+						"the range spans more than %d %s buckets", h.config.MaxResultRows, bucket).
+						WithHint("Use a coarser bucket or a shorter range.")), nil
+				}
+				buckets = append(buckets, b)
+			}
+		}
+	}
+	result.BucketCount = len(buckets)
+
+	if len(order) == 0 && splitBy == "" && len(buckets) > 0 {
+		order = append(order, "")
+		byKey[""] = &series{points: map[time.Time]map[string]any{}}
+	}
+	for _, id := range order {
+		s := byKey[id]
+		out := TimeSeries{Key: s.key, Points: make([]map[string]any, 0, len(buckets))}
+		for _, b := range buckets {
+			point, ok := s.points[b]
+			if !ok {
+				if fill == FillNone {
+					continue
+				}
+				point = make(map[string]any, len(aggregates))
+				for _, metric := range aggregates {
+					point[metric.Alias] = nil
+					if fill == FillZero {
+						point[metric.Alias] = 0
+					}
+				}
+			}
+			point["bucket"] = fromWall(b, loc).Format(time.RFC3339)
+			out.Points = append(out.Points, point)
+		}
+		result.Series = append(result.Series, out)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal timeseries response", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal response")), nil
+	}
+	return mcp.NewToolResultStructured(*result, string(resultJSON)), nil
+}
+
+// timeRange reads the from/to or last arguments. Zero times leave the range
+// open. Ranges given by last start at a bucket boundary, so the first bucket
+// is complete.
+func timeRange(request mcp.CallToolRequest, bucket string, loc *time.Location) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if s := request.GetString("from", ""); s != "" {
+		if from, err = parseTime(s, loc); err != nil {
+			return from, to, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if s := request.GetString("to", ""); s != "" {
+		if to, err = parseTime(s, loc); err != nil {
+			return from, to, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	if last := request.GetString("last", ""); last != "" {
+		if !from.IsZero() {
+			return from, to, fmt.Errorf("pass either from or last, not both")
+		}
+		match := lastPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(last)))
+		if match == nil {
+			return from, to, fmt.Errorf("invalid last: %q (expected a count and unit such as 30m, 24h, 90d, 12w or 6mo)", last)
+		}
+		n, _ := strconv.Atoi(match[1])
+		end := to
+		if end.IsZero() {
+			end = time.Now()
+		}
+		switch match[2] {
+		case "m":
+			from = end.Add(-time.Duration(n) * time.Minute)
+		case "h":
+			from = end.Add(-time.Duration(n) * time.Hour)
+		case "d":
+			from = end.AddDate(0, 0, -n)
+		case "w":
+			from = end.AddDate(0, 0, -7*n)
+		case "mo":
+			from = end.AddDate(0, -n, 0)
+		}
+		from = fromWall(truncateWall(toWall(from, loc), bucket), loc)
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+// parseTime parses RFC 3339 times, or date and date-time values in loc
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.DateTime, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time or a YYYY-MM-DD [HH:MM:SS] value", s)
+}
+
+// Buckets are handled as wall-clock times in UTC, so that stepping through
+// them follows the calendar of the requested time zone across DST changes.
+
+// toWall returns the wall-clock time of t in loc
+func toWall(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// fromWall returns the time in loc with the wall clock of w
+func fromWall(w time.Time, loc *time.Location) time.Time {
+	return time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), 0, loc)
+}
+
+// wallTime reads a bucket start returned by the database
+func wallTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), true
+	case []byte:
+		return wallTime(string(t))
+	case string:
+		w, err := time.Parse(time.DateTime, t)
+		return w, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// truncateWall returns the start of the bucket holding w
+func truncateWall(w time.Time, bucket string) time.Time {
+	switch bucket {
+	case db.BucketMinute:
+		return w.Truncate(time.Minute)
+	case db.BucketHour:
+		return w.Truncate(time.Hour)
+	case db.BucketDay:
+		return time.Date(w.Year(), w.Month(), w.Day(), 0, 0, 0, 0, time.UTC)
+	case db.BucketWeek:
+		day := time.Date(w.Year(), w.Month(), w.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(w.Year(), w.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextBucket returns the start of the bucket after b
+func nextBucket(b time.Time, bucket string) time.Time {
+	switch bucket {
+	case db.BucketMinute:
+		return b.Add(time.Minute)
+	case db.BucketHour:
+		return b.Add(time.Hour)
+	case db.BucketDay:
+		return b.AddDate(0, 0, 1)
+	case db.BucketWeek:
+		return b.AddDate(0, 0, 7)
+	default:
+		return b.AddDate(0, 1, 0)
+	}
+}
@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+const namespace = "mcp_localbridge"
+
+// Tool call metrics shared by every registry created with NewRegistry
+var (
+	// ToolCallDuration observes tool handler latency by tool and database
+	ToolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Latency of MCP tool calls by tool and database.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"tool", "database"})
+
+	// ToolErrors counts failed tool calls by tool and error class
+	ToolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "Failed MCP tool calls by tool and error class.",
+	}, []string{"tool", "class"})
+
+	// RowsReturned counts rows read from databases by tool and database
+	RowsReturned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_returned_total",
+		Help:      "Rows returned from database queries by tool and database.",
+	}, []string{"tool", "database"})
+
+	// ActiveSessions tracks connected client sessions by transport
+	ActiveSessions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Currently connected MCP client sessions by transport.",
+	}, []string{"transport"})
+)
+
+// Error classes used for the ToolErrors class label
+const (
+	ErrorClassToolError = "tool_error"
+	ErrorClassTimeout   = "timeout"
+	ErrorClassCanceled  = "canceled"
+	ErrorClassInternal  = "internal"
+	ErrorClassPanic     = "panic"
+)
+
+// UnknownDatabase is the database label of tool calls naming a database that
+// is not configured, so made-up names do not create new series
+const UnknownDatabase = "unknown"
+
+// NewRegistry creates a registry with tool metrics, runtime metrics and
+// connection pool collectors for the given repositories and Redis clients
+func NewRegistry(repos map[string]db.Repository, redisClients map[string]*cache.RedisClient) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ToolCallDuration,
+		ToolErrors,
+		RowsReturned,
+		ActiveSessions,
+		newDBStatsCollector(repos),
+		newRedisPoolCollector(redisClients),
+	)
+	return registry
+}
+
+// CallStats accumulates per-call statistics reported by tool handlers
+type CallStats struct {
+	rows       atomic.Int64
+	errorClass atomic.Value
+}
+
+// Rows returns the number of rows recorded for the call
+func (s *CallStats) Rows() int64 {
+	return s.rows.Load()
+}
+
+// ErrorClass returns the error class recorded for the call, if any
+func (s *CallStats) ErrorClass() string {
+	class, _ := s.errorClass.Load().(string)
+	return class
+}
+
+type callStatsKey struct{}
+
+// WithCallStats attaches a fresh CallStats to the context
+func WithCallStats(ctx context.Context) (context.Context, *CallStats) {
+	stats := &CallStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+// RecordRows adds n returned rows to the CallStats carried by ctx, if any
+func RecordRows(ctx context.Context, n int) {
+	if stats, ok := ctx.Value(callStatsKey{}).(*CallStats); ok {
+		stats.rows.Add(int64(n))
+	}
+}
+
+// RecordErrorClass sets the error class of the call carried by ctx, if any.
+// Middlewares use it when the outcome cannot be inferred from the result alone.
+func RecordErrorClass(ctx context.Context, class string) {
+	if stats, ok := ctx.Value(callStatsKey{}).(*CallStats); ok {
+		stats.errorClass.Store(class)
+	}
+}
+
+// ObserveToolCall records latency, rows and error class for a finished tool call.
+// errorClass is empty for successful calls.
+func ObserveToolCall(tool, database string, duration time.Duration, rows int64, errorClass string) {
+	ToolCallDuration.WithLabelValues(tool, database).Observe(duration.Seconds())
+	if rows > 0 {
+		RowsReturned.WithLabelValues(tool, database).Add(float64(rows))
+	}
+	if errorClass != "" {
+		ToolErrors.WithLabelValues(tool, errorClass).Inc()
+	}
+}
+
+// dbStatsCollector exports sql.DBStats for every repository
+type dbStatsCollector struct {
+	repositories map[string]db.Repository
+
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newDBStatsCollector(repos map[string]db.Repository) *dbStatsCollector {
+	labels := []string{"database", "driver"}
+	return &dbStatsCollector{
+		repositories: repos,
+		maxOpen:      prometheus.NewDesc(namespace+"_db_max_open_connections", "Maximum number of open connections to the database.", labels, nil),
+		open:         prometheus.NewDesc(namespace+"_db_open_connections", "Number of established connections, both in use and idle.", labels, nil),
+		inUse:        prometheus.NewDesc(namespace+"_db_in_use_connections", "Number of connections currently in use.", labels, nil),
+		idle:         prometheus.NewDesc(namespace+"_db_idle_connections", "Number of idle connections.", labels, nil),
+		waitCount:    prometheus.NewDesc(namespace+"_db_wait_count_total", "Total number of connections waited for.", labels, nil),
+		waitDuration: prometheus.NewDesc(namespace+"_db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+// Collect implements prometheus.Collector
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, repo := range c.repositories {
+		stats := repo.Stats()
+		driver := repo.GetDriver()
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name, driver)
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections), name, driver)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), name, driver)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), name, driver)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), name, driver)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), name, driver)
+	}
+}
+
+// redisPoolCollector exports go-redis pool statistics for every Redis client
+type redisPoolCollector struct {
+	clients map[string]*cache.RedisClient
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newRedisPoolCollector(clients map[string]*cache.RedisClient) *redisPoolCollector {
+	labels := []string{"redis"}
+	return &redisPoolCollector{
+		clients:    clients,
+		hits:       prometheus.NewDesc(namespace+"_redis_pool_hits_total", "Number of times a free connection was found in the pool.", labels, nil),
+		misses:     prometheus.NewDesc(namespace+"_redis_pool_misses_total", "Number of times a free connection was not found in the pool.", labels, nil),
+		timeouts:   prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "Number of times a wait timeout occurred.", labels, nil),
+		totalConns: prometheus.NewDesc(namespace+"_redis_pool_total_connections", "Number of total connections in the pool.", labels, nil),
+		idleConns:  prometheus.NewDesc(namespace+"_redis_pool_idle_connections", "Number of idle connections in the pool.", labels, nil),
+		staleConns: prometheus.NewDesc(namespace+"_redis_pool_stale_connections_total", "Number of stale connections removed from the pool.", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect implements prometheus.Collector
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, client := range c.clients {
+		stats := client.GetClient().PoolStats()
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts), name)
+		ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns), name)
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+)
+
+// Server exposes a Prometheus registry over HTTP
+type Server struct {
+	httpServer *http.Server
+	config     config.MetricsConfig
+	logger     *slog.Logger
+}
+
+// NewServer creates a new metrics HTTP server for the given registry
+func NewServer(registry *prometheus.Registry, cfg config.MetricsConfig, logger *slog.Logger) *Server {
+	if cfg.Path == "" {
+		cfg.Path = "/metrics"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:    cfg.Address(),
+			Handler: mux,
+		},
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Start starts serving metrics. It blocks until the server is stopped.
+func (s *Server) Start() error {
+	s.logger.Info("Starting metrics server", "address", s.config.Address(), "path", s.config.Path)
+
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
+
+// Stop gracefully shuts down the metrics server
+func (s *Server) Stop(ctx context.Context) error {
+	s.logger.Info("Stopping metrics server")
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown metrics server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/metrics"
+)
+
+// Metrics records latency, returned rows and errors for every tool call.
+// The database label is one of the configured databases, or
+// metrics.UnknownDatabase for any other name a client passes.
+func Metrics(databases []string) ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, stats := metrics.WithCallStats(ctx)
+			start := time.Now()
+
+			result, err := next(ctx, request)
+
+			metrics.ObserveToolCall(
+				request.Params.Name,
+				databaseLabel(request.GetString("database", ""), databases),
+				time.Since(start),
+				stats.Rows(),
+				classifyToolError(ctx, stats, result, err),
+			)
+			return result, err
+		}
+	}
+}
+
+// databaseLabel returns the metrics label of a database argument
+func databaseLabel(name string, databases []string) string {
+	if name == "" || slices.Contains(databases, name) {
+		return name
+	}
+	return metrics.UnknownDatabase
+}
+
+// classifyToolError maps a tool call outcome to a metrics error class
+func classifyToolError(ctx context.Context, stats *metrics.CallStats, result *mcp.CallToolResult, err error) string {
+	switch {
+	case stats.ErrorClass() != "":
+		return stats.ErrorClass()
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return metrics.ErrorClassTimeout
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return metrics.ErrorClassCanceled
+	case err != nil:
+		return metrics.ErrorClassInternal
+	case result != nil && result.IsError:
+		return metrics.ErrorClassToolError
+	default:
+		return ""
+	}
+}
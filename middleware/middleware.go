@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolMiddleware wraps a tool handler with cross-cutting behaviour such as
+// deadlines, recovery, logging, metrics, auth or caching
+type ToolMiddleware func(next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+// Chain composes middlewares into one. The first middleware is the outermost,
+// so Chain(a, b)(h) runs a, then b, then h.
+func Chain(middlewares ...ToolMiddleware) ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID assigned by the RequestID middleware
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// RequestID assigns a unique ID to every tool call
+func RequestID() ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = context.WithValue(ctx, requestIDKey{}, uuid.NewString())
+			return next(ctx, request)
+		}
+	}
+}
+
+// Logging logs the start and outcome of every tool call with its duration
+func Logging(logger *slog.Logger) ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			attrs := []any{
+				"tool", request.Params.Name,
+				"database", request.GetString("database", ""),
+				"request_id", RequestIDFromContext(ctx),
+			}
+			logger.DebugContext(ctx, "Tool call started", attrs...)
+			start := time.Now()
+
+			result, err := next(ctx, request)
+
+			attrs = append(attrs, "duration_ms", time.Since(start).Milliseconds())
+			switch {
+			case err != nil:
+				logger.ErrorContext(ctx, "Tool call failed", append(attrs, "error", err)...)
+			case result != nil && result.IsError:
+				logger.WarnContext(ctx, "Tool call returned an error result", attrs...)
+			default:
+				logger.InfoContext(ctx, "Tool call completed", attrs...)
+			}
+			return result, err
+		}
+	}
+}
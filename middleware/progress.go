@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/progress"
+)
+
+// Progress attaches a progress reporter to the context when the caller
+// supplied a progress token, so handlers can call progress.Report
+func Progress() ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = progress.WithReporter(ctx, progress.NewReporter(ctx, request))
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// Recovery turns panics in tool handlers into error results instead of
+// letting them take down the session
+func Recovery(logger *slog.Logger) ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.ErrorContext(ctx, "Recovered from panic in tool handler",
+						"tool", request.Params.Name,
+						"request_id", RequestIDFromContext(ctx),
+						"panic", r,
+						"stack", string(debug.Stack()))
+					metrics.RecordErrorClass(ctx, metrics.ErrorClassPanic)
+					result = toolerrors.Result(toolerrors.Newf(toolerrors.CodeInternal,
+						"internal error while executing tool %s (request_id: %s)", request.Params.Name, RequestIDFromContext(ctx)))
+					err = nil
+				}
+			}()
+			return next(ctx, request)
+		}
+	}
+}
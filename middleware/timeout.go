@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+type handlerResult struct {
+	result *mcp.CallToolResult
+	err    error
+}
+
+// Timeout enforces a deadline on every tool call. The handler context is
+// cancelled when the deadline passes, and the client gets an error result
+// even if the handler does not return promptly.
+func Timeout(timeout time.Duration) ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan handlerResult, 1)
+			go func() {
+				result, err := next(ctx, request)
+				done <- handlerResult{result: result, err: err}
+			}()
+
+			select {
+			case res := <-done:
+				if ctx.Err() == context.DeadlineExceeded {
+					metrics.RecordErrorClass(ctx, metrics.ErrorClassTimeout)
+				}
+				return res.result, res.err
+			case <-ctx.Done():
+				if ctx.Err() != context.DeadlineExceeded {
+					// Cancelled by the caller; nobody is waiting for the result
+					return nil, ctx.Err()
+				}
+				metrics.RecordErrorClass(ctx, metrics.ErrorClassTimeout)
+				return toolerrors.Result(toolerrors.Newf(toolerrors.CodeQueryTimeout,
+					"tool %s exceeded the request timeout of %s", request.Params.Name, timeout).
+					WithHint("Narrow the request or raise server.request_timeout.")), nil
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// Tracing starts the root span for every tool call so that SQL,
+// row decoding and Redis spans created by the handler become its children
+func Tracing() ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, span := tracing.Start(ctx, fmt.Sprintf("tools/call %s", request.Params.Name),
+				tracing.AttrToolName.String(request.Params.Name),
+				tracing.AttrDatabase.String(request.GetString("database", "")),
+				tracing.AttrRequestID.String(RequestIDFromContext(ctx)),
+			)
+
+			result, err := next(ctx, request)
+
+			if err == nil && result != nil && result.IsError {
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+			tracing.End(span, err)
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package progress
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// minInterval throttles notifications for handlers that report per item
+const minInterval = 250 * time.Millisecond
+
+// contextKey is the context key type for the reporter
+type contextKey struct{}
+
+// Reporter sends notifications/progress for a request whose caller supplied
+// a progress token
+type Reporter struct {
+	server *server.MCPServer
+	token  mcp.ProgressToken
+
+	mu           sync.Mutex
+	lastSent     time.Time
+	lastProgress int
+}
+
+// NewReporter returns a reporter for the request, or nil when the caller did
+// not ask for progress or no MCP server is attached to the context
+func NewReporter(ctx context.Context, request mcp.CallToolRequest) *Reporter {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	return &Reporter{server: srv, token: request.Params.Meta.ProgressToken}
+}
+
+// WithReporter attaches a reporter to the context
+func WithReporter(ctx context.Context, reporter *Reporter) context.Context {
+	if reporter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, reporter)
+}
+
+// Report sends a progress notification through the reporter in ctx, if any.
+// Updates are throttled, except for the final one where progress == total,
+// and updates that arrive out of order from concurrent workers are dropped.
+func Report(ctx context.Context, progress, total int, message string) {
+	reporter, ok := ctx.Value(contextKey{}).(*Reporter)
+	if !ok {
+		return
+	}
+
+	reporter.mu.Lock()
+	now := time.Now()
+	sent := !reporter.lastSent.IsZero()
+	if (sent && progress <= reporter.lastProgress) || (progress < total && now.Sub(reporter.lastSent) < minInterval) {
+		reporter.mu.Unlock()
+		return
+	}
+	reporter.lastSent = now
+	reporter.lastProgress = progress
+	reporter.mu.Unlock()
+
+	params := map[string]any{
+		"progressToken": reporter.token,
+		"progress":      progress,
+		"total":         total,
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	// Progress is best effort, a closed session must not fail the tool call
+	_ = reporter.server.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), params)
+}
@@ -0,0 +1,177 @@
+package prompts
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// Prompt names
+const (
+	SummarizeTable        = "summarize_table"
+	ExplainDataModel      = "explain_data_model"
+	WriteQueryForQuestion = "write_query_for_question"
+	InvestigateAnomaly    = "investigate_anomaly"
+)
+
+// Names lists every prompt with a built-in template
+var Names = []string{SummarizeTable, ExplainDataModel, WriteQueryForQuestion, InvestigateAnomaly}
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// Data is the data available to prompt templates. Fields that do not apply
+// to a prompt are left empty.
+type Data struct {
+	Database      string
+	Table         string
+	Column        string
+	Question      string
+	Symptom       string
+	Schema        *db.TableInfo
+	Tables        []db.TableInfo
+	ForeignKeys   []db.ForeignKeyInfo
+	Relationships map[string][]db.ForeignKeyInfo
+	SampleData    []map[string]any
+}
+
+// Templates holds the parsed prompt bodies
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// Load parses the built-in prompt templates, replacing any that have an
+// override file configured
+func Load(cfg config.PromptsConfig) (*Templates, error) {
+	t := &Templates{templates: make(map[string]*template.Template, len(Names))}
+
+	for _, name := range Names {
+		body, err := defaultTemplates.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in prompt template %s: %w", name, err)
+		}
+		if t.templates[name], err = parse(name, string(body)); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, path := range cfg.Templates {
+		if _, ok := t.templates[name]; !ok {
+			return nil, fmt.Errorf("unknown prompt %q in prompt templates, expected one of: %s", name, strings.Join(Names, ", "))
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template for %s: %w", name, err)
+		}
+		if t.templates[name], err = parse(name, string(body)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Render executes the template of a prompt
+func (t *Templates) Render(name string, data Data) (string, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown prompt: %s", name)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// parse parses a prompt body with the shared template functions
+func parse(name, body string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// funcs are the helpers available to prompt templates
+var funcs = template.FuncMap{
+	"json":        toJSON,
+	"columns":     formatColumns,
+	"foreignKeys": formatForeignKeys,
+	"primaryKey":  primaryKey,
+}
+
+// toJSON renders a value as indented JSON
+func toJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<unencodable: %v>", err)
+	}
+	return string(data)
+}
+
+// formatColumns formats column information as a bullet list
+func formatColumns(columns []db.ColumnInfo) string {
+	var sb strings.Builder
+	for _, col := range columns {
+		nullable := "NOT NULL"
+		if col.IsNullable {
+			nullable = "NULL"
+		}
+		primary := ""
+		if col.IsPrimaryKey {
+			primary = " [PRIMARY KEY]"
+		}
+		fmt.Fprintf(&sb, "  - %s (%s, %s)%s\n", col.Name, col.DataType, nullable, primary)
+	}
+	return sb.String()
+}
+
+// formatForeignKeys formats foreign keys as a bullet list, ordered by source
+func formatForeignKeys(fks []db.ForeignKeyInfo) string {
+	sorted := append([]db.ForeignKeyInfo(nil), fks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SourceTable < sorted[j].SourceTable
+	})
+
+	var sb strings.Builder
+	for _, fk := range sorted {
+		fmt.Fprintf(&sb, "  - %s.%s -> %s.%s", fk.SourceTable, keyColumns(fk.SourceColumns, fk.SourceColumn),
+			fk.ReferencedTable, keyColumns(fk.ReferencedColumns, fk.ReferencedColumn))
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			fmt.Fprintf(&sb, " ON DELETE %s", fk.OnDelete)
+		}
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			fmt.Fprintf(&sb, " ON UPDATE %s", fk.OnUpdate)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// keyColumns renders the columns of a foreign key side, parenthesised when composite
+func keyColumns(columns []string, column string) string {
+	if len(columns) > 1 {
+		return "(" + strings.Join(columns, ", ") + ")"
+	}
+	return column
+}
+
+// primaryKey joins the primary key column names
+func primaryKey(columns []db.ColumnInfo) string {
+	var names []string
+	for _, col := range columns {
+		if col.IsPrimaryKey {
+			names = append(names, col.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
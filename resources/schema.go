@@ -0,0 +1,285 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// URI templates of the schema resources
+const (
+	TablesURITemplate      = "db://{database}/tables"
+	TableSchemaURITemplate = "db://{database}/table/{table}/schema"
+	ERDURITemplate         = "db://{database}/erd"
+)
+
+// TablesURI returns the URI of the table list resource of a database
+func TablesURI(dbName string) string {
+	return fmt.Sprintf("db://%s/tables", dbName)
+}
+
+// TableSchemaURI returns the URI of the schema resource of a table
+func TableSchemaURI(dbName, tableName string) string {
+	return fmt.Sprintf("db://%s/table/%s/schema", dbName, tableName)
+}
+
+// ERDURI returns the URI of the entity relationship resource of a database
+func ERDURI(dbName string) string {
+	return fmt.Sprintf("db://%s/erd", dbName)
+}
+
+// DatabaseURIPrefix returns the prefix shared by every resource of a database
+func DatabaseURIPrefix(dbName string) string {
+	return fmt.Sprintf("db://%s/", dbName)
+}
+
+// SchemaResourceHandler serves database schemas as MCP resources, backed by
+// the introspection and relationship insights
+type SchemaResourceHandler struct {
+	introspection *insights.IntrospectionHandler
+	relationship  *insights.RelationshipHandler
+	logger        *slog.Logger
+}
+
+// NewSchemaResourceHandler creates a new schema resource handler
+func NewSchemaResourceHandler(
+	introspection *insights.IntrospectionHandler,
+	relationship *insights.RelationshipHandler,
+	logger *slog.Logger,
+) *SchemaResourceHandler {
+	return &SchemaResourceHandler{
+		introspection: introspection,
+		relationship:  relationship,
+		logger:        logger,
+	}
+}
+
+// TableSummary is a table entry of the table list resource
+type TableSummary struct {
+	Name        string `json:"name"`
+	ColumnCount int    `json:"column_count"`
+	Description string `json:"description,omitempty"`
+}
+
+// TablesResource is the content of db://{database}/tables
+type TablesResource struct {
+	Database   string         `json:"database"`
+	TableCount int            `json:"table_count"`
+	Tables     []TableSummary `json:"tables"`
+	CachedAt   string         `json:"cached_at"`
+}
+
+// TableSchemaResource is the content of db://{database}/table/{table}/schema
+type TableSchemaResource struct {
+	Database    string              `json:"database"`
+	Table       db.TableInfo        `json:"table"`
+	ForeignKeys []db.ForeignKeyInfo `json:"foreign_keys"`
+	CachedAt    string              `json:"cached_at"`
+}
+
+// ERDResource is the content of db://{database}/erd
+type ERDResource struct {
+	Database          string                         `json:"database"`
+	Tables            []string                       `json:"tables"`
+	Relationships     map[string][]db.ForeignKeyInfo `json:"relationships"`
+	RelationshipCount int                            `json:"relationship_count"`
+	Mermaid           string                         `json:"mermaid"`
+}
+
+// HandleTables reads the table list of a database
+func (h *SchemaResourceHandler) HandleTables(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	dbName, err := templateArgument(request, "database")
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := h.introspection.Introspect(ctx, dbName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]TableSummary, 0, len(schema.Tables))
+	for _, table := range schema.Tables {
+		tables = append(tables, TableSummary{
+			Name:        table.TableName,
+			ColumnCount: len(table.Columns),
+			Description: table.Description,
+		})
+	}
+
+	return jsonContents(request.Params.URI, TablesResource{
+		Database:   dbName,
+		TableCount: len(tables),
+		Tables:     tables,
+		CachedAt:   schema.CachedAt,
+	})
+}
+
+// HandleTableSchema reads the columns, indexes and foreign keys of a table
+func (h *SchemaResourceHandler) HandleTableSchema(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	dbName, err := templateArgument(request, "database")
+	if err != nil {
+		return nil, err
+	}
+	tableName, err := templateArgument(request, "table")
+	if err != nil {
+		return nil, err
+	}
+
+	// Introspect the requested table only; its name is matched literally
+	schema, err := h.introspection.IntrospectTables(ctx, dbName, "", []string{literalPattern(tableName)}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var table *db.TableInfo
+	for i := range schema.Tables {
+		if schema.Tables[i].TableName == tableName {
+			table = &schema.Tables[i]
+		}
+	}
+	if table == nil {
+		for _, failed := range schema.FailedTables {
+			if failed.Table == tableName {
+				return nil, toolerrors.Newf(toolerrors.CodeInternal, "failed to introspect table '%s': %s", tableName, failed.Error).
+					WithTarget(tableName)
+			}
+		}
+		return nil, toolerrors.Newf(toolerrors.CodeUnknownTable, "table '%s' not found in database '%s'", tableName, dbName).
+			WithTarget(tableName).
+			WithHint(fmt.Sprintf("Read %s for the list of tables.", TablesURI(dbName)))
+	}
+
+	relationships, err := h.relationship.Relationships(ctx, dbName, "", tableName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys := relationships.Relationships[tableName]
+	if foreignKeys == nil {
+		foreignKeys = []db.ForeignKeyInfo{}
+	}
+
+	return jsonContents(request.Params.URI, TableSchemaResource{
+		Database:    dbName,
+		Table:       *table,
+		ForeignKeys: foreignKeys,
+		CachedAt:    schema.CachedAt,
+	})
+}
+
+// literalPattern escapes the glob metacharacters of a table name so that
+// introspection matches it exactly
+func literalPattern(name string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(name)
+}
+
+// HandleERD reads the relationship graph of a database, as JSON and as a
+// Mermaid erDiagram
+func (h *SchemaResourceHandler) HandleERD(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	dbName, err := templateArgument(request, "database")
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := h.relationship.Relationships(ctx, dbName, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	erd := ERDResource{
+		Database:          dbName,
+		Tables:            relatedTables(relationships.Relationships),
+		Relationships:     relationships.Relationships,
+		RelationshipCount: relationships.RelationshipCount,
+		Mermaid:           BuildMermaidERD(relationships.Relationships),
+	}
+	return jsonContents(request.Params.URI, erd)
+}
+
+// BuildMermaidERD renders a foreign key graph as a Mermaid erDiagram
+func BuildMermaidERD(graph map[string][]db.ForeignKeyInfo) string {
+	var sb strings.Builder
+	sb.WriteString("erDiagram\n")
+
+	sources := make([]string, 0, len(graph))
+	for source := range graph {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		for _, fk := range graph[source] {
+			fmt.Fprintf(&sb, "    %s }o--|| %s : \"%s -> %s\"\n",
+				mermaidEntity(fk.SourceTable), mermaidEntity(fk.ReferencedTable), fk.SourceColumn, fk.ReferencedColumn)
+		}
+	}
+	return sb.String()
+}
+
+// mermaidEntity quotes schema-qualified table names, which are not valid bare
+// entity names in Mermaid
+func mermaidEntity(table string) string {
+	if strings.Contains(table, ".") {
+		return `"` + table + `"`
+	}
+	return table
+}
+
+// relatedTables lists every table that takes part in a relationship
+func relatedTables(graph map[string][]db.ForeignKeyInfo) []string {
+	seen := make(map[string]bool)
+	for source, fks := range graph {
+		seen[source] = true
+		for _, fk := range fks {
+			seen[fk.ReferencedTable] = true
+		}
+	}
+
+	tables := make([]string, 0, len(seen))
+	for table := range seen {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// templateArgument extracts a URI template variable from a resource request
+func templateArgument(request mcp.ReadResourceRequest, name string) (string, error) {
+	var value string
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		value = v
+	case []string:
+		if len(v) > 0 {
+			value = v[0]
+		}
+	}
+	if value == "" {
+		return "", toolerrors.Newf(toolerrors.CodeInvalidArgument, "resource URI %s is missing %s", request.Params.URI, name)
+	}
+	return value, nil
+}
+
+// jsonContents encodes a resource as a single JSON text content
+func jsonContents(uri string, resource any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(resource, "", "  ")
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal resource")
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
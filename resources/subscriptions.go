@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Subscription is a client session subscribed to a resource URI
+type Subscription struct {
+	SessionID string
+	URI       string
+}
+
+// Subscriptions tracks resources/subscribe requests per client session.
+// The built-in transports do not keep subscriptions on the session, so the
+// server records them here from the subscribe and unsubscribe hooks.
+type Subscriptions struct {
+	mu    sync.RWMutex
+	byURI map[string]map[string]struct{}
+}
+
+// NewSubscriptions creates an empty subscription registry
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		byURI: make(map[string]map[string]struct{}),
+	}
+}
+
+// Subscribe records that a session wants updates for a URI
+func (s *Subscriptions) Subscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, ok := s.byURI[uri]
+	if !ok {
+		sessions = make(map[string]struct{})
+		s.byURI[uri] = sessions
+	}
+	sessions[sessionID] = struct{}{}
+}
+
+// Unsubscribe removes a single subscription
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sessions, ok := s.byURI[uri]; ok {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(s.byURI, uri)
+		}
+	}
+}
+
+// RemoveSession drops every subscription of a closed session
+func (s *Subscriptions) RemoveSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uri, sessions := range s.byURI {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(s.byURI, uri)
+		}
+	}
+}
+
+// Matching returns the subscriptions whose URI starts with prefix, ordered by URI
+func (s *Subscriptions) Matching(prefix string) []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Subscription
+	for uri, sessions := range s.byURI {
+		if !strings.HasPrefix(uri, prefix) {
+			continue
+		}
+		for sessionID := range sessions {
+			matches = append(matches, Subscription{SessionID: sessionID, URI: uri})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].URI != matches[j].URI {
+			return matches[i].URI < matches[j].URI
+		}
+		return matches[i].SessionID < matches[j].SessionID
+	})
+	return matches
+}
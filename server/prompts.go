@@ -0,0 +1,58 @@
+package server
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+)
+
+// registerPrompts registers the analysis prompts
+func (s *MCPServer) registerPrompts(handler *insights.PromptHandler) {
+	s.logger.Info("Registering MCP prompts")
+
+	databaseArgument := mcp.WithArgument("database",
+		mcp.RequiredArgument(),
+		mcp.ArgumentDescription("Name of the database instance"))
+
+	s.server.AddPrompt(mcp.NewPrompt(prompts.SummarizeTable,
+		mcp.WithPromptTitle("Summarize Table"),
+		mcp.WithPromptDescription("Summarize the business purpose, patterns and data quality of a table from its schema and a live sample"),
+		databaseArgument,
+		mcp.WithArgument("table",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Name of the table to summarize")),
+	), handler.HandleSummarizeTable)
+
+	s.server.AddPrompt(mcp.NewPrompt(prompts.ExplainDataModel,
+		mcp.WithPromptTitle("Explain Data Model"),
+		mcp.WithPromptDescription("Explain the entities and foreign key relationships of a database"),
+		databaseArgument,
+		mcp.WithArgument("table",
+			mcp.ArgumentDescription("Optional table to focus on")),
+	), handler.HandleExplainDataModel)
+
+	s.server.AddPrompt(mcp.NewPrompt(prompts.WriteQueryForQuestion,
+		mcp.WithPromptTitle("Write Query for Question"),
+		mcp.WithPromptDescription("Write a read-only query answering a natural language question, grounded in the live schema"),
+		databaseArgument,
+		mcp.WithArgument("question",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Question to answer, e.g. 'How many orders were placed last week?'")),
+		mcp.WithArgument("table",
+			mcp.ArgumentDescription("Optional table to restrict the schema to; includes a data sample")),
+	), handler.HandleWriteQueryForQuestion)
+
+	s.server.AddPrompt(mcp.NewPrompt(prompts.InvestigateAnomaly,
+		mcp.WithPromptTitle("Investigate Anomaly"),
+		mcp.WithPromptDescription("Plan checks to find the root cause of a data anomaly in a table"),
+		databaseArgument,
+		mcp.WithArgument("table",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Name of the affected table")),
+		mcp.WithArgument("column",
+			mcp.ArgumentDescription("Optional column showing the anomaly")),
+		mcp.WithArgument("symptom",
+			mcp.ArgumentDescription("Optional description of what looks wrong")),
+	), handler.HandleInvestigateAnomaly)
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/resources"
+)
+
+// subscriptionHooks records resources/subscribe requests per session, since
+// the built-in sessions do not track subscriptions themselves
+func subscriptionHooks(subscriptions *resources.Subscriptions) *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			subscriptions.Subscribe(session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			subscriptions.Unsubscribe(session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		subscriptions.RemoveSession(session.SessionID())
+	})
+	return hooks
+}
+
+// registerSchemaResources registers the schema resource templates, plus a
+// concrete table list and ERD resource per database for discoverability
+func (s *MCPServer) registerSchemaResources(handler *resources.SchemaResourceHandler) {
+	s.logger.Info("Registering MCP resources")
+
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(resources.TablesURITemplate, "Database Tables",
+			mcp.WithTemplateDescription("Tables of a database with column counts, served from the introspection cache"),
+			mcp.WithTemplateMIMEType("application/json")),
+		handler.HandleTables,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(resources.TableSchemaURITemplate, "Table Schema",
+			mcp.WithTemplateDescription("Columns, indexes and foreign keys of a single table"),
+			mcp.WithTemplateMIMEType("application/json")),
+		handler.HandleTableSchema,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate(resources.ERDURITemplate, "Entity Relationship Diagram",
+			mcp.WithTemplateDescription("Foreign key graph of a database as JSON and a Mermaid erDiagram"),
+			mcp.WithTemplateMIMEType("application/json")),
+		handler.HandleERD,
+	)
+
+	for dbName := range s.repositories {
+		s.server.AddResource(
+			mcp.NewResource(resources.TablesURI(dbName), fmt.Sprintf("%s tables", dbName),
+				mcp.WithResourceDescription(fmt.Sprintf("Tables of the %s database", dbName)),
+				mcp.WithMIMEType("application/json")),
+			withDatabaseArgument(dbName, handler.HandleTables),
+		)
+		s.server.AddResource(
+			mcp.NewResource(resources.ERDURI(dbName), fmt.Sprintf("%s ERD", dbName),
+				mcp.WithResourceDescription(fmt.Sprintf("Entity relationship diagram of the %s database", dbName)),
+				mcp.WithMIMEType("application/json")),
+			withDatabaseArgument(dbName, handler.HandleERD),
+		)
+	}
+}
+
+// withDatabaseArgument fills in the database template variable for concrete
+// resources, which are matched directly rather than through a template
+func withDatabaseArgument(dbName string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		request.Params.Arguments = map[string]any{"database": dbName}
+		return handler(ctx, request)
+	}
+}
+
+// notifySchemaRefreshed sends notifications/resources/updated to every
+// session subscribed to a resource of the refreshed database
+func (s *MCPServer) notifySchemaRefreshed(ctx context.Context, dbName string) {
+	for _, sub := range s.subscriptions.Matching(resources.DatabaseURIPrefix(dbName)) {
+		err := s.server.SendNotificationToSpecificClient(sub.SessionID, mcp.MethodNotificationResourceUpdated, map[string]any{
+			"uri": sub.URI,
+		})
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to send resource update notification",
+				"uri", sub.URI, "session_id", sub.SessionID, "error", err)
+			continue
+		}
+		s.logger.DebugContext(ctx, "Sent resource update notification", "uri", sub.URI, "session_id", sub.SessionID)
+	}
+}
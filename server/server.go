@@ -3,24 +3,35 @@ package server
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/completion"
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
 	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/middleware"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+	"github.com/SkillingX/mcp-localbridge/resources"
 	"github.com/SkillingX/mcp-localbridge/tools"
 )
 
 // MCPServer wraps the mcp-go server with our custom configuration
 type MCPServer struct {
-	server       *server.MCPServer
-	config       *config.Config
-	repositories map[string]db.Repository
-	redisClients map[string]*cache.RedisClient
-	logger       *slog.Logger
+	server          *server.MCPServer
+	config          *config.Config
+	repositories    map[string]db.Repository
+	redisClients    map[string]*cache.RedisClient
+	metricsRegistry *prometheus.Registry
+	toolMiddleware  middleware.ToolMiddleware
+	subscriptions   *resources.Subscriptions
+	logger          *slog.Logger
 }
 
 // NewMCPServer creates and initializes a new MCP server
@@ -39,25 +50,36 @@ func NewMCPServer(cfg *config.Config, logger *slog.Logger) (*MCPServer, error) {
 	}
 
 	// Create MCP server instance
+	subscriptions := resources.NewSubscriptions()
+	completer := completion.NewCompleter(repositories, redisClients, cfg.Completion, logger)
 	var serverOpts []server.ServerOption
-	// Note: WithRecovery might not be available in all versions of mcp-go
-	// if cfg.Server.EnableRecovery {
-	// 	serverOpts = append(serverOpts, server.WithRecovery())
-	// }
-	serverOpts = append(serverOpts, server.WithToolCapabilities(true))
+	serverOpts = append(serverOpts,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(subscriptionHooks(subscriptions)),
+		server.WithCompletions(),
+		server.WithPromptCompletionProvider(completer),
+		server.WithResourceCompletionProvider(completer),
+	)
 
 	mcpServer := server.NewMCPServer(
 		cfg.Server.Name,
 		cfg.Server.Version,
 		serverOpts...,
 	)
+	// Lets insights tools ask the client's model for summaries
+	mcpServer.EnableSampling()
 
 	mcpSrv := &MCPServer{
-		server:       mcpServer,
-		config:       cfg,
-		repositories: repositories,
-		redisClients: redisClients,
-		logger:       logger,
+		server:          mcpServer,
+		config:          cfg,
+		repositories:    repositories,
+		redisClients:    redisClients,
+		metricsRegistry: metrics.NewRegistry(repositories, redisClients),
+		toolMiddleware:  middleware.Chain(toolMiddlewares(cfg, slices.Collect(maps.Keys(repositories)), logger)...),
+		subscriptions:   subscriptions,
+		logger:          logger,
 	}
 
 	// Register all tools
@@ -68,6 +90,30 @@ func NewMCPServer(cfg *config.Config, logger *slog.Logger) (*MCPServer, error) {
 	return mcpSrv, nil
 }
 
+// toolMiddlewares returns the middleware chain applied to every tool handler,
+// outermost first. Cross-cutting concerns such as auth, audit, rate limiting
+// or caching should be added here rather than in individual handlers.
+func toolMiddlewares(cfg *config.Config, databases []string, logger *slog.Logger) []middleware.ToolMiddleware {
+	chain := []middleware.ToolMiddleware{
+		middleware.RequestID(),
+		middleware.Tracing(),
+		middleware.Logging(logger),
+		middleware.Metrics(databases),
+		middleware.Progress(),
+		middleware.Timeout(cfg.GetRequestTimeout()),
+	}
+	// Recovery must sit inside Timeout so panics in the handler goroutine are caught
+	if cfg.Server.EnableRecovery {
+		chain = append(chain, middleware.Recovery(logger))
+	}
+	return chain
+}
+
+// addTool registers a tool with its handler wrapped in the middleware chain
+func (s *MCPServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.server.AddTool(tool, s.toolMiddleware(handler))
+}
+
 // registerTools registers all MCP tools
 func (s *MCPServer) registerTools() error {
 	s.logger.Info("Registering MCP tools")
@@ -76,17 +122,29 @@ func (s *MCPServer) registerTools() error {
 	dbToolsHandler := tools.NewDBToolsHandler(s.repositories, s.config.Tools.DB, s.logger)
 	redisToolsHandler := tools.NewRedisToolsHandler(s.redisClients, s.config.Tools.Redis, s.logger)
 
+	// Prompt templates back both the MCP prompts and sampled summaries
+	templates, err := prompts.Load(s.config.Prompts)
+	if err != nil {
+		return fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+	summarizer := insights.NewSummarizer(s.server, templates, s.redisClients, s.config.Tools.Insights.Summarization, s.logger)
+
 	// Insights handlers
 	introspectionHandler := insights.NewIntrospectionHandler(s.repositories, s.redisClients, s.config.Tools.Insights.Introspection, s.logger)
-	semanticSummaryHandler := insights.NewSemanticSummaryHandler(s.repositories, s.config.Tools.Insights.SemanticSummary, s.logger)
-	relationshipHandler := insights.NewRelationshipHandler(s.repositories, s.redisClients, s.config.Tools.Insights.Relationship, s.logger)
+	semanticSummaryHandler := insights.NewSemanticSummaryHandler(s.repositories, s.config.Tools.Insights.SemanticSummary, summarizer, s.logger)
+	relationshipHandler := insights.NewRelationshipHandler(s.repositories, s.redisClients, s.config.Tools.Insights.Relationship, summarizer, s.logger)
 	analyticsHandler := insights.NewAnalyticsHandler(s.repositories, s.config.Tools.Insights.Analytics, s.logger)
-	metadataHandler := insights.NewMetadataHandler(s.repositories, s.logger)
+	profileHandler := insights.NewProfileHandler(s.repositories, s.config.Tools.Insights.Analytics, s.logger)
+	timeseriesHandler := insights.NewTimeseriesHandler(s.repositories, s.config.Tools.Insights.Analytics, s.logger)
+	pivotHandler := insights.NewPivotHandler(s.repositories, s.config.Tools.Insights.Analytics, s.logger)
+	metadataHandler := insights.NewMetadataHandler(s.repositories, summarizer, s.logger)
 
 	// Register database tools
 	s.registerDBListDatabasesTool(dbToolsHandler)
 	s.registerDBQueryTool(dbToolsHandler)
+	s.registerDBJoinQueryTool(dbToolsHandler)
 	s.registerDBTableListTool(dbToolsHandler)
+	s.registerDBObjectDefinitionTool(dbToolsHandler)
 	s.registerDBTablePreviewTool(dbToolsHandler)
 
 	// Register Redis tools
@@ -99,19 +157,44 @@ func (s *MCPServer) registerTools() error {
 	s.registerSemanticSummaryTool(semanticSummaryHandler)
 	s.registerRelationshipTool(relationshipHandler)
 	s.registerAnalyticsTool(analyticsHandler)
+	s.registerProfileTool(profileHandler)
+	s.registerTimeseriesTool(timeseriesHandler)
+	s.registerPivotTool(pivotHandler)
 	s.registerMetadataTool(metadataHandler)
 
+	// Schema resources share the insights handlers, so a refresh through the
+	// introspection tool notifies resource subscribers
+	s.registerSchemaResources(resources.NewSchemaResourceHandler(introspectionHandler, relationshipHandler, s.logger))
+	introspectionHandler.OnRefresh(s.notifySchemaRefreshed)
+
+	// Prompts render the analysis templates with live schema and samples
+	s.registerPrompts(insights.NewPromptHandler(introspectionHandler, relationshipHandler, semanticSummaryHandler, templates, s.logger))
+
 	s.logger.Info("All MCP tools registered successfully")
 	return nil
 }
 
+// readOnlyAnnotation describes a tool that only reads from the configured
+// local backends, so clients can run it without asking for confirmation
+func readOnlyAnnotation(title string) mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
 // Database Tools Registration
 
 func (s *MCPServer) registerDBListDatabasesTool(handler *tools.DBToolsHandler) {
 	tool := mcp.NewTool("db_list_databases",
 		mcp.WithDescription("List all available database instances configured in the MCP server. Use these database names when calling other database tools."),
+		mcp.WithOutputSchema[tools.DBListDatabasesResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("List Databases")),
 	)
-	s.server.AddTool(tool, handler.HandleDBListDatabases)
+	s.addTool(tool, handler.HandleDBListDatabases)
 }
 
 func (s *MCPServer) registerDBQueryTool(handler *tools.DBToolsHandler) {
@@ -123,28 +206,116 @@ func (s *MCPServer) registerDBQueryTool(handler *tools.DBToolsHandler) {
 		mcp.WithString("table",
 			mcp.Required(),
 			mcp.Description("Name of the table to query")),
-		mcp.WithString("conditions",
-			mcp.Description("JSON object of WHERE conditions (e.g., '{\"status\":\"active\",\"age\":25}'). Supports equality and LIKE patterns.")),
-		mcp.WithString("limit",
-			mcp.Description(fmt.Sprintf("Maximum number of rows to return (max: %d)", s.config.Tools.DB.MaxRows))),
-		mcp.WithString("offset",
-			mcp.Description("Number of rows to skip")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithObject("conditions",
+			mcp.Description("WHERE conditions as column/value pairs (e.g., {\"status\":\"active\",\"age\":25}). Supports equality and LIKE patterns."),
+			mcp.AdditionalProperties(true)),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return (max: %d)", s.config.Tools.DB.MaxRows)),
+			mcp.Min(1),
+			mcp.Max(float64(s.config.Tools.DB.MaxRows)),
+			mcp.DefaultNumber(float64(s.config.Tools.DB.MaxRows))),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of rows to skip"),
+			mcp.Min(0),
+			mcp.DefaultNumber(0)),
 		mcp.WithString("order_by",
 			mcp.Description("Column(s) to sort by (e.g., 'created_at DESC, id ASC')")),
-		mcp.WithString("dry_run",
-			mcp.Description(fmt.Sprintf("If 'true', return SQL preview without execution. Default: %v", s.config.Tools.DB.DefaultDryRun))),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, return SQL preview without execution"),
+			mcp.DefaultBool(s.config.Tools.DB.DefaultDryRun)),
+		mcp.WithOutputSchema[tools.DBQueryResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Query Table")),
 	)
-	s.server.AddTool(tool, handler.HandleDBQuery)
+	s.addTool(tool, handler.HandleDBQuery)
+}
+
+func (s *MCPServer) registerDBJoinQueryTool(handler *tools.DBToolsHandler) {
+	tool := mcp.NewTool("db_join_query",
+		mcp.WithDescription("Query a table joined with related tables in one parameterized query. Join conditions follow the foreign keys, over several hops if needed; ambiguous paths are refused unless chosen with via. Results use table.column names."),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Name of the database instance to query")),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Root table of the join")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of unqualified tables; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithArray("join",
+			mcp.Required(),
+			mcp.Description("Related tables to join, in order; tables on the foreign key path between them are joined too"),
+			mcp.WithStringItems()),
+		mcp.WithObject("via",
+			mcp.Description(`Choices among equally short join paths: per joined table, the tables, constraint names or table.column foreign keys the path must pass, e.g. {"users": ["orders.created_by"]}`),
+			mcp.AdditionalProperties(map[string]any{"type": "array", "items": map[string]any{"type": "string"}})),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to return as table.column, or table.* (default: all columns of table and the join tables); bare names belong to table"),
+			mcp.WithStringItems()),
+		mcp.WithObject("conditions",
+			mcp.Description(`WHERE conditions by table.column, as for analytics: a value compares with =, null matches NULL, an array matches any of its values, and an object maps operators to values, e.g. {"customers.country": "DE", "orders.amount": {">=": 100}}`),
+			mcp.AdditionalProperties(true)),
+		mcp.WithString("join_type",
+			mcp.Description("inner keeps rows with a match in every joined table; left keeps all rows of table"),
+			mcp.Enum("inner", "left"),
+			mcp.DefaultString("inner")),
+		mcp.WithNumber("max_depth",
+			mcp.Description("Maximum number of joins between a table and the tables joined before it"),
+			mcp.Min(1),
+			mcp.Max(6),
+			mcp.DefaultNumber(3)),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return (max: %d)", s.config.Tools.DB.MaxRows)),
+			mcp.Min(1),
+			mcp.Max(float64(s.config.Tools.DB.MaxRows)),
+			mcp.DefaultNumber(float64(s.config.Tools.DB.MaxRows))),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of rows to skip"),
+			mcp.Min(0),
+			mcp.DefaultNumber(0)),
+		mcp.WithString("order_by",
+			mcp.Description("table.column terms to sort by (e.g., 'orders.created_at DESC, customers.name')")),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("If true, return SQL preview without execution"),
+			mcp.DefaultBool(s.config.Tools.DB.DefaultDryRun)),
+		mcp.WithOutputSchema[tools.DBJoinQueryResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Join Query")),
+	)
+	s.addTool(tool, handler.HandleDBJoinQuery)
 }
 
 func (s *MCPServer) registerDBTableListTool(handler *tools.DBToolsHandler) {
 	tool := mcp.NewTool("db_table_list",
-		mcp.WithDescription("List all tables in a database"),
+		mcp.WithDescription("List all tables in the configured schemas of a database, plus views, materialized views, procedures, functions, triggers and sequences with their object type"),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Name of the database instance")),
+		mcp.WithString("schema",
+			mcp.Description("Only list objects of this configured schema (MySQL: database)")),
+		mcp.WithOutputSchema[tools.DBTableListResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("List Tables")),
+	)
+	s.addTool(tool, handler.HandleDBTableList)
+}
+
+func (s *MCPServer) registerDBObjectDefinitionTool(handler *tools.DBToolsHandler) {
+	tool := mcp.NewTool("db_object_definition",
+		mcp.WithDescription("Return the DDL (CREATE statement) of a table, view, materialized view, procedure, function, trigger or sequence"),
 		mcp.WithString("database",
 			mcp.Required(),
 			mcp.Description("Name of the database instance")),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the object, as listed by db_table_list")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the object; defaults to the first configured schema")),
+		mcp.WithString("type",
+			mcp.Description("Object type; required only when several objects share the name"),
+			mcp.Enum(db.ObjectTypes...)),
+		mcp.WithOutputSchema[tools.DBObjectDefinitionResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Show Object Definition")),
 	)
-	s.server.AddTool(tool, handler.HandleDBTableList)
+	s.addTool(tool, handler.HandleDBObjectDefinition)
 }
 
 func (s *MCPServer) registerDBTablePreviewTool(handler *tools.DBToolsHandler) {
@@ -156,8 +327,12 @@ func (s *MCPServer) registerDBTablePreviewTool(handler *tools.DBToolsHandler) {
 		mcp.WithString("table",
 			mcp.Required(),
 			mcp.Description("Name of the table to preview")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithOutputSchema[tools.DBTablePreviewResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Preview Table")),
 	)
-	s.server.AddTool(tool, handler.HandleDBTablePreview)
+	s.addTool(tool, handler.HandleDBTablePreview)
 }
 
 // Redis Tools Registration
@@ -171,8 +346,10 @@ func (s *MCPServer) registerRedisGetTool(handler *tools.RedisToolsHandler) {
 		mcp.WithString("key",
 			mcp.Required(),
 			mcp.Description("Redis key to retrieve")),
+		mcp.WithOutputSchema[tools.RedisGetResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Redis Get")),
 	)
-	s.server.AddTool(tool, handler.HandleRedisGet)
+	s.addTool(tool, handler.HandleRedisGet)
 }
 
 func (s *MCPServer) registerRedisSetTool(handler *tools.RedisToolsHandler) {
@@ -187,10 +364,20 @@ func (s *MCPServer) registerRedisSetTool(handler *tools.RedisToolsHandler) {
 		mcp.WithString("value",
 			mcp.Required(),
 			mcp.Description("Value to store")),
-		mcp.WithString("ttl",
-			mcp.Description("Time-to-live in seconds (optional)")),
+		mcp.WithNumber("ttl",
+			mcp.Description("Time-to-live in seconds (optional, 0 means no expiry)"),
+			mcp.Min(0),
+			mcp.DefaultNumber(0)),
+		mcp.WithOutputSchema[tools.RedisSetResult](),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Redis Set",
+			ReadOnlyHint:    mcp.ToBoolPtr(false),
+			DestructiveHint: mcp.ToBoolPtr(true),
+			IdempotentHint:  mcp.ToBoolPtr(true),
+			OpenWorldHint:   mcp.ToBoolPtr(false),
+		}),
 	)
-	s.server.AddTool(tool, handler.HandleRedisSet)
+	s.addTool(tool, handler.HandleRedisSet)
 }
 
 func (s *MCPServer) registerRedisScanTool(handler *tools.RedisToolsHandler) {
@@ -200,71 +387,279 @@ func (s *MCPServer) registerRedisScanTool(handler *tools.RedisToolsHandler) {
 			mcp.Required(),
 			mcp.Description("Name of the Redis instance")),
 		mcp.WithString("pattern",
-			mcp.Description("Key pattern to match (e.g., 'user:*'). Default: '*'")),
+			mcp.Description("Key pattern to match (e.g., 'user:*')"),
+			mcp.DefaultString("*")),
+		mcp.WithOutputSchema[tools.RedisScanResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Redis Scan")),
 	)
-	s.server.AddTool(tool, handler.HandleRedisScan)
+	s.addTool(tool, handler.HandleRedisScan)
 }
 
 // Insights Tools Registration
 
 func (s *MCPServer) registerIntrospectionTool(handler *insights.IntrospectionHandler) {
 	tool := mcp.NewTool("introspection",
-		mcp.WithDescription("Introspect database schema to get detailed information about all tables, columns, indexes, and relationships. Results are cached for better performance."),
+		mcp.WithDescription("Introspect database schema to get detailed information about tables, columns, indexes, and relationships. Tables are cached individually and output is paginated for large schemas."),
 		mcp.WithString("database",
 			mcp.Required(),
 			mcp.Description("Name of the database instance")),
-		mcp.WithString("refresh",
-			mcp.Description("Set to 'true' to refresh cache. Default: false")),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Set to true to re-check the database and rebuild tables whose definition changed"),
+			mcp.DefaultBool(false)),
+		mcp.WithString("tables",
+			mcp.Description("Comma-separated glob patterns of tables to include (e.g., 'orders_*,users,sales.*'). Defaults to all tables")),
+		mcp.WithString("schema",
+			mcp.Description("Only introspect tables of this configured schema (MySQL: database)")),
+		mcp.WithNumber("page",
+			mcp.Description("Page of tables to return, starting at 1"),
+			mcp.Min(1),
+			mcp.DefaultNumber(1)),
+		mcp.WithNumber("page_size",
+			mcp.Description("Number of tables per page (max 1000). Defaults to the configured page size"),
+			mcp.Min(1),
+			mcp.Max(1000)),
+		mcp.WithOutputSchema[insights.IntrospectionResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Introspect Schema")),
 	)
-	s.server.AddTool(tool, handler.HandleIntrospection)
+	s.addTool(tool, handler.HandleIntrospection)
 }
 
 func (s *MCPServer) registerSemanticSummaryTool(handler *insights.SemanticSummaryHandler) {
 	tool := mcp.NewTool("semantic_summary",
-		mcp.WithDescription("Generate a semantic summary of table data. Returns schema, sample data, and an LLM prompt template that MCP clients can use to generate business-meaningful insights."),
+		mcp.WithDescription("Generate a semantic summary of table data. Returns schema and sample data; use the summarize_table prompt to turn them into business-meaningful insights."),
 		mcp.WithString("database",
 			mcp.Required(),
 			mcp.Description("Name of the database instance")),
 		mcp.WithString("table",
 			mcp.Required(),
 			mcp.Description("Name of the table to summarize")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithString("sample_mode",
+			mcp.Description("How to sample rows: first (physical order), random, recent (latest rows by a timestamp column), stratified (even share per value of sample_column) or hash (reproducible, by primary key)"),
+			mcp.Enum(db.SampleModes...)),
+		mcp.WithString("sample_column",
+			mcp.Description("Timestamp column for recent sampling (detected when omitted) or categorical column for stratified sampling")),
+		mcp.WithString("seed",
+			mcp.Description("Seed for hash sampling; the same seed returns the same rows while the data is unchanged")),
+		mcp.WithBoolean("summarize",
+			mcp.Description("If true, ask the client's model (MCP sampling) to summarize a table; clients without sampling get the rendered prompt instead"),
+			mcp.DefaultBool(s.config.Tools.Insights.Summarization.Enabled)),
+		mcp.WithOutputSchema[insights.SemanticSummaryResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Semantic Summary")),
 	)
-	s.server.AddTool(tool, handler.HandleSemanticSummary)
+	s.addTool(tool, handler.HandleSemanticSummary)
 }
 
 func (s *MCPServer) registerRelationshipTool(handler *insights.RelationshipHandler) {
 	tool := mcp.NewTool("relationship",
-		mcp.WithDescription("Analyze foreign key relationships between tables. Returns a relationship graph and LLM prompt template for understanding the data model."),
+		mcp.WithDescription("Analyze foreign key relationships between tables. Returns a relationship graph, with the foreign keys referencing a single table, or with from_table and to_table every shortest join path between them and its SQL JOIN clause; use the explain_data_model prompt to explain the data model."),
 		mcp.WithString("database",
 			mcp.Required(),
 			mcp.Description("Name of the database instance")),
 		mcp.WithString("table",
 			mcp.Description("Optional: specific table to analyze. If omitted, analyzes all tables.")),
+		mcp.WithString("from_table",
+			mcp.Description("Optional: table to find join paths from, together with to_table. Paths follow foreign keys in either direction, up to the configured max_depth joins")),
+		mcp.WithString("to_table",
+			mcp.Description("Optional: table to find join paths to, together with from_table")),
+		mcp.WithBoolean("infer",
+			mcp.Description("If true, also propose undeclared foreign keys from column names (user_id -> users.id), matching types and sampled values, each with a confidence, under inferred_relationships. Not used for join paths"),
+			mcp.DefaultBool(s.config.Tools.Insights.Relationship.Infer)),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table, or, without a table, the only schema to analyze. Tables may also be named schema.table")),
+		mcp.WithBoolean("summarize",
+			mcp.Description("If true, ask the client's model (MCP sampling) to explain the data model; clients without sampling get the rendered prompt instead"),
+			mcp.DefaultBool(s.config.Tools.Insights.Summarization.Enabled)),
+		mcp.WithOutputSchema[insights.RelationshipResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Table Relationships")),
 	)
-	s.server.AddTool(tool, handler.HandleRelationship)
+	s.addTool(tool, handler.HandleRelationship)
+}
+
+// metricSchema is the JSON schema of a db.Metric argument item
+var metricSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"fn":     map[string]any{"type": "string", "enum": db.AggregateFuncs},
+		"column": map[string]any{"type": "string", "description": "Column to aggregate; omit to count rows"},
+		"alias":  map[string]any{"type": "string", "description": "Result key of the metric"},
+		"p":      map[string]any{"type": "number", "minimum": 0, "maximum": 1, "description": "Fraction computed by PERCENTILE, e.g. 0.95"},
+	},
+	"required":             []string{"fn"},
+	"additionalProperties": false,
 }
 
 func (s *MCPServer) registerAnalyticsTool(handler *insights.AnalyticsHandler) {
 	tool := mcp.NewTool("analytics",
-		mcp.WithDescription("Perform analytical aggregations (COUNT, SUM, AVG, MIN, MAX) on table data with optional grouping and filtering. Uses parameterized queries for security."),
+		mcp.WithDescription("Perform analytical aggregations on table data: several metrics (COUNT, COUNT_DISTINCT, APPROX_COUNT_DISTINCT, SUM, AVG, MIN, MAX, MEDIAN, PERCENTILE, STDDEV, VARIANCE) over multiple group-by columns, with filtering, HAVING on metrics, ordering and a top-N limit. The result reports how each metric was computed. Uses parameterized queries for security."),
 		mcp.WithString("database",
 			mcp.Required(),
 			mcp.Description("Name of the database instance")),
 		mcp.WithString("table",
 			mcp.Required(),
 			mcp.Description("Name of the table to analyze")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithArray("metrics",
+			mcp.Description(`Metrics to compute, e.g. [{"fn":"SUM","column":"amount","alias":"revenue"},{"fn":"PERCENTILE","column":"amount","p":0.95},{"fn":"COUNT"}]. alias defaults to fn_column, or pNN_column for percentiles`),
+			mcp.Items(metricSchema)),
 		mcp.WithString("column",
-			mcp.Required(),
-			mcp.Description("Column to aggregate")),
+			mcp.Description("Column to aggregate (legacy single-metric form, use metrics instead)")),
 		mcp.WithString("function",
+			mcp.Description("Aggregate function to apply (legacy single-metric form, returned as result)"),
+			mcp.Enum("COUNT", "SUM", "AVG", "MIN", "MAX")),
+		mcp.WithObject("conditions",
+			mcp.Description(`WHERE conditions by column: a value compares with =, null matches NULL, an array matches any of its values, and an object maps operators (=, !=, <, <=, >, >=, LIKE, NOT LIKE, IN, NOT IN) to values, e.g. {"amount": {">=": 100}}`),
+			mcp.AdditionalProperties(true)),
+		mcp.WithArray("group_by",
+			mcp.Description("Columns to group by"),
+			mcp.WithStringItems()),
+		mcp.WithArray("having",
+			mcp.Description(`Filters on metric aliases, e.g. [{"metric":"revenue","op":">","value":1000}]`),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"metric": map[string]any{"type": "string"},
+					"op":     map[string]any{"type": "string", "enum": []string{"=", "!=", "<>", "<", "<=", ">", ">=", "IN", "NOT IN"}},
+					"value":  map[string]any{},
+				},
+				"required":             []string{"metric", "op", "value"},
+				"additionalProperties": false,
+			})),
+		mcp.WithString("order_by",
+			mcp.Description("Metric aliases or group_by columns to sort by, comma separated, each optionally followed by ASC or DESC (e.g. 'revenue DESC, region')")),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of result rows, e.g. the N of a top-N query"),
+			mcp.Min(1),
+			mcp.Max(float64(s.config.Tools.Insights.Analytics.MaxResultRows)),
+			mcp.DefaultNumber(float64(s.config.Tools.Insights.Analytics.MaxResultRows))),
+		mcp.WithOutputSchema[insights.AnalyticsResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Aggregate Analytics")),
+	)
+	s.addTool(tool, handler.HandleAnalytics)
+}
+
+func (s *MCPServer) registerProfileTool(handler *insights.ProfileHandler) {
+	tool := mcp.NewTool("profile",
+		mcp.WithDescription("Profile the columns of a table: null ratio, distinct count, min/max, mean/stddev/percentiles and histograms for numbers, length stats for strings, and the most frequent values. Large tables are sampled; their distinct counts are HyperLogLog estimates."),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Name of the database instance")),
+		mcp.WithString("table",
 			mcp.Required(),
-			mcp.Description("Aggregate function: COUNT, SUM, AVG, MIN, or MAX")),
-		mcp.WithString("conditions",
-			mcp.Description("JSON object of WHERE conditions (optional)")),
-		mcp.WithString("group_by",
-			mcp.Description("Column to group by (optional)")),
+			mcp.Description("Name of the table to profile")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to profile (default: all columns)"),
+			mcp.WithStringItems()),
+		mcp.WithNumber("top_n",
+			mcp.Description("Number of most frequent values to report per column (0 disables)"),
+			mcp.Min(0),
+			mcp.Max(100),
+			mcp.DefaultNumber(10)),
+		mcp.WithNumber("bins",
+			mcp.Description("Number of equi-width histogram buckets for numeric columns (0 disables)"),
+			mcp.Min(0),
+			mcp.Max(100),
+			mcp.DefaultNumber(10)),
+		mcp.WithOutputSchema[insights.ProfileResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Column Profile")),
 	)
-	s.server.AddTool(tool, handler.HandleAnalytics)
+	s.addTool(tool, handler.HandleProfile)
+}
+
+func (s *MCPServer) registerTimeseriesTool(handler *insights.TimeseriesHandler) {
+	tool := mcp.NewTool("timeseries",
+		mcp.WithDescription("Group table rows into time buckets (minute, hour, day, week, month) and compute metrics per bucket, optionally split into series by a dimension column. Empty buckets are filled with zero or null. Answers questions such as 'orders per day for the last 90 days'."),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Name of the database instance")),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Name of the table to analyze")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithString("time_column",
+			mcp.Required(),
+			mcp.Description("Date, timestamp or integer epoch (seconds) column to bucket by")),
+		mcp.WithString("bucket",
+			mcp.Required(),
+			mcp.Description("Bucket width; weeks start on Monday"),
+			mcp.Enum(db.TimeBuckets...)),
+		mcp.WithArray("metrics",
+			mcp.Description(`Metrics per bucket, as for analytics, e.g. [{"fn":"SUM","column":"amount","alias":"revenue"}]. Defaults to a row count`),
+			mcp.Items(metricSchema)),
+		mcp.WithString("split_by",
+			mcp.Description("Dimension column splitting the result into one series per value (optional)")),
+		mcp.WithObject("conditions",
+			mcp.Description("WHERE conditions by column, as for analytics"),
+			mcp.AdditionalProperties(true)),
+		mcp.WithString("from",
+			mcp.Description("Range start, inclusive: RFC 3339 time or YYYY-MM-DD [HH:MM:SS] in timezone")),
+		mcp.WithString("to",
+			mcp.Description("Range end, exclusive: RFC 3339 time or YYYY-MM-DD [HH:MM:SS] in timezone")),
+		mcp.WithString("last",
+			mcp.Description("Relative range ending at to (default now), e.g. 90d, 24h, 12w or 6mo; starts at a bucket boundary. Replaces from")),
+		mcp.WithString("timezone",
+			mcp.Description("Time zone of the buckets: IANA name (e.g. Asia/Shanghai) or UTC offset (e.g. +08:00)"),
+			mcp.DefaultString("UTC")),
+		mcp.WithString("fill",
+			mcp.Description("Value of metrics in empty buckets; none leaves gaps out"),
+			mcp.Enum(insights.FillZero, insights.FillNull, insights.FillNone),
+			mcp.DefaultString(insights.FillZero)),
+		mcp.WithOutputSchema[insights.TimeseriesResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Time Series")),
+	)
+	s.addTool(tool, handler.HandleTimeseries)
+}
+
+func (s *MCPServer) registerPivotTool(handler *insights.PivotHandler) {
+	tool := mcp.NewTool("pivot",
+		mcp.WithDescription("Cross-tabulate a metric by a row dimension and a column dimension, with row and column totals, e.g. 'revenue by region by month'. Column dimension values are discovered first and capped at max_columns. Returns JSON rows, a Markdown table or columnar arrays."),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Name of the database instance")),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Name of the table to analyze")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithString("row_dimension",
+			mcp.Required(),
+			mcp.Description("Column whose values become the rows")),
+		mcp.WithString("column_dimension",
+			mcp.Required(),
+			mcp.Description("Column whose values become the columns")),
+		mcp.WithString("row_bucket",
+			mcp.Description("Group the row dimension, a date, timestamp or epoch column, into time buckets"),
+			mcp.Enum(db.TimeBuckets...)),
+		mcp.WithString("column_bucket",
+			mcp.Description("Group the column dimension, a date, timestamp or epoch column, into time buckets"),
+			mcp.Enum(db.TimeBuckets...)),
+		mcp.WithString("timezone",
+			mcp.Description("Time zone of time buckets: IANA name (e.g. Asia/Shanghai) or UTC offset (e.g. +08:00)"),
+			mcp.DefaultString("UTC")),
+		mcp.WithObject("metric",
+			mcp.Description(`Metric of each cell, as for analytics but without percentiles, e.g. {"fn":"SUM","column":"amount"}. Defaults to a row count`),
+			mcp.Properties(metricSchema["properties"].(map[string]any))),
+		mcp.WithObject("conditions",
+			mcp.Description(`WHERE conditions by column, as for analytics, e.g. {"status": ["paid", "shipped"]}`),
+			mcp.AdditionalProperties(true)),
+		mcp.WithNumber("max_columns",
+			mcp.Description("Maximum number of column dimension values; columns_truncated reports more"),
+			mcp.Min(1),
+			mcp.Max(100),
+			mcp.DefaultNumber(20)),
+		mcp.WithString("format",
+			mcp.Description("Shape of the pivot: json rows, a Markdown table, or columnar arrays"),
+			mcp.Enum(insights.FormatJSON, insights.FormatMarkdown, insights.FormatColumnar),
+			mcp.DefaultString(insights.FormatJSON)),
+		mcp.WithOutputSchema[insights.PivotResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Pivot Table")),
+	)
+	s.addTool(tool, handler.HandlePivot)
 }
 
 func (s *MCPServer) registerMetadataTool(handler *insights.MetadataHandler) {
@@ -276,8 +671,12 @@ func (s *MCPServer) registerMetadataTool(handler *insights.MetadataHandler) {
 		mcp.WithString("table",
 			mcp.Required(),
 			mcp.Description("Name of the table")),
+		mcp.WithString("schema",
+			mcp.Description("Schema (MySQL: database) of the table; defaults to the first configured schema. Tables may also be named schema.table")),
+		mcp.WithOutputSchema[insights.MetadataResult](),
+		mcp.WithToolAnnotation(readOnlyAnnotation("Table Metadata")),
 	)
-	s.server.AddTool(tool, handler.HandleMetadata)
+	s.addTool(tool, handler.HandleMetadata)
 }
 
 // GetServer returns the underlying mcp-go server
@@ -285,6 +684,11 @@ func (s *MCPServer) GetServer() *server.MCPServer {
 	return s.server
 }
 
+// GetMetricsRegistry returns the Prometheus registry with tool, pool and session metrics
+func (s *MCPServer) GetMetricsRegistry() *prometheus.Registry {
+	return s.metricsRegistry
+}
+
 // Close closes all database and Redis connections
 func (s *MCPServer) Close() error {
 	s.logger.Info("Closing MCP server resources")
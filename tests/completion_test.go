@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/completion"
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestCompletion_Names tests completion of configured instance names
+func TestCompletion_Names(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	repos := map[string]db.Repository{"mysql_main": nil, "mysql_reports": nil, "postgres_main": nil}
+	redisClients := map[string]*cache.RedisClient{"cache_main": nil}
+	completer := completion.NewCompleter(repos, redisClients, config.CompletionConfig{}, logger)
+
+	tests := []struct {
+		name     string
+		argument mcp.CompleteArgument
+		resolved map[string]string
+		want     []string
+	}{
+		{"Database prefix", mcp.CompleteArgument{Name: "database", Value: "mysql"}, nil, []string{"mysql_main", "mysql_reports"}},
+		{"Database case-insensitive", mcp.CompleteArgument{Name: "database", Value: "POST"}, nil, []string{"postgres_main"}},
+		{"Database misspelling", mcp.CompleteArgument{Name: "database", Value: "postgers_main"}, nil, []string{"postgres_main"}},
+		{"Redis instance", mcp.CompleteArgument{Name: "redis", Value: ""}, nil, []string{"cache_main"}},
+		{"Table of unknown database", mcp.CompleteArgument{Name: "table", Value: "us"}, map[string]string{"database": "missing"}, []string{}},
+		{"Unknown argument", mcp.CompleteArgument{Name: "limit", Value: "1"}, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := completer.Complete(context.Background(), tt.argument, tt.resolved)
+			if !reflect.DeepEqual(got.Values, tt.want) {
+				t.Errorf("Complete(%s=%q) = %v, want %v", tt.argument.Name, tt.argument.Value, got.Values, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+)
+
+// TestConfig_ParseRowCountStrategy tests parsing of the row_count setting
+func TestConfig_ParseRowCountStrategy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    config.RowCountStrategy
+		wantErr bool
+	}{
+		{value: "", want: config.RowCountStrategy{Mode: config.RowCountEstimate}},
+		{value: "none", want: config.RowCountStrategy{Mode: config.RowCountNone}},
+		{value: "estimate", want: config.RowCountStrategy{Mode: config.RowCountEstimate}},
+		{value: "exact", want: config.RowCountStrategy{Mode: config.RowCountExact}},
+		{value: "exact_below:100000", want: config.RowCountStrategy{Mode: config.RowCountExactBelow, Threshold: 100000}},
+		{value: "exact_below", wantErr: true},
+		{value: "exact_below:0", wantErr: true},
+		{value: "exact_below:many", wantErr: true},
+		{value: "exact:10", wantErr: true},
+		{value: "approximate", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := config.ParseRowCountStrategy(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q, got %+v", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRowCountStrategy(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
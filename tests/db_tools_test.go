@@ -11,6 +11,7 @@ import (
 
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
 	"github.com/SkillingX/mcp-localbridge/tools"
 )
 
@@ -80,6 +81,26 @@ func TestDBTools_ParameterValidation(t *testing.T) {
 			},
 			wantError: false,
 		},
+		{
+			name: "Typed object conditions",
+			args: map[string]any{
+				"database":   "test_db",
+				"table":      "users",
+				"conditions": map[string]any{"status": "active", "age": float64(25)},
+				"limit":      float64(10),
+				"dry_run":    true,
+			},
+			wantError: false,
+		},
+		{
+			name: "Invalid identifier in conditions",
+			args: map[string]any{
+				"database":   "test_db",
+				"table":      "users",
+				"conditions": map[string]any{"status; DROP TABLE users": "x"},
+			},
+			wantError: true,
+		},
 	}
 
 	// Setup
@@ -156,6 +177,66 @@ func TestDBTools_ParameterValidation(t *testing.T) {
 	}
 }
 
+// TestDBTools_StructuredContent tests that results carry structured content alongside text
+func TestDBTools_StructuredContent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	handler := tools.NewDBToolsHandler(make(map[string]db.Repository), config.DBToolsConfig{MaxRows: 100}, logger)
+
+	result, err := handler.HandleDBListDatabases(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Handler error: %v", err)
+	}
+
+	structured, ok := result.StructuredContent.(tools.DBListDatabasesResult)
+	if !ok {
+		t.Fatalf("Expected DBListDatabasesResult structured content, got %T", result.StructuredContent)
+	}
+	if structured.Databases == nil || structured.Count != 0 {
+		t.Errorf("Expected an empty, non-nil database list, got %+v", structured)
+	}
+	if len(result.Content) == 0 {
+		t.Errorf("Expected text content for older clients")
+	}
+}
+
+// TestDBTools_ObjectDefinitionValidation tests that db_object_definition rejects bad arguments before querying
+func TestDBTools_ObjectDefinitionValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	handler := tools.NewDBToolsHandler(make(map[string]db.Repository), config.DBToolsConfig{MaxRows: 100}, logger)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		code toolerrors.Code
+	}{
+		{"Missing name", map[string]any{"database": "main"}, toolerrors.CodeInvalidArgument},
+		{"Unsafe name", map[string]any{"database": "main", "name": "users; DROP TABLE users"}, toolerrors.CodeInvalidIdentifier},
+		{"Unknown type", map[string]any{"database": "main", "name": "users", "type": "index"}, toolerrors.CodeInvalidArgument},
+		{"Unknown database", map[string]any{"database": "main", "name": "users", "type": "view"}, toolerrors.CodeDatabaseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "db_object_definition", Arguments: tt.args}}
+			result, err := handler.HandleDBObjectDefinition(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Handler error: %v", err)
+			}
+			te, ok := result.StructuredContent.(*toolerrors.ToolError)
+			if !ok {
+				t.Fatalf("Expected ToolError structured content, got %T", result.StructuredContent)
+			}
+			if te.Code != tt.code {
+				t.Errorf("Expected code %s, got %s: %s", tt.code, te.Code, te.Message)
+			}
+		})
+	}
+}
+
 // Helper function to check if string contains substring (case-insensitive)
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
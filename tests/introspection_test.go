@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// TestIntrospection_ArgumentValidation tests that bad filters and pages are rejected before any query runs
+func TestIntrospection_ArgumentValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	// The repository is never queried because validation fails first
+	repos := map[string]db.Repository{"main": &db.MySQLRepository{}}
+	handler := insights.NewIntrospectionHandler(repos, map[string]*cache.RedisClient{}, config.IntrospectionConfig{}, logger)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		code toolerrors.Code
+	}{
+		{"Unknown database", map[string]any{"database": "missing"}, toolerrors.CodeDatabaseNotFound},
+		{"Page below one", map[string]any{"database": "main", "page": float64(0)}, toolerrors.CodeInvalidArgument},
+		{"Page size too large", map[string]any{"database": "main", "page_size": float64(5000)}, toolerrors.CodeInvalidArgument},
+		{"Malformed pattern", map[string]any{"database": "main", "tables": "orders_*, [users"}, toolerrors.CodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "introspection", Arguments: tt.args}}
+			result, err := handler.HandleIntrospection(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("Expected error result, got %+v", result)
+			}
+			te, ok := result.StructuredContent.(*toolerrors.ToolError)
+			if !ok {
+				t.Fatalf("Expected ToolError structured content, got %T", result.StructuredContent)
+			}
+			if te.Code != tt.code {
+				t.Errorf("Expected code %s, got %s: %s", tt.code, te.Code, te.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// joinTestGraph: order_items -> orders -> customers, orders -> users twice,
+// and addresses -> customers
+func joinTestGraph() db.JoinGraph {
+	fk := func(name, source, column, referenced string) db.ForeignKeyInfo {
+		return db.ForeignKeyInfo{
+			Name:              name,
+			SourceTable:       source,
+			SourceColumns:     []string{column},
+			ReferencedTable:   referenced,
+			ReferencedColumns: []string{"id"},
+		}
+	}
+	return db.NewJoinGraph([]db.ForeignKeyInfo{
+		fk("fk_items_order", "order_items", "order_id", "orders"),
+		fk("fk_orders_customer", "orders", "customer_id", "customers"),
+		fk("fk_orders_created_by", "orders", "created_by", "users"),
+		fk("fk_orders_approved_by", "orders", "approved_by", "users"),
+		fk("fk_addresses_customer", "addresses", "customer_id", "customers"),
+		fk("fk_users_manager", "users", "manager_id", "users"),
+	})
+}
+
+// TestJoinGraph_ShortestPaths tests multi-hop paths over both directions of foreign keys
+func TestJoinGraph_ShortestPaths(t *testing.T) {
+	graph := joinTestGraph()
+
+	paths := graph.ShortestPaths([]string{"order_items"}, "addresses", 3)
+	if len(paths) != 1 {
+		t.Fatalf("Expected one path, got %d", len(paths))
+	}
+	want := "order_items -[fk_items_order]-> orders -[fk_orders_customer]-> customers <-[fk_addresses_customer]- addresses"
+	if got := db.FormatJoinPath(paths[0]); got != want {
+		t.Errorf("FormatJoinPath() = %s, want %s", got, want)
+	}
+	if got := paths[0][2].Predicate(); got != "customers.id = addresses.customer_id" {
+		t.Errorf("Predicate() = %s", got)
+	}
+
+	// Two foreign keys to users make two shortest paths
+	if paths := graph.ShortestPaths([]string{"order_items"}, "users", 3); len(paths) != 2 {
+		t.Errorf("Expected two paths to users, got %d", len(paths))
+	}
+
+	// Paths start from the nearest joined table
+	paths = graph.ShortestPaths([]string{"order_items", "customers"}, "addresses", 3)
+	if len(paths) != 1 || len(paths[0]) != 1 || paths[0][0].FromTable != "customers" {
+		t.Errorf("Expected a single join from customers, got %v", paths)
+	}
+
+	if paths := graph.ShortestPaths([]string{"order_items"}, "addresses", 2); paths != nil {
+		t.Errorf("Expected no path within 2 joins, got %v", paths)
+	}
+}
+
+// TestQueryBuilder_BuildJoinQuery tests join SQL with qualified columns and conditions
+func TestQueryBuilder_BuildJoinQuery(t *testing.T) {
+	paths := joinTestGraph().ShortestPaths([]string{"order_items"}, "customers", 3)
+	if len(paths) != 1 {
+		t.Fatalf("Expected one path, got %d", len(paths))
+	}
+
+	query, params, err := db.NewQueryBuilder("postgres").BuildJoinQuery(db.JoinSpec{
+		Root:      "order_items",
+		Steps:     paths[0],
+		FullNames: map[string]string{"order_items": "public.order_items", "orders": "public.orders", "customers": "public.customers"},
+		Columns: []db.JoinColumn{
+			{Table: "order_items", Column: "id"},
+			{Table: "customers", Column: "name"},
+		},
+		Conditions: map[string]any{"customers.country": "DE"},
+		OrderBy:    "orders.created_at DESC",
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `SELECT "order_items"."id" AS "order_items.id", "customers"."name" AS "customers.name"` +
+		` FROM "public"."order_items" INNER JOIN "public"."orders" ON "order_items"."order_id" = "orders"."id"` +
+		` INNER JOIN "public"."customers" ON "orders"."customer_id" = "customers"."id"` +
+		` WHERE "customers"."country" = $1 ORDER BY "orders"."created_at" DESC LIMIT 10`
+	if query != want {
+		t.Errorf("BuildJoinQuery() =\n%s\nwant\n%s", query, want)
+	}
+	if len(params) != 1 || params[0] != "DE" {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	// Columns, conditions and ordering must refer to joined tables
+	invalid := []db.JoinSpec{
+		{Root: "orders", Columns: []db.JoinColumn{{Table: "customers", Column: "name"}}},
+		{Root: "orders", Columns: []db.JoinColumn{{Table: "orders", Column: "id"}}, Conditions: map[string]any{"users.id": 1}},
+		{Root: "orders", Columns: []db.JoinColumn{{Table: "orders", Column: "id"}}, OrderBy: "orders.id; DROP TABLE orders"},
+	}
+	for _, spec := range invalid {
+		if query, _, err := db.NewQueryBuilder("mysql").BuildJoinQuery(spec); err == nil {
+			t.Errorf("Expected an error, got %s", query)
+		}
+	}
+}
+
+// TestQueryBuilder_BuildJoinClause tests the FROM clause of a join path
+func TestQueryBuilder_BuildJoinClause(t *testing.T) {
+	paths := joinTestGraph().ShortestPaths([]string{"customers"}, "order_items", 3)
+	if len(paths) != 1 {
+		t.Fatalf("Expected one path, got %d", len(paths))
+	}
+
+	clause, err := db.NewQueryBuilder("mysql").BuildJoinClause("customers", paths[0], nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FROM `customers` INNER JOIN `orders` ON `customers`.`id` = `orders`.`customer_id`" +
+		" INNER JOIN `order_items` ON `orders`.`id` = `order_items`.`order_id`"
+	if clause != want {
+		t.Errorf("BuildJoinClause() =\n%s\nwant\n%s", clause, want)
+	}
+}
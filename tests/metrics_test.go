@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/SkillingX/mcp-localbridge/cache"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+)
+
+// TestMetrics_CallStats tests that handlers can report rows through the context
+func TestMetrics_CallStats(t *testing.T) {
+	ctx, stats := metrics.WithCallStats(context.Background())
+
+	metrics.RecordRows(ctx, 10)
+	metrics.RecordRows(ctx, 5)
+
+	if stats.Rows() != 15 {
+		t.Errorf("Expected 15 rows, got %d", stats.Rows())
+	}
+
+	// Recording without call stats in the context must be a no-op
+	metrics.RecordRows(context.Background(), 3)
+}
+
+// TestMetrics_ObserveToolCall tests that tool calls update the exported series
+func TestMetrics_ObserveToolCall(t *testing.T) {
+	beforeRows := testutil.ToFloat64(metrics.RowsReturned.WithLabelValues("db_query", "metrics_test_db"))
+	beforeErrors := testutil.ToFloat64(metrics.ToolErrors.WithLabelValues("db_query", metrics.ErrorClassTimeout))
+
+	metrics.ObserveToolCall("db_query", "metrics_test_db", 20*time.Millisecond, 42, "")
+	metrics.ObserveToolCall("db_query", "metrics_test_db", time.Second, 0, metrics.ErrorClassTimeout)
+
+	if got := testutil.ToFloat64(metrics.RowsReturned.WithLabelValues("db_query", "metrics_test_db")) - beforeRows; got != 42 {
+		t.Errorf("Expected 42 rows returned, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ToolErrors.WithLabelValues("db_query", metrics.ErrorClassTimeout)) - beforeErrors; got != 1 {
+		t.Errorf("Expected 1 timeout error, got %v", got)
+	}
+}
+
+// TestMetrics_NewRegistry tests that the registry gathers without configured backends
+func TestMetrics_NewRegistry(t *testing.T) {
+	registry := metrics.NewRegistry(map[string]db.Repository{}, map[string]*cache.RedisClient{})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	if len(families) == 0 {
+		t.Errorf("Expected runtime metrics to be gathered")
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/middleware"
+)
+
+func newToolRequest(name string) mcp.CallToolRequest {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	return request
+}
+
+// TestMiddleware_ChainOrder tests that the first middleware is the outermost
+func TestMiddleware_ChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) middleware.ToolMiddleware {
+		return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	handler := middleware.Chain(record("a"), record("b"))(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "handler")
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	if _, err := handler(context.Background(), newToolRequest("test")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "a,b,handler" {
+		t.Errorf("Expected a,b,handler, got %s", got)
+	}
+}
+
+// TestMiddleware_RequestID tests that every call gets its own request ID
+func TestMiddleware_RequestID(t *testing.T) {
+	var ids []string
+	handler := middleware.RequestID()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids = append(ids, middleware.RequestIDFromContext(ctx))
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	handler(context.Background(), newToolRequest("test"))
+	handler(context.Background(), newToolRequest("test"))
+
+	if ids[0] == "" || ids[0] == ids[1] {
+		t.Errorf("Expected distinct non-empty request IDs, got %v", ids)
+	}
+}
+
+// TestMiddleware_Recovery tests that a panicking handler yields an error result
+func TestMiddleware_Recovery(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := middleware.Recovery(logger)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	})
+
+	result, err := handler(context.Background(), newToolRequest("test"))
+	if err != nil {
+		t.Fatalf("Expected panic to be converted to a result, got error: %v", err)
+	}
+	if result == nil || !result.IsError {
+		t.Fatalf("Expected an error result, got %+v", result)
+	}
+}
+
+// TestMiddleware_Timeout tests that slow handlers are cut off at the deadline
+func TestMiddleware_Timeout(t *testing.T) {
+	handler := middleware.Timeout(20 * time.Millisecond)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(time.Second)
+		return mcp.NewToolResultText("too late"), nil
+	})
+
+	start := time.Now()
+	result, err := handler(context.Background(), newToolRequest("test"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil || !result.IsError {
+		t.Fatalf("Expected a timeout error result, got %+v", result)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Timeout middleware did not return at the deadline")
+	}
+}
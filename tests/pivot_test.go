@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestQueryBuilder_BuildPivotQuery tests conditional aggregation with parameterized pivot values
+func TestQueryBuilder_BuildPivotQuery(t *testing.T) {
+	spec := db.PivotSpec{
+		Table:      "orders",
+		Rows:       db.PivotDimension{Column: "region"},
+		Columns:    db.PivotDimension{Column: "channel"},
+		Metric:     db.Metric{Fn: "SUM", Column: "amount"},
+		Conditions: map[string]any{"status": "paid"},
+	}
+
+	query, params, err := db.NewQueryBuilder("postgres").BuildPivotQuery(spec, []any{"web", nil}, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `SELECT row_key, SUM(CASE WHEN column_key = $1 THEN metric_value END) AS cell_0,` +
+		` SUM(CASE WHEN column_key IS NULL THEN metric_value END) AS cell_1, SUM(metric_value) AS total` +
+		` FROM (SELECT "region" AS row_key, "channel" AS column_key, "amount" AS metric_value FROM "orders" WHERE "status" = $2) AS pivot_source` +
+		` GROUP BY row_key ORDER BY row_key LIMIT 100`
+	if query != want {
+		t.Errorf("BuildPivotQuery() =\n%s\nwant\n%s", query, want)
+	}
+	if len(params) != 2 || params[0] != "web" || params[1] != "paid" {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	// Bucketed dimensions bind their time zone after the pivot values
+	spec.Columns = db.PivotDimension{Column: "created_at", Bucket: db.BucketMonth, TimeKind: db.TimeKindTimestamp}
+	spec.Metric = db.Metric{Fn: "COUNT"}
+	spec.Timezone = "UTC"
+	query, params, err = db.NewQueryBuilder("mysql").BuildPivotTotals(spec, []any{"2024-01-01 00:00:00"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = "SELECT COUNT(CASE WHEN column_key = ? THEN 1 END) AS cell_0, COUNT(1) AS total" +
+		" FROM (SELECT `region` AS row_key, DATE_FORMAT(CONVERT_TZ(`created_at`, '+00:00', ?), '%Y-%m-01 00:00:00') AS column_key" +
+		" FROM `orders` WHERE `status` = ?) AS pivot_source"
+	if query != want {
+		t.Errorf("BuildPivotTotals() =\n%s\nwant\n%s", query, want)
+	}
+	if len(params) != 3 || params[0] != "2024-01-01 00:00:00" || params[1] != "+00:00" {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	spec.Metric = db.Metric{Fn: "MEDIAN", Column: "amount"}
+	if query, _, err := db.NewQueryBuilder("postgres").BuildPivotQuery(spec, nil, 10); err == nil {
+		t.Errorf("Expected an error for percentiles, got %s", query)
+	}
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"strconv"
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestProfile_ColumnKind tests the classification of data types
+func TestProfile_ColumnKind(t *testing.T) {
+	tests := map[string]string{
+		"int":                      db.ColumnKindNumeric,
+		"bigint":                   db.ColumnKindNumeric,
+		"decimal":                  db.ColumnKindNumeric,
+		"double precision":         db.ColumnKindNumeric,
+		"varchar":                  db.ColumnKindString,
+		"character varying":        db.ColumnKindString,
+		"longtext":                 db.ColumnKindString,
+		"timestamp with time zone": db.ColumnKindTemporal,
+		"datetime":                 db.ColumnKindTemporal,
+		"interval":                 db.ColumnKindTemporal,
+		"boolean":                  db.ColumnKindBoolean,
+		"jsonb":                    db.ColumnKindOther,
+		"point":                    db.ColumnKindOther,
+	}
+
+	for dataType, want := range tests {
+		if got := db.ColumnKind(dataType); got != want {
+			t.Errorf("ColumnKind(%q) = %s, want %s", dataType, got, want)
+		}
+	}
+}
+
+// TestProfile_EstimateDistinct tests the HyperLogLog estimate on registers
+// built the way the sketch query builds them
+func TestProfile_EstimateDistinct(t *testing.T) {
+	for _, n := range []int{0, 100, 5000, 200000} {
+		registers := make(map[int]int)
+		for i := range n {
+			sum := md5.Sum([]byte("value-" + strconv.Itoa(i)))
+			h := binary.BigEndian.Uint32(sum[:4])
+			register := int(h & (1<<db.SketchPrecision - 1))
+			rank := bits.LeadingZeros32(h>>db.SketchPrecision) - db.SketchPrecision + 1
+			registers[register] = max(registers[register], rank)
+		}
+
+		got := db.EstimateDistinct(registers)
+		if math.Abs(float64(got)-float64(n)) > 0.1*float64(n) {
+			t.Errorf("EstimateDistinct() for %d values = %d, want within 10%%", n, got)
+		}
+	}
+}
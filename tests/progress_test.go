@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/middleware"
+	"github.com/SkillingX/mcp-localbridge/progress"
+)
+
+// progressSession is a minimal client session that buffers notifications
+type progressSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *progressSession) SessionID() string { return "progress-session" }
+func (s *progressSession) Initialize()       {}
+func (s *progressSession) Initialized() bool { return true }
+func (s *progressSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// TestProgress_Notifications tests that progress reaches callers with a progress token
+func TestProgress_Notifications(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	srv.AddTool(mcp.NewTool("slow"), middleware.Progress()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		progress.Report(ctx, 0, 2, "starting")
+		progress.Report(ctx, 1, 2, "throttled")
+		progress.Report(ctx, 2, 2, "done")
+		return mcp.NewToolResultText("ok"), nil
+	}))
+
+	session := &progressSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	ctx := context.Background()
+	if err := srv.RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+	ctx = srv.WithContext(ctx, session)
+
+	call := func(meta string) {
+		message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"` + meta + `}}`
+		response := srv.HandleMessage(ctx, json.RawMessage(message))
+		if _, ok := response.(mcp.JSONRPCResponse); !ok {
+			t.Fatalf("Unexpected response: %#v", response)
+		}
+	}
+
+	// Without a token no notifications are sent
+	call("")
+	if len(session.notifications) != 0 {
+		t.Fatalf("Expected no notifications without a progress token, got %d", len(session.notifications))
+	}
+
+	call(`,"_meta":{"progressToken":"job-1"}`)
+	close(session.notifications)
+
+	var received []map[string]any
+	for notification := range session.notifications {
+		if notification.Method == string(mcp.MethodNotificationProgress) {
+			received = append(received, notification.Params.AdditionalFields)
+		}
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 progress notifications, got %d: %v", len(received), received)
+	}
+	if received[0]["progressToken"] != "job-1" || received[1]["message"] != "done" {
+		t.Errorf("Unexpected progress notifications: %v", received)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+)
+
+// TestPrompts_BuiltInTemplates tests that every built-in prompt renders live data
+func TestPrompts_BuiltInTemplates(t *testing.T) {
+	templates, err := prompts.Load(config.PromptsConfig{})
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	schema := &db.TableInfo{
+		TableName: "orders",
+		Columns: []db.ColumnInfo{
+			{Name: "id", DataType: "int", IsPrimaryKey: true},
+			{Name: "user_id", DataType: "int"},
+		},
+	}
+	fks := []db.ForeignKeyInfo{{SourceTable: "orders", SourceColumn: "user_id", ReferencedTable: "users", ReferencedColumn: "id"}}
+	data := prompts.Data{
+		Database:      "shop",
+		Table:         "orders",
+		Question:      "How many orders per user?",
+		Schema:        schema,
+		Tables:        []db.TableInfo{*schema},
+		ForeignKeys:   fks,
+		Relationships: map[string][]db.ForeignKeyInfo{"orders": fks},
+		SampleData:    []map[string]any{{"id": 1, "user_id": 7}},
+	}
+
+	for _, name := range prompts.Names {
+		text, err := templates.Render(name, data)
+		if err != nil {
+			t.Errorf("Render(%s) error: %v", name, err)
+			continue
+		}
+		if !strings.Contains(text, "orders") || !strings.Contains(text, "shop") {
+			t.Errorf("Render(%s) is missing live schema:\n%s", name, text)
+		}
+	}
+}
+
+// TestPrompts_TemplateOverride tests overriding a prompt body from a file
+func TestPrompts_TemplateOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summarize_table.tmpl")
+	if err := os.WriteFile(path, []byte("Summarize {{.Database}}.{{.Table}} ({{len .SampleData}} rows)"), 0o600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	templates, err := prompts.Load(config.PromptsConfig{Templates: map[string]string{prompts.SummarizeTable: path}})
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	text, err := templates.Render(prompts.SummarizeTable, prompts.Data{Database: "shop", Table: "orders", SampleData: []map[string]any{{}}})
+	if err != nil {
+		t.Fatalf("Render error: %v", err)
+	}
+	if text != "Summarize shop.orders (1 rows)" {
+		t.Errorf("Unexpected override output: %q", text)
+	}
+
+	if _, err := prompts.Load(config.PromptsConfig{Templates: map[string]string{"unknown": path}}); err == nil {
+		t.Errorf("Expected error for unknown prompt name")
+	}
+}
+
+// TestPrompts_CompositeForeignKey tests that composite keys render as one relationship with their rules
+func TestPrompts_CompositeForeignKey(t *testing.T) {
+	templates, err := prompts.Load(config.PromptsConfig{})
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	fk := db.ForeignKeyInfo{
+		Name:              "fk_order_lines_order",
+		SourceTable:       "order_lines",
+		SourceColumn:      "order_id, region",
+		SourceColumns:     []string{"order_id", "region"},
+		ReferencedTable:   "orders",
+		ReferencedColumn:  "id, region",
+		ReferencedColumns: []string{"id", "region"},
+		OnDelete:          "CASCADE",
+		OnUpdate:          "NO ACTION",
+	}
+	text, err := templates.Render(prompts.SummarizeTable, prompts.Data{
+		Database:    "shop",
+		Table:       "order_lines",
+		Schema:      &db.TableInfo{TableName: "order_lines"},
+		ForeignKeys: []db.ForeignKeyInfo{fk},
+	})
+	if err != nil {
+		t.Fatalf("Render error: %v", err)
+	}
+
+	want := "order_lines.(order_id, region) -> orders.(id, region) ON DELETE CASCADE\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected %q in:\n%s", want, text)
+	}
+	if strings.Contains(text, "ON UPDATE") {
+		t.Errorf("Expected the default NO ACTION rule to be omitted:\n%s", text)
+	}
+}
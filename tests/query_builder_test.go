@@ -167,6 +167,51 @@ func TestQueryBuilder_BuildAggregation(t *testing.T) {
 	}
 }
 
+// TestQueryBuilder_BuildAggregationQuery tests multi-metric aggregation with HAVING and ordering
+func TestQueryBuilder_BuildAggregationQuery(t *testing.T) {
+	qb := db.NewQueryBuilder("postgres")
+
+	query, params, err := qb.BuildAggregationQuery(db.AggregationSpec{
+		Table: "orders",
+		Metrics: []db.Metric{
+			{Fn: "sum", Column: "amount", Alias: "revenue"},
+			{Fn: "COUNT(DISTINCT)", Column: "customer_id"},
+			{Fn: "COUNT"},
+		},
+		GroupBy:    []string{"region", "channel"},
+		Conditions: map[string]any{"status": []any{"paid", "shipped"}, "amount": map[string]any{">=": 10}},
+		Having:     []db.HavingFilter{{Metric: "revenue", Op: ">", Value: 1000}},
+		OrderBy:    "revenue DESC, region",
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `SELECT "region", "channel", SUM("amount") AS "revenue", COUNT(DISTINCT "customer_id") AS "count_distinct_customer_id", COUNT(*) AS "count"` +
+		` FROM "orders" WHERE "amount" >= $1 AND "status" IN ($2, $3) GROUP BY "region", "channel"` +
+		` HAVING SUM("amount") > $4 ORDER BY "revenue" DESC, "region" LIMIT 10`
+	if query != want {
+		t.Errorf("BuildAggregationQuery() =\n%s\nwant\n%s", query, want)
+	}
+	if len(params) != 4 || params[0] != 10 || params[1] != "paid" || params[3] != 1000 {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	// Names outside the result and unknown operators are rejected
+	invalid := []db.AggregationSpec{
+		{Table: "orders", Metrics: []db.Metric{{Fn: "SUM", Column: "amount"}}, OrderBy: "amount; DROP TABLE orders"},
+		{Table: "orders", Metrics: []db.Metric{{Fn: "SUM", Column: "amount"}}, Having: []db.HavingFilter{{Metric: "total", Op: ">", Value: 1}}},
+		{Table: "orders", Metrics: []db.Metric{{Fn: "SUM", Column: "amount"}}, Conditions: map[string]any{"amount": map[string]any{"; --": 1}}},
+		{Table: "orders", Metrics: []db.Metric{{Fn: "SUM", Column: "amount"}, {Fn: "AVG", Column: "amount", Alias: "sum_amount"}}},
+	}
+	for _, spec := range invalid {
+		if query, _, err := qb.BuildAggregationQuery(spec); err == nil {
+			t.Errorf("Expected an error, got %s", query)
+		}
+	}
+}
+
 // TestQueryBuilder_SQLInjectionPrevention tests that the query builder prevents SQL injection
 func TestQueryBuilder_SQLInjectionPrevention(t *testing.T) {
 	qb := db.NewQueryBuilder("mysql")
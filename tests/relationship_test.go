@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestInferForeignKeys tests foreign keys proposed from column names and types
+func TestInferForeignKeys(t *testing.T) {
+	column := func(name, dataType string, primary bool) db.ColumnInfo {
+		return db.ColumnInfo{Name: name, DataType: dataType, IsPrimaryKey: primary}
+	}
+	tables := map[string]*db.TableInfo{
+		"users":      {Columns: []db.ColumnInfo{column("id", "int", true), column("manager_id", "int", false)}},
+		"customers":  {Columns: []db.ColumnInfo{column("customer_id", "bigint", true), column("name", "varchar", false)}},
+		"categories": {Columns: []db.ColumnInfo{column("id", "int", true)}},
+		"orders": {Columns: []db.ColumnInfo{
+			column("id", "int", true),
+			column("customerId", "int", false),
+			column("created_by_user_id", "int", false),
+			column("user_id", "varchar", false),
+			column("category_id", "int", false),
+		}},
+	}
+	declared := []db.ForeignKeyInfo{{SourceTable: "orders", SourceColumn: "category_id", ReferencedTable: "categories"}}
+
+	inferred := db.InferForeignKeys(tables, declared, "")
+	got := make(map[string]db.InferredForeignKey)
+	for _, fk := range inferred {
+		got[fk.SourceTable+"."+fk.SourceColumn+" -> "+fk.ReferencedTable+"."+fk.ReferencedColumn] = fk
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 inferred foreign keys, got %+v", inferred)
+	}
+
+	customer, ok := got["orders.customerId -> customers.customer_id"]
+	if !ok || !customer.Inferred || customer.NameMatch != db.NameMatchExact || customer.TypeMatch != db.TypeMatchCompatible {
+		t.Errorf("Unexpected customer key: %+v", customer)
+	}
+	creator, ok := got["orders.created_by_user_id -> users.id"]
+	if !ok || creator.NameMatch != db.NameMatchRole || creator.TypeMatch != db.TypeMatchIdentical {
+		t.Errorf("Unexpected creator key: %+v", creator)
+	}
+	if creator.Confidence != 0.28 {
+		t.Errorf("Expected confidence 0.28 before sampling, got %v", creator.Confidence)
+	}
+
+	// A single table keeps its own columns and the keys referencing it
+	scoped := db.InferForeignKeys(tables, declared, "users")
+	if len(scoped) != 1 || scoped[0].SourceColumn != "created_by_user_id" {
+		t.Errorf("Expected only the key referencing users, got %+v", scoped)
+	}
+
+	// Sampled values raise the confidence or rule the key out
+	if !creator.ApplyContainment(100, 98) || creator.Confidence != 0.87 {
+		t.Errorf("Expected confidence 0.87 with 98%% containment, got %v", creator.Confidence)
+	}
+	if customer.ApplyContainment(100, 10) {
+		t.Error("Expected 10% containment to rule the key out")
+	}
+	if empty := scoped[0]; empty.ApplyContainment(0, 0) {
+		t.Error("Expected an empty sample to rule the key out")
+	}
+}
+
+// TestSingularPluralName tests the pluralization rules of inferred names
+func TestSingularPluralName(t *testing.T) {
+	tests := map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"address":  "addresses",
+		"box":      "boxes",
+		"status":   "statuses",
+		"person":   "people",
+		"day":      "days",
+	}
+	for singular, plural := range tests {
+		if got := db.PluralName(singular); got != plural {
+			t.Errorf("PluralName(%q) = %q, want %q", singular, got, plural)
+		}
+		if got := db.SingularName(plural); got != singular {
+			t.Errorf("SingularName(%q) = %q, want %q", plural, got, singular)
+		}
+	}
+}
+
+// TestQueryBuilder_BuildContainmentCheck tests the sampled containment query
+func TestQueryBuilder_BuildContainmentCheck(t *testing.T) {
+	query := db.NewQueryBuilder("postgres").BuildContainmentCheck("public.orders", "user_id", "public.users", "id", 1000)
+	want := `SELECT COUNT(*) AS sampled, COUNT("referenced_key"."id") AS matched FROM (SELECT DISTINCT sample_value FROM` +
+		` (SELECT "user_id" AS sample_value FROM "public"."orders" WHERE "user_id" IS NOT NULL LIMIT 1000) AS sample_rows) AS sample_values` +
+		` LEFT JOIN "public"."users" AS referenced_key ON "referenced_key"."id" = sample_values.sample_value`
+	if query != want {
+		t.Errorf("BuildContainmentCheck() =\n%s\nwant\n%s", query, want)
+	}
+}
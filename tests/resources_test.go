@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/resources"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// TestResources_Subscriptions tests subscription bookkeeping per session
+func TestResources_Subscriptions(t *testing.T) {
+	subs := resources.NewSubscriptions()
+	subs.Subscribe("s1", resources.TablesURI("shop"))
+	subs.Subscribe("s2", resources.TableSchemaURI("shop", "orders"))
+	subs.Subscribe("s2", resources.TablesURI("crm"))
+
+	if got := subs.Matching(resources.DatabaseURIPrefix("shop")); len(got) != 2 {
+		t.Fatalf("Expected 2 shop subscriptions, got %+v", got)
+	}
+
+	subs.Unsubscribe("s1", resources.TablesURI("shop"))
+	subs.RemoveSession("s2")
+	if got := subs.Matching("db://"); len(got) != 0 {
+		t.Errorf("Expected no subscriptions left, got %+v", got)
+	}
+}
+
+// TestResources_MermaidERD tests rendering of the foreign key graph
+func TestResources_MermaidERD(t *testing.T) {
+	graph := map[string][]db.ForeignKeyInfo{
+		"orders": {{SourceTable: "orders", SourceColumn: "user_id", ReferencedTable: "users", ReferencedColumn: "id"}},
+	}
+
+	erd := resources.BuildMermaidERD(graph)
+	if !strings.HasPrefix(erd, "erDiagram\n") || !strings.Contains(erd, `orders }o--|| users : "user_id -> id"`) {
+		t.Errorf("Unexpected Mermaid output:\n%s", erd)
+	}
+}
+
+// TestResources_TemplateRead tests that templated URIs reach the handler and
+// surface typed errors for unknown databases
+func TestResources_TemplateRead(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	repos := make(map[string]db.Repository)
+	handler := resources.NewSchemaResourceHandler(
+		insights.NewIntrospectionHandler(repos, nil, config.IntrospectionConfig{}, logger),
+		insights.NewRelationshipHandler(repos, nil, config.RelationshipConfig{}, nil, logger),
+		logger,
+	)
+
+	srv := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, false))
+	srv.AddResourceTemplate(mcp.NewResourceTemplate(resources.TableSchemaURITemplate, "Table Schema"), handler.HandleTableSchema)
+
+	request, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "resources/read",
+		"params":  map[string]any{"uri": resources.TableSchemaURI("missing", "users")},
+	})
+	response := srv.HandleMessage(context.Background(), request)
+
+	errResponse, ok := response.(mcp.JSONRPCError)
+	if !ok {
+		t.Fatalf("Expected JSON-RPC error, got %T", response)
+	}
+	if !strings.Contains(errResponse.Error.Message, string(toolerrors.CodeDatabaseNotFound)) {
+		t.Errorf("Expected database not found error, got %q", errResponse.Error.Message)
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestQueryBuilder_HashSample tests reproducible hash sampling on the primary key
+func TestQueryBuilder_HashSample(t *testing.T) {
+	tests := []struct {
+		driver   string
+		fraction float64
+		want     string
+	}{
+		{
+			driver:   "mysql",
+			fraction: 1,
+			want:     "SELECT * FROM `orders` ORDER BY CAST(CONV(LEFT(MD5(CONCAT_WS('|', ?, `id`)), 8), 16, 10) AS UNSIGNED) LIMIT 10",
+		},
+		{
+			driver:   "postgres",
+			fraction: 0.25,
+			want: `SELECT * FROM "orders" WHERE ('x' || substr(md5((CONCAT_WS('|', $1::text, "id"))::text), 1, 8))::bit(32)::bigint < 1073741824` +
+				` ORDER BY ('x' || substr(md5((CONCAT_WS('|', $1::text, "id"))::text), 1, 8))::bit(32)::bigint LIMIT 10`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			qb := db.NewQueryBuilder(tt.driver)
+			query, params := qb.BuildHashSample(qb.BuildSampleSource("orders", nil, 0, 1), []string{"id"}, "seed", tt.fraction, 10)
+			if query != tt.want {
+				t.Errorf("BuildHashSample() = %s, want %s", query, tt.want)
+			}
+			if len(params) != 1 || params[0] != "seed" {
+				t.Errorf("BuildHashSample() params = %v, want [seed]", params)
+			}
+		})
+	}
+}
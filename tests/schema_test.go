@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// schemaRepository is a repository stub that only reports its schemas
+type schemaRepository struct {
+	db.Repository
+	schemas []string
+}
+
+func (r schemaRepository) Schemas() []string { return r.schemas }
+
+func (r schemaRepository) GetName() string { return "postgres_main" }
+
+// TestQueryBuilder_QualifiedNames tests that schema.table names are quoted part by part
+func TestQueryBuilder_QualifiedNames(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{driver: "mysql", want: "SELECT * FROM `sales`.`orders` LIMIT 5"},
+		{driver: "postgres", want: `SELECT * FROM "sales"."orders" LIMIT 5`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			query, _ := db.NewQueryBuilder(tt.driver).BuildSelect("sales.orders", nil, 5, 0, "")
+			if query != tt.want {
+				t.Errorf("BuildSelect() = %s, want %s", query, tt.want)
+			}
+		})
+	}
+}
+
+// TestSchema_ResolveTableName tests combining table and schema arguments
+func TestSchema_ResolveTableName(t *testing.T) {
+	repo := schemaRepository{schemas: []string{"public", "sales"}}
+
+	tests := []struct {
+		name    string
+		schema  string
+		table   string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare table", table: "orders", want: "orders"},
+		{name: "default schema argument", schema: "public", table: "orders", want: "orders"},
+		{name: "schema argument", schema: "sales", table: "orders", want: "sales.orders"},
+		{name: "qualified table", table: "sales.orders", want: "sales.orders"},
+		{name: "qualified default schema", table: "public.orders", want: "orders"},
+		{name: "qualified table and schema", schema: "sales", table: "sales.orders", wantErr: true},
+		{name: "too many parts", table: "main.sales.orders", wantErr: true},
+		{name: "unconfigured schema", schema: "hr", table: "staff", wantErr: true},
+		{name: "unconfigured qualified schema", table: "hr.staff", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.ResolveTableName(repo, tt.schema, tt.table)
+			if tt.wantErr {
+				var te *toolerrors.ToolError
+				if !errors.As(err, &te) || te.Code != toolerrors.CodeInvalidArgument {
+					t.Fatalf("Expected INVALID_ARGUMENT, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTableName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Unknown schemas point at the configured ones
+	_, err := db.ResolveTableName(repo, "sale", "orders")
+	var te *toolerrors.ToolError
+	if !errors.As(err, &te) || !strings.Contains(te.Hint, "sales") {
+		t.Errorf("Expected a hint suggesting 'sales', got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestQueryBuilder_StatisticalMetrics tests percentile and statistical metrics per driver
+func TestQueryBuilder_StatisticalMetrics(t *testing.T) {
+	spec := db.AggregationSpec{
+		Table: "orders",
+		Metrics: []db.Metric{
+			{Fn: "MEDIAN", Column: "amount"},
+			{Fn: "PERCENTILE(95)", Column: "amount"},
+			{Fn: "STDDEV", Column: "amount"},
+			{Fn: "APPROX_COUNT_DISTINCT", Column: "customer_id"},
+		},
+		GroupBy:    []string{"region"},
+		Conditions: map[string]any{"status": "paid"},
+	}
+
+	query, _, err := db.NewQueryBuilder("postgres").BuildAggregationQuery(spec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `SELECT "region", percentile_cont(0.5) WITHIN GROUP (ORDER BY "amount") AS "median_amount",` +
+		` percentile_cont(0.95) WITHIN GROUP (ORDER BY "amount") AS "p95_amount", STDDEV_SAMP("amount") AS "stddev_amount",` +
+		` COUNT(DISTINCT "customer_id") AS "approx_count_distinct_customer_id"` +
+		` FROM "orders" WHERE "status" = $1 GROUP BY "region"`
+	if query != want {
+		t.Errorf("BuildAggregationQuery() =\n%s\nwant\n%s", query, want)
+	}
+
+	// MySQL ranks each percentile column once, filtering inside the derived table
+	qb := db.NewQueryBuilder("mysql")
+	query, _, err = qb.BuildAggregationQuery(spec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantFrom := " FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY `region` ORDER BY `amount` IS NULL, `amount`) AS `_pct_rank_0`," +
+		" COUNT(`amount`) OVER (PARTITION BY `region`) AS `_pct_count_0` FROM `orders` WHERE `status` = ?) AS ranked GROUP BY `region`"
+	if !strings.Contains(query, wantFrom) || strings.Count(query, "ROW_NUMBER()") != 1 {
+		t.Errorf("Unexpected MySQL query: %s", query)
+	}
+
+	methods := map[string]string{}
+	for _, metric := range spec.Metrics {
+		m, err := db.NormalizeMetric(metric)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		methods[m.Fn] = qb.MetricMethod(m)
+	}
+	if methods["MEDIAN"] != db.MethodWindow || methods["STDDEV"] != db.MethodNative || methods["APPROX_COUNT_DISTINCT"] != db.MethodExact {
+		t.Errorf("Unexpected methods: %v", methods)
+	}
+
+	// Ungrouped approximate distinct counts are sketched over the filtered rows
+	sketch, params, err := qb.BuildDistinctSketchWhere("orders", "customer_id", spec.Conditions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(sketch, "WHERE `customer_id` IS NOT NULL AND `status` = ?") || len(params) != 1 || params[0] != "paid" {
+		t.Errorf("Unexpected sketch query: %s %v", sketch, params)
+	}
+
+	for _, invalid := range []db.Metric{
+		{Fn: "PERCENTILE", Column: "amount"},
+		{Fn: "PERCENTILE", Column: "amount", P: 150},
+		{Fn: "SUM", Column: "amount", P: 0.5},
+		{Fn: "MEDIAN"},
+	} {
+		if m, err := db.NormalizeMetric(invalid); err == nil {
+			t.Errorf("Expected an error for %+v, got %+v", invalid, m)
+		}
+	}
+}
+
+// TestPercentileCont tests percentile interpolation against percentile_cont
+func TestPercentileCont(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 10}
+	tests := map[float64]float64{0: 1, 0.5: 3, 0.9: 7.6, 1: 10}
+	for p, want := range tests {
+		if got, ok := db.PercentileCont(values, p); !ok || got < want-1e-9 || got > want+1e-9 {
+			t.Errorf("PercentileCont(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if _, ok := db.PercentileCont(nil, 0.5); ok {
+		t.Error("Expected no percentile of no values")
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/insights"
+	"github.com/SkillingX/mcp-localbridge/prompts"
+)
+
+// fakeSampler answers every sampling request with a fixed text
+type fakeSampler struct {
+	requests int
+}
+
+func (f *fakeSampler) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return f.CreateMessage(ctx, request)
+}
+
+func (f *fakeSampler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	f.requests++
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("Orders placed by users.")},
+		Model:           "test-model",
+	}, nil
+}
+
+// TestSummarizer_SamplingAndFallback tests sampled summaries and the template fallback
+func TestSummarizer_SamplingAndFallback(t *testing.T) {
+	templates, err := prompts.Load(config.PromptsConfig{})
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	sampler := &fakeSampler{}
+	summarizer := insights.NewSummarizer(sampler, templates, nil, config.SummarizationConfig{Enabled: true}, logger)
+
+	schema := &db.TableInfo{TableName: "orders", Columns: []db.ColumnInfo{{Name: "id", DataType: "int"}}}
+	data := prompts.Data{Database: "shop", Table: "orders", Schema: schema}
+
+	// Client without sampling: the rendered prompt is returned
+	summary, err := summarizer.Summarize(context.Background(), prompts.SummarizeTable, "shop", "orders", schema, data)
+	if err != nil {
+		t.Fatalf("Summarize error: %v", err)
+	}
+	if summary.Source != insights.SummarySourceTemplate || summary.Prompt == "" || sampler.requests != 0 {
+		t.Errorf("Expected template fallback, got %+v", summary)
+	}
+
+	// Client with sampling: the model answers
+	ctx := server.WithInProcessSamplingHandler(context.Background(), sampler)
+	summary, err = summarizer.Summarize(ctx, prompts.SummarizeTable, "shop", "orders", schema, data)
+	if err != nil {
+		t.Fatalf("Summarize error: %v", err)
+	}
+	if summary.Source != insights.SummarySourceSampling || summary.Text != "Orders placed by users." || summary.Model != "test-model" {
+		t.Errorf("Expected sampled summary, got %+v", summary)
+	}
+
+	// Schema hashes are stable for equal schemas
+	first, _ := insights.SchemaHash(schema)
+	second, _ := insights.SchemaHash(&db.TableInfo{TableName: "orders", Columns: []db.ColumnInfo{{Name: "id", DataType: "int"}}})
+	if first != second || first != summary.SchemaHash {
+		t.Errorf("Expected stable schema hash, got %s, %s and %s", first, second, summary.SchemaHash)
+	}
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+)
+
+// TestQueryBuilder_BuildTimeseriesQuery tests time bucketing per driver and column kind
+func TestQueryBuilder_BuildTimeseriesQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		driver     string
+		spec       db.TimeseriesSpec
+		want       string
+		wantParams int
+	}{
+		{
+			name:   "postgres timestamptz by day",
+			driver: "postgres",
+			spec: db.TimeseriesSpec{
+				TimeKind: db.TimeKindTimestampTZ, Bucket: db.BucketDay, Timezone: "Europe/Berlin",
+				From: int64(1700000000),
+			},
+			want: `SELECT date_trunc('day', "created_at" AT TIME ZONE $1) AS bucket, COUNT(*) AS "count" FROM "orders"` +
+				` WHERE "created_at" IS NOT NULL AND "created_at" >= to_timestamp($2) GROUP BY 1 ORDER BY 1 LIMIT 100`,
+			wantParams: 2,
+		},
+		{
+			name:   "postgres offset with split",
+			driver: "postgres",
+			spec: db.TimeseriesSpec{
+				TimeKind: db.TimeKindTimestamp, Bucket: db.BucketHour, Timezone: "+08:00", SplitBy: "region",
+			},
+			want: `SELECT date_trunc('hour', ("created_at" AT TIME ZONE 'UTC') AT TIME ZONE $1::interval) AS bucket, "region", COUNT(*) AS "count" FROM "orders"` +
+				` WHERE "created_at" IS NOT NULL GROUP BY 1, 2 ORDER BY 1, 2 LIMIT 100`,
+			wantParams: 1,
+		},
+		{
+			name:   "mysql datetime by week",
+			driver: "mysql",
+			spec: db.TimeseriesSpec{
+				TimeKind: db.TimeKindTimestamp, Bucket: db.BucketWeek, Timezone: "UTC",
+				To: "2024-01-01 00:00:00",
+			},
+			want: "SELECT DATE_FORMAT(DATE_SUB(CONVERT_TZ(`created_at`, '+00:00', ?), INTERVAL WEEKDAY(CONVERT_TZ(`created_at`, '+00:00', ?)) DAY), '%Y-%m-%d 00:00:00') AS bucket, COUNT(*) AS `count` FROM `orders`" +
+				" WHERE `created_at` IS NOT NULL AND `created_at` < ? GROUP BY 1 ORDER BY 1 LIMIT 100",
+			wantParams: 3,
+		},
+		{
+			name:       "mysql date by month",
+			driver:     "mysql",
+			spec:       db.TimeseriesSpec{TimeKind: db.TimeKindDate, Bucket: db.BucketMonth, Timezone: "UTC"},
+			want:       "SELECT DATE_FORMAT(`created_at`, '%Y-%m-01 00:00:00') AS bucket, COUNT(*) AS `count` FROM `orders` WHERE `created_at` IS NOT NULL GROUP BY 1 ORDER BY 1 LIMIT 100",
+			wantParams: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Table, tt.spec.TimeColumn, tt.spec.Limit = "orders", "created_at", 100
+			tt.spec.Metrics = []db.Metric{{Fn: "COUNT"}}
+
+			query, params, err := db.NewQueryBuilder(tt.driver).BuildTimeseriesQuery(tt.spec)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if query != tt.want {
+				t.Errorf("BuildTimeseriesQuery() =\n%s\nwant\n%s", query, tt.want)
+			}
+			if len(params) != tt.wantParams {
+				t.Errorf("Expected %d params, got %v", tt.wantParams, params)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+)
+
+// TestToolArgs_Object tests that object arguments accept both typed and stringified values
+func TestToolArgs_Object(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     any
+		wantLen   int
+		wantError bool
+	}{
+		{name: "Typed object", value: map[string]any{"status": "active", "age": float64(25)}, wantLen: 2},
+		{name: "Stringified object", value: `{"status":"active"}`, wantLen: 1},
+		{name: "Empty string", value: "", wantLen: 0},
+		{name: "Missing", value: nil, wantLen: 0},
+		{name: "Invalid JSON string", value: `{invalid}`, wantError: true},
+		{name: "Wrong type", value: float64(3), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.value != nil {
+				args["conditions"] = tt.value
+			}
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "db_query", Arguments: args}}
+
+			obj, err := toolargs.Object(request, "conditions")
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", obj)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(obj) != tt.wantLen {
+				t.Errorf("Expected %d entries, got %d", tt.wantLen, len(obj))
+			}
+		})
+	}
+}
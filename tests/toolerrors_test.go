@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+// TestToolErrors_FromDBError tests mapping of driver errors to error codes
+func TestToolErrors_FromDBError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   toolerrors.Code
+		wantTarget string
+		retryable  bool
+	}{
+		{
+			name:       "MySQL unknown column",
+			err:        &mysql.MySQLError{Number: 1054, Message: "Unknown column 'emial' in 'where clause'"},
+			wantCode:   toolerrors.CodeUnknownColumn,
+			wantTarget: "emial",
+		},
+		{
+			name:       "MySQL unknown table",
+			err:        &mysql.MySQLError{Number: 1146, Message: "Table 'shop.userz' doesn't exist"},
+			wantCode:   toolerrors.CodeUnknownTable,
+			wantTarget: "userz",
+		},
+		{
+			name:     "MySQL access denied",
+			err:      &mysql.MySQLError{Number: 1142, Message: "SELECT command denied"},
+			wantCode: toolerrors.CodePermissionDenied,
+		},
+		{
+			name:      "MySQL too many connections",
+			err:       &mysql.MySQLError{Number: 1040, Message: "Too many connections"},
+			wantCode:  toolerrors.CodeRateLimited,
+			retryable: true,
+		},
+		{
+			name:       "Postgres undefined column",
+			err:        &pq.Error{Code: "42703", Message: `column "emial" does not exist`},
+			wantCode:   toolerrors.CodeUnknownColumn,
+			wantTarget: "emial",
+		},
+		{
+			name:      "Postgres statement timeout",
+			err:       &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"},
+			wantCode:  toolerrors.CodeQueryTimeout,
+			retryable: true,
+		},
+		{
+			name:      "Postgres connection exception class",
+			err:       &pq.Error{Code: "08006", Message: "connection failure"},
+			wantCode:  toolerrors.CodeBackendUnavailable,
+			retryable: true,
+		},
+		{
+			name:      "Wrapped context deadline",
+			err:       fmt.Errorf("query: %w", context.DeadlineExceeded),
+			wantCode:  toolerrors.CodeQueryTimeout,
+			retryable: true,
+		},
+		{
+			name:     "Unknown error",
+			err:      errors.New("something odd"),
+			wantCode: toolerrors.CodeQueryFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := toolerrors.FromDBError(tt.err)
+			if te.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, te.Code)
+			}
+			if te.Target != tt.wantTarget {
+				t.Errorf("Expected target %q, got %q", tt.wantTarget, te.Target)
+			}
+			if te.Retryable != tt.retryable {
+				t.Errorf("Expected retryable=%v, got %v", tt.retryable, te.Retryable)
+			}
+		})
+	}
+}
+
+// TestToolErrors_Suggestions tests nearest-name hints
+func TestToolErrors_Suggestions(t *testing.T) {
+	te := toolerrors.New(toolerrors.CodeUnknownColumn, "unknown column").
+		WithTarget("emial").
+		WithSuggestions([]string{"id", "email", "name", "created_at"})
+
+	if len(te.Suggestions) == 0 || te.Suggestions[0] != "email" {
+		t.Errorf("Expected 'email' as first suggestion, got %v", te.Suggestions)
+	}
+	if te.Hint == "" {
+		t.Errorf("Expected a hint listing suggestions")
+	}
+}
+
+// TestToolErrors_NotFound tests the generalized not-found error
+func TestToolErrors_NotFound(t *testing.T) {
+	te := toolerrors.NotFound(toolerrors.CodeDatabaseNotFound, "database", "mysql_mian", []string{"postgres_main", "mysql_main"})
+
+	if te.Code != toolerrors.CodeDatabaseNotFound {
+		t.Errorf("Expected DATABASE_NOT_FOUND, got %s", te.Code)
+	}
+	if len(te.Suggestions) == 0 || te.Suggestions[0] != "mysql_main" {
+		t.Errorf("Expected 'mysql_main' as first suggestion, got %v", te.Suggestions)
+	}
+}
+
+// TestToolErrors_Result tests that error results carry structured content
+func TestToolErrors_Result(t *testing.T) {
+	result := toolerrors.Result(toolerrors.New(toolerrors.CodeRateLimited, "slow down"))
+
+	if !result.IsError {
+		t.Errorf("Expected IsError to be set")
+	}
+	te, ok := result.StructuredContent.(*toolerrors.ToolError)
+	if !ok {
+		t.Fatalf("Expected *ToolError structured content, got %T", result.StructuredContent)
+	}
+	if te.Code != toolerrors.CodeRateLimited || !te.Retryable {
+		t.Errorf("Unexpected structured error: %+v", te)
+	}
+}
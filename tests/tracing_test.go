@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/tracing"
+)
+
+// TestTracing_LogHandlerAddsTraceIDs tests that log records inside a span carry trace and span IDs
+func TestTracing_LogHandlerAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test-span")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to parse log record: %v", err)
+	}
+
+	if record["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected trace_id %s, got %v", span.SpanContext().TraceID(), record["trace_id"])
+	}
+	if record["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("Expected span_id %s, got %v", span.SpanContext().SpanID(), record["span_id"])
+	}
+}
+
+// TestTracing_LogHandlerWithoutSpan tests that records outside a span are left untouched
+func TestTracing_LogHandlerWithoutSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.InfoContext(context.Background(), "no span")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to parse log record: %v", err)
+	}
+	if _, ok := record["trace_id"]; ok {
+		t.Errorf("Expected no trace_id outside a span, got %v", record["trace_id"])
+	}
+}
+
+// TestTracing_BuildSpan tests that query construction is traced with the built statement
+func TestTracing_BuildSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	built := db.StartBuildSpan(context.Background(), "select")
+	query, params := db.NewQueryBuilder("postgres").BuildSelect("orders", map[string]any{"status": "paid"}, 10, 0, "")
+	built(query, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "query_builder.select" {
+		t.Fatalf("Expected one query_builder.select span, got %v", spans)
+	}
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == tracing.AttrDBStatement && attr.Value.AsString() != query {
+			t.Errorf("Expected statement %q, got %q", query, attr.Value.AsString())
+		}
+		if attr.Value.AsString() == params[0] {
+			t.Errorf("Expected no parameter values on the span, got %v", attr)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package toolargs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Object returns an object argument by key. Older clients sent objects as
+// JSON-encoded strings, so a string value is decoded as JSON.
+// A missing or empty argument returns a nil map.
+func Object(request mcp.CallToolRequest, key string) (map[string]any, error) {
+	val, ok := request.GetArguments()[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	switch v := val.(type) {
+	case map[string]any:
+		return v, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(v), &obj); err != nil {
+			return nil, fmt.Errorf("invalid %s JSON: %w", key, err)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("argument %q must be an object, got %T", key, val)
+	}
+}
+
+// StringList returns a list argument by key. Besides an array of strings it
+// accepts a comma-separated string, the form older clients sent.
+// A missing or empty argument returns nil.
+func StringList(request mcp.CallToolRequest, key string) ([]string, error) {
+	val, ok := request.GetArguments()[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	var list []string
+	switch v := val.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("argument %q must be an array of strings, got %T item", key, item)
+			}
+			list = append(list, s)
+		}
+	default:
+		return nil, fmt.Errorf("argument %q must be an array of strings, got %T", key, val)
+	}
+	return list, nil
+}
+
+// Decode decodes an argument into dst, which must be a pointer. Like Object,
+// it accepts the value JSON-encoded as a string. Unknown object fields are
+// rejected, so misspelled keys are reported. A missing argument leaves dst unchanged.
+func Decode(request mcp.CallToolRequest, key string, dst any) error {
+	val, ok := request.GetArguments()[key]
+	if !ok || val == nil {
+		return nil
+	}
+
+	var raw []byte
+	if v, ok := val.(string); ok {
+		if v == "" {
+			return nil
+		}
+		raw = []byte(v)
+	} else {
+		var err error
+		if raw, err = json.Marshal(val); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
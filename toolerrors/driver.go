@@ -0,0 +1,231 @@
+package toolerrors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	singleQuoted = regexp.MustCompile(`'([^']+)'`)
+	doubleQuoted = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// mysqlCodes maps MySQL server error numbers to error codes
+var mysqlCodes = map[uint16]Code{
+	1044: CodePermissionDenied,   // ER_DBACCESS_DENIED_ERROR
+	1045: CodePermissionDenied,   // ER_ACCESS_DENIED_ERROR
+	1142: CodePermissionDenied,   // ER_TABLEACCESS_DENIED_ERROR
+	1143: CodePermissionDenied,   // ER_COLUMNACCESS_DENIED_ERROR
+	1227: CodePermissionDenied,   // ER_SPECIFIC_ACCESS_DENIED_ERROR
+	1290: CodePermissionDenied,   // ER_OPTION_PREVENTS_STATEMENT (e.g. --read-only)
+	1049: CodeDatabaseNotFound,   // ER_BAD_DB_ERROR
+	1146: CodeUnknownTable,       // ER_NO_SUCH_TABLE
+	1054: CodeUnknownColumn,      // ER_BAD_FIELD_ERROR
+	1064: CodeInvalidQuery,       // ER_PARSE_ERROR
+	1292: CodeInvalidArgument,    // ER_TRUNCATED_WRONG_VALUE
+	1366: CodeInvalidArgument,    // ER_TRUNCATED_WRONG_VALUE_FOR_FIELD
+	1205: CodeQueryTimeout,       // ER_LOCK_WAIT_TIMEOUT
+	1317: CodeQueryTimeout,       // ER_QUERY_INTERRUPTED
+	3024: CodeQueryTimeout,       // ER_QUERY_TIMEOUT (max_execution_time)
+	1213: CodeConflict,           // ER_LOCK_DEADLOCK
+	1040: CodeRateLimited,        // ER_CON_COUNT_ERROR
+	1203: CodeRateLimited,        // ER_TOO_MANY_USER_CONNECTIONS
+	1226: CodeRateLimited,        // ER_USER_LIMIT_REACHED
+	1053: CodeBackendUnavailable, // ER_SERVER_SHUTDOWN
+}
+
+// postgresCodes maps Postgres SQLSTATE codes to error codes
+var postgresCodes = map[string]Code{
+	"42501": CodePermissionDenied,   // insufficient_privilege
+	"28000": CodePermissionDenied,   // invalid_authorization_specification
+	"28P01": CodePermissionDenied,   // invalid_password
+	"25006": CodePermissionDenied,   // read_only_sql_transaction
+	"3D000": CodeDatabaseNotFound,   // invalid_catalog_name
+	"42P01": CodeUnknownTable,       // undefined_table
+	"42703": CodeUnknownColumn,      // undefined_column
+	"57014": CodeQueryTimeout,       // query_canceled (statement_timeout)
+	"55P03": CodeQueryTimeout,       // lock_not_available
+	"40001": CodeConflict,           // serialization_failure
+	"40P01": CodeConflict,           // deadlock_detected
+	"53300": CodeRateLimited,        // too_many_connections
+	"57P01": CodeBackendUnavailable, // admin_shutdown
+	"57P02": CodeBackendUnavailable, // crash_shutdown
+	"57P03": CodeBackendUnavailable, // cannot_connect_now
+}
+
+// postgresClassCodes maps SQLSTATE classes (first two characters) to error
+// codes for states not listed in postgresCodes
+var postgresClassCodes = map[string]Code{
+	"08": CodeBackendUnavailable, // connection_exception
+	"22": CodeInvalidArgument,    // data_exception
+	"42": CodeInvalidQuery,       // syntax_error_or_access_rule_violation
+	"53": CodeBackendUnavailable, // insufficient_resources
+}
+
+// FromDBError classifies an error returned by database/sql and the MySQL or
+// Postgres drivers
+func FromDBError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+
+	if e := fromContextError(err); e != nil {
+		return e
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		code, ok := mysqlCodes[mysqlErr.Number]
+		if !ok {
+			code = CodeQueryFailed
+		}
+		e := Wrap(code, err, "query failed")
+		if code == CodeUnknownColumn || code == CodeUnknownTable {
+			e.Target = unqualify(firstMatch(singleQuoted, mysqlErr.Message))
+		}
+		return withDefaultHint(e)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		state := string(pqErr.Code)
+		code, ok := postgresCodes[state]
+		if !ok {
+			code, ok = postgresClassCodes[state[:min(2, len(state))]]
+		}
+		if !ok {
+			code = CodeQueryFailed
+		}
+		e := Wrap(code, err, "query failed")
+		switch code {
+		case CodeUnknownColumn:
+			e.Target = pqErr.Column
+			if e.Target == "" {
+				e.Target = unqualify(firstMatch(doubleQuoted, pqErr.Message))
+			}
+		case CodeUnknownTable:
+			e.Target = unqualify(firstMatch(doubleQuoted, pqErr.Message))
+		}
+		if pqErr.Hint != "" {
+			e.Hint = pqErr.Hint
+		}
+		return withDefaultHint(e)
+	}
+
+	if isConnectionError(err) {
+		return withDefaultHint(Wrap(CodeBackendUnavailable, err, "database unavailable"))
+	}
+
+	return Wrap(CodeQueryFailed, err, "query failed")
+}
+
+// FromRedisError classifies an error returned by go-redis
+func FromRedisError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+
+	if e := fromContextError(err); e != nil {
+		return e
+	}
+
+	// go-redis does not export its pool timeout error, so match on the message
+	if errors.Is(err, redis.ErrClosed) || strings.Contains(err.Error(), "connection pool timeout") || isConnectionError(err) {
+		return withDefaultHint(Wrap(CodeBackendUnavailable, err, "redis unavailable"))
+	}
+
+	// Redis replies with an upper-case error prefix, e.g. "NOPERM ..."
+	prefix, _, _ := strings.Cut(err.Error(), " ")
+	switch prefix {
+	case "NOPERM", "NOAUTH", "WRONGPASS", "READONLY":
+		return withDefaultHint(Wrap(CodePermissionDenied, err, "redis command rejected"))
+	case "LOADING", "BUSY", "MASTERDOWN", "TRYAGAIN", "CLUSTERDOWN":
+		return withDefaultHint(Wrap(CodeBackendUnavailable, err, "redis unavailable"))
+	case "OOM":
+		return Wrap(CodeBackendUnavailable, err, "redis out of memory")
+	case "WRONGTYPE":
+		return Wrap(CodeInvalidArgument, err, "redis command failed")
+	}
+
+	return Wrap(CodeQueryFailed, err, "redis command failed")
+}
+
+// fromContextError classifies deadline and cancellation errors
+func fromContextError(err error) *ToolError {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return withDefaultHint(Wrap(CodeQueryTimeout, err, "query timed out"))
+	case errors.Is(err, context.Canceled):
+		return Wrap(CodeCanceled, err, "request canceled")
+	default:
+		return nil
+	}
+}
+
+// isConnectionError reports whether err indicates a broken or refused connection
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// withDefaultHint fills in a generic hint for the error code if none is set
+func withDefaultHint(e *ToolError) *ToolError {
+	if e.Hint != "" {
+		return e
+	}
+	switch e.Code {
+	case CodeQueryTimeout:
+		e.Hint = "Narrow the query with conditions or a smaller limit, then retry."
+	case CodePermissionDenied:
+		e.Hint = "The configured database user lacks privileges for this operation."
+	case CodeRateLimited:
+		e.Hint = "The backend is at its connection limit; retry after a short delay."
+	case CodeBackendUnavailable:
+		e.Hint = "The backend could not be reached; retry after a short delay."
+	case CodeConflict:
+		e.Hint = "The statement conflicted with a concurrent transaction; retry it."
+	case CodeUnknownTable:
+		e.Hint = "Use db_table_list to see the available tables."
+	case CodeUnknownColumn:
+		e.Hint = "Use semantic_summary or introspection to see the table's columns."
+	}
+	return e
+}
+
+// firstMatch returns the first capture group of re in s
+func firstMatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// unqualify strips schema or table qualifiers from an identifier
+func unqualify(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
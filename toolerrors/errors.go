@@ -0,0 +1,158 @@
+package toolerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Code identifies a class of tool failure that clients can act on
+type Code string
+
+// Error codes returned in tool error results
+const (
+	CodeInvalidArgument    Code = "INVALID_ARGUMENT"
+	CodeInvalidIdentifier  Code = "INVALID_IDENTIFIER"
+	CodeInvalidQuery       Code = "INVALID_QUERY"
+	CodeDatabaseNotFound   Code = "DATABASE_NOT_FOUND"
+	CodeRedisNotFound      Code = "REDIS_NOT_FOUND"
+	CodeUnknownTable       Code = "UNKNOWN_TABLE"
+	CodeUnknownColumn      Code = "UNKNOWN_COLUMN"
+	CodeUnknownObject      Code = "UNKNOWN_OBJECT"
+	CodeQueryTimeout       Code = "QUERY_TIMEOUT"
+	CodeCanceled           Code = "CANCELED"
+	CodePermissionDenied   Code = "PERMISSION_DENIED"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeConflict           Code = "CONFLICT"
+	CodeBackendUnavailable Code = "BACKEND_UNAVAILABLE"
+	CodeUnsupported        Code = "UNSUPPORTED"
+	CodeQueryFailed        Code = "QUERY_FAILED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+)
+
+// Retryable reports whether a request failing with this code may succeed
+// when retried unchanged
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeQueryTimeout, CodeRateLimited, CodeConflict, CodeBackendUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToolError is the structured error returned to clients in tool results
+type ToolError struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	Hint      string `json:"hint,omitempty"`
+	// Target is the identifier the error refers to (database, table or column name)
+	Target string `json:"target,omitempty"`
+	// Suggestions lists close matches for Target, nearest first
+	Suggestions []string `json:"suggestions,omitempty"`
+
+	cause error
+}
+
+// New creates a ToolError with the default retryability for the code
+func New(code Code, message string) *ToolError {
+	return &ToolError{Code: code, Message: message, Retryable: code.Retryable()}
+}
+
+// Newf creates a ToolError with a formatted message
+func Newf(code Code, format string, args ...any) *ToolError {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap creates a ToolError that keeps err as its cause
+func Wrap(code Code, err error, message string) *ToolError {
+	e := New(code, fmt.Sprintf("%s: %v", message, err))
+	e.cause = err
+	return e
+}
+
+// Error implements error
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause, if any
+func (e *ToolError) Unwrap() error {
+	return e.cause
+}
+
+// WithHint sets a human-readable hint on how to fix the request
+func (e *ToolError) WithHint(hint string) *ToolError {
+	e.Hint = hint
+	return e
+}
+
+// WithTarget records the identifier the error refers to
+func (e *ToolError) WithTarget(target string) *ToolError {
+	e.Target = target
+	return e
+}
+
+// WithSuggestions records close matches for the error target out of candidates
+// and adds a hint listing them
+func (e *ToolError) WithSuggestions(candidates []string) *ToolError {
+	e.Suggestions = Suggest(e.Target, candidates, maxSuggestions)
+	if len(e.Suggestions) > 0 {
+		e.Hint = fmt.Sprintf("Did you mean: %s?", strings.Join(e.Suggestions, ", "))
+	}
+	return e
+}
+
+// NotFound creates a not-found error for a named backend, listing the
+// available names and the closest matches
+func NotFound(code Code, kind, name string, available []string) *ToolError {
+	sorted := append([]string(nil), available...)
+	sort.Strings(sorted)
+
+	if len(sorted) == 0 {
+		return Newf(code, "%s '%s' not found. No %ss are configured or enabled.", kind, name, kind).WithTarget(name)
+	}
+
+	e := Newf(code, "%s '%s' not found or not enabled. Available %ss: %s", kind, name, kind, strings.Join(sorted, ", ")).
+		WithTarget(name).
+		WithSuggestions(sorted)
+	if e.Hint == "" {
+		e.Hint = fmt.Sprintf("Use one of: %s", strings.Join(sorted, ", "))
+	}
+	return e
+}
+
+// As converts any error into a ToolError. ToolErrors in the chain are
+// returned as is, other errors are classified as driver errors.
+func As(err error) *ToolError {
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+	return FromDBError(err)
+}
+
+// Result builds an error tool result that carries the ToolError both as
+// structured content and as its JSON text representation
+func Result(err error) *mcp.CallToolResult {
+	te := As(err)
+
+	text, marshalErr := json.MarshalIndent(te, "", "  ")
+	if marshalErr != nil {
+		return mcp.NewToolResultError(te.Error())
+	}
+
+	result := mcp.NewToolResultStructured(te, string(text))
+	result.IsError = true
+	return result
+}
+
+// InvalidArgument wraps an argument parsing error as INVALID_ARGUMENT
+func InvalidArgument(err error) *ToolError {
+	return New(CodeInvalidArgument, err.Error())
+}
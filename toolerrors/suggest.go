@@ -0,0 +1,77 @@
+package toolerrors
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions caps the number of close matches reported in a hint
+const maxSuggestions = 3
+
+// Suggest returns up to limit candidates closest to name by edit distance,
+// ignoring case. Candidates that are too far away to be a plausible typo
+// are left out.
+func Suggest(name string, candidates []string, limit int) []string {
+	if name == "" || len(candidates) == 0 {
+		return nil
+	}
+
+	type match struct {
+		candidate string
+		distance  int
+	}
+
+	lowerName := strings.ToLower(name)
+	threshold := max(2, len(name)/2)
+
+	var matches []match
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		distance := levenshtein(lowerName, lowerCandidate)
+		if strings.Contains(lowerCandidate, lowerName) || strings.Contains(lowerName, lowerCandidate) {
+			distance = min(distance, 1)
+		}
+		if distance <= threshold {
+			matches = append(matches, match{candidate: candidate, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.candidate
+	}
+	return result
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+)
+
+const (
+	defaultJoinDepth = 3
+	maxJoinDepth     = 6
+)
+
+// DBJoinQueryResult is the structured result of db_join_query. Row keys are
+// table.column labels. Rows is always an array; dry runs return no rows but
+// the params.
+type DBJoinQueryResult struct {
+	Database string           `json:"database"`
+	Table    string           `json:"table"`
+	Tables   []string         `json:"tables"` // joined tables in join order, including intermediate ones
+	Joins    []db.JoinStep    `json:"joins"`
+	Columns  []string         `json:"columns"`
+	Rows     []map[string]any `json:"rows"`
+	RowCount int              `json:"row_count"`
+	DryRun   bool             `json:"dry_run,omitempty"`
+	Query    string           `json:"query"`
+	Params   []any            `json:"params,omitempty"`
+}
+
+// HandleDBJoinQuery joins related tables to a root table along foreign key
+// paths and returns the rows with table-qualified column names
+// CRITICAL: Uses parameterized queries to prevent SQL injection
+func (h *DBToolsHandler) HandleDBJoinQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.InfoContext(ctx, "Handling db_join_query tool request")
+
+	dbName, err := request.RequireString("database")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	root, err := request.RequireString("table")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	related, err := toolargs.StringList(request, "join")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if len(related) == 0 {
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeInvalidArgument, "at least one table to join is required").
+			WithHint("Pass join, e.g. [\"customers\", \"order_items\"]; use db_query for a single table.")), nil
+	}
+	var via map[string][]string
+	if err := toolargs.Decode(request, "via", &via); err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	projections, err := toolargs.StringList(request, "columns")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	conditions, err := toolargs.Object(request, "conditions")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+
+	maxDepth := request.GetInt("max_depth", defaultJoinDepth)
+	if maxDepth < 1 || maxDepth > maxJoinDepth {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"max_depth must be between 1 and %d", maxJoinDepth)), nil
+	}
+	limit := request.GetInt("limit", h.config.MaxRows)
+	if limit <= 0 || limit > h.config.MaxRows {
+		limit = h.config.MaxRows
+	}
+	offset := max(request.GetInt("offset", 0), 0)
+	joinType := request.GetString("join_type", "inner")
+	if joinType != "inner" && joinType != "left" {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "invalid join_type: %s", joinType).
+			WithHint("Must be one of: inner, left")), nil
+	}
+	dryRun := request.GetBool("dry_run", h.config.DefaultDryRun)
+
+	// Get repository and resolve table names
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return toolerrors.Result(h.databaseNotFoundError(dbName)), nil
+	}
+	schema := request.GetString("schema", "")
+	resolve := func(table string) (string, error) {
+		if err := db.ValidateIdentifier("table", table); err != nil {
+			return "", err
+		}
+		return db.ResolveTableName(repo, schema, table)
+	}
+	if root, err = resolve(root); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	for i, table := range related {
+		if related[i], err = resolve(table); err != nil {
+			return toolerrors.Result(err), nil
+		}
+	}
+	hints := make(map[string][]string, len(via))
+	for table, through := range via {
+		resolved, err := resolve(table)
+		if err != nil {
+			return toolerrors.Result(err), nil
+		}
+		hints[resolved] = through
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(h.config.QueryTimeout)*time.Second)
+	defer cancel()
+
+	// Plan the joins: each table joins along the shortest path from the
+	// tables joined so far, which must be unique once via hints apply
+	graph, err := db.LoadJoinGraph(queryCtx, repo)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to read foreign keys", "error", err)
+		return toolerrors.Result(db.ClassifyError(ctx, repo, "", err)), nil
+	}
+	tables := []string{root}
+	var steps []db.JoinStep
+	for _, table := range related {
+		if slices.Contains(tables, table) {
+			continue
+		}
+		path, err := joinPath(graph, tables, table, hints[table], maxDepth)
+		if err != nil {
+			return toolerrors.Result(err), nil
+		}
+		for _, step := range path {
+			steps = append(steps, step)
+			tables = append(tables, step.ToTable)
+		}
+	}
+
+	// Columns default to every column of the root and joined tables named
+	// in the request, leaving out intermediate ones
+	if len(projections) == 0 {
+		for _, table := range slices.Concat([]string{root}, related) {
+			if !slices.Contains(projections, table+".*") {
+				projections = append(projections, table+".*")
+			}
+		}
+	}
+	columns, err := h.joinColumns(queryCtx, repo, root, tables, projections)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	// Unqualified condition columns belong to the root table
+	qualified := make(map[string]any, len(conditions))
+	for key, value := range conditions {
+		if err := db.ValidateIdentifier("column", key); err != nil {
+			return toolerrors.Result(err), nil
+		}
+		if table, _, ok := db.SplitColumnName(key); !ok || !slices.Contains(tables, table) {
+			key = root + "." + key
+		}
+		qualified[key] = value
+	}
+
+	fullNames := make(map[string]string, len(tables))
+	for _, table := range tables {
+		fullNames[table] = db.FullTableName(repo, table)
+	}
+	qb := db.NewQueryBuilder(repo.GetDriver())
+	built := db.StartBuildSpan(ctx, "join")
+	query, params, err := qb.BuildJoinQuery(db.JoinSpec{
+		Root:       root,
+		Steps:      steps,
+		FullNames:  fullNames,
+		Left:       joinType == "left",
+		Columns:    columns,
+		Conditions: qualified,
+		OrderBy:    request.GetString("order_by", ""),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	built(query, err)
+	if err != nil {
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInvalidArgument, err, "failed to build query")), nil
+	}
+
+	result := DBJoinQueryResult{
+		Database: dbName,
+		Table:    root,
+		Tables:   tables,
+		Joins:    steps,
+		Columns:  make([]string, len(columns)),
+		Rows:     []map[string]any{},
+		Query:    query,
+	}
+	for i, column := range columns {
+		result.Columns[i] = column.Label()
+	}
+
+	if dryRun {
+		result.DryRun = true
+		result.Params = params
+	} else {
+		// CRITICAL: Execute parameterized query to prevent SQL injection
+		rows, err := repo.Query(queryCtx, query, params...)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "Join query failed", "error", err, "query", query)
+			return toolerrors.Result(db.ClassifyError(ctx, repo, root, err)), nil
+		}
+		defer rows.Close()
+
+		data, err := h.parseQueryResult(ctx, rows)
+		if err != nil {
+			return toolerrors.Result(db.ClassifyError(ctx, repo, root, err)), nil
+		}
+		metrics.RecordRows(ctx, data.RowCount)
+		result.Rows = data.Rows
+		result.RowCount = data.RowCount
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal join query result", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal result")), nil
+	}
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
+}
+
+// joinPath finds the join path to a table from the tables joined so far.
+// through keeps the paths passing every listed table, constraint or
+// table.column of a foreign key; the path left must be unique.
+func joinPath(graph db.JoinGraph, joined []string, table string, through []string, maxDepth int) ([]db.JoinStep, error) {
+	paths := graph.ShortestPaths(joined, table, maxDepth)
+	if len(paths) == 0 {
+		return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument,
+			"no foreign key path joins %s to %s within %d joins", table, strings.Join(joined, ", "), maxDepth).
+			WithTarget(table).
+			WithHint("Check the table names, raise max_depth, or query the tables separately; joins follow declared foreign keys only.")
+	}
+
+	paths = slices.DeleteFunc(paths, func(path []db.JoinStep) bool {
+		return !passesThrough(path, through)
+	})
+	if len(paths) == 1 {
+		return paths[0], nil
+	}
+
+	described := make([]string, len(paths))
+	for i, path := range paths {
+		described[i] = db.FormatJoinPath(path)
+	}
+	if len(paths) == 0 {
+		return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "no shortest join path to %s passes through %s", table, strings.Join(through, ", ")).
+			WithTarget(table).
+			WithHint("via lists tables, constraint names or table.column of foreign keys on the path")
+	}
+	return nil, toolerrors.Newf(toolerrors.CodeInvalidArgument, "%d join paths lead to %s; pass via to choose one: %s", len(paths), table, strings.Join(described, "; ")).
+		WithTarget(table).
+		WithHint(fmt.Sprintf(`Pass via, e.g. {"%s": ["%s"]}, naming a table, constraint or table.column on the wanted path.`, table, paths[0][0].Constraint))
+}
+
+// passesThrough reports whether a join path uses every hint as a table, a
+// constraint name or a table.column of one of its foreign keys
+func passesThrough(path []db.JoinStep, through []string) bool {
+	for _, hint := range through {
+		found := slices.ContainsFunc(path, func(step db.JoinStep) bool {
+			if hint == step.Constraint || hint == step.FromTable || hint == step.ToTable {
+				return true
+			}
+			for i := range step.FromColumns {
+				if hint == step.FromTable+"."+step.FromColumns[i] || hint == step.ToTable+"."+step.ToColumns[i] {
+					return true
+				}
+			}
+			return false
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// joinColumns resolves table.column projections over the joined tables.
+// table.* expands to all columns of the table, reading only their names;
+// bare columns belong to root.
+func (h *DBToolsHandler) joinColumns(ctx context.Context, repo db.Repository, root string, tables, projections []string) ([]db.JoinColumn, error) {
+	var columns []db.JoinColumn
+	for _, projection := range projections {
+		table, column, ok := db.SplitColumnName(projection)
+		if !ok || !slices.Contains(tables, table) {
+			table, column = root, projection
+		}
+		if column != "*" {
+			if err := db.ValidateIdentifier("column", column); err != nil {
+				return nil, err
+			}
+			columns = append(columns, db.JoinColumn{Table: table, Column: column})
+			continue
+		}
+
+		names, err := db.ColumnNames(ctx, repo, table)
+		if err != nil {
+			return nil, db.ClassifyError(ctx, repo, table, err)
+		}
+		if len(names) == 0 {
+			return nil, db.TableNotFoundError(ctx, repo, table)
+		}
+		for _, name := range names {
+			columns = append(columns, db.JoinColumn{Table: table, Column: name})
+		}
+	}
+	return columns, nil
+}
@@ -2,17 +2,22 @@ package tools
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/SkillingX/mcp-localbridge/config"
 	"github.com/SkillingX/mcp-localbridge/db"
+	"github.com/SkillingX/mcp-localbridge/metrics"
+	"github.com/SkillingX/mcp-localbridge/toolargs"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
+	"github.com/SkillingX/mcp-localbridge/tracing"
 )
 
 // DBToolsHandler provides database-related MCP tools
@@ -41,10 +46,62 @@ func (h *DBToolsHandler) getAvailableDatabases() []string {
 	return databases
 }
 
-// formatDatabaseNotFoundError creates a helpful error message with available databases
+// databaseNotFoundError creates a DATABASE_NOT_FOUND error with available databases
 // Uses shared implementation from db package
-func (h *DBToolsHandler) formatDatabaseNotFoundError(dbName string) string {
-	return db.FormatDatabaseNotFoundError(dbName, h.repositories)
+func (h *DBToolsHandler) databaseNotFoundError(dbName string) *toolerrors.ToolError {
+	return db.DatabaseNotFoundError(dbName, h.repositories)
+}
+
+// DBQueryResult is the structured result of db_query. Executed queries fill
+// columns, rows and row_count; dry runs fill the query preview fields and
+// return no rows. Rows is always an array.
+type DBQueryResult struct {
+	Columns     []string         `json:"columns,omitempty"`
+	Rows        []map[string]any `json:"rows"`
+	RowCount    int              `json:"row_count"`
+	DryRun      bool             `json:"dry_run,omitempty"`
+	Query       string           `json:"query,omitempty"`
+	Params      []any            `json:"params,omitempty"`
+	Description string           `json:"description,omitempty"`
+}
+
+// DBTableListResult is the structured result of db_table_list. Tables and
+// Count cover base tables; Objects lists every object with its type. Names
+// outside the default schema are qualified as schema.table.
+type DBTableListResult struct {
+	Database string          `json:"database"`
+	Schemas  []string        `json:"schemas"`
+	Tables   []string        `json:"tables"`
+	Count    int             `json:"count"`
+	Objects  []db.ObjectInfo `json:"objects"`
+}
+
+// DBObjectDefinitionResult is the structured result of db_object_definition
+type DBObjectDefinitionResult struct {
+	Database   string `json:"database"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Definition string `json:"definition"`
+}
+
+// DBTablePreviewResult is the structured result of db_table_preview
+type DBTablePreviewResult struct {
+	Database     string          `json:"database"`
+	Table        string          `json:"table"`
+	PreviewLimit int             `json:"preview_limit"`
+	Data         *db.QueryResult `json:"data"`
+}
+
+// DatabaseEntry describes a configured database instance
+type DatabaseEntry struct {
+	Name   string `json:"name"`
+	Driver string `json:"driver"`
+}
+
+// DBListDatabasesResult is the structured result of db_list_databases
+type DBListDatabasesResult struct {
+	Databases []DatabaseEntry `json:"databases"`
+	Count     int             `json:"count"`
 }
 
 // HandleDBQuery executes a database query with safe parameter binding
@@ -52,29 +109,39 @@ func (h *DBToolsHandler) formatDatabaseNotFoundError(dbName string) string {
 func (h *DBToolsHandler) HandleDBQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling db_query tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	tableName, err := request.RequireString("table")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(h.formatDatabaseNotFoundError(dbName)), nil
+		return toolerrors.Result(h.databaseNotFoundError(dbName)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
 	}
 
 	// Parse conditions (WHERE clause as JSON object)
-	var conditions map[string]any
-	condStr := request.GetString("conditions", "")
-	if condStr != "" {
-		if err := json.Unmarshal([]byte(condStr), &conditions); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid conditions JSON: %v", err)), nil
+	// Accepts a JSON object or, for older clients, a JSON-encoded string
+	conditions, err := toolargs.Object(request, "conditions")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	for column := range conditions {
+		if err := db.ValidateIdentifier("column", column); err != nil {
+			return toolerrors.Result(err), nil
 		}
 	}
 
@@ -97,22 +164,25 @@ func (h *DBToolsHandler) HandleDBQuery(ctx context.Context, request mcp.CallTool
 
 	// Build query using QueryBuilder (always parameterized)
 	qb := db.NewQueryBuilder(repo.GetDriver())
-	query, params := qb.BuildSelect(tableName, conditions, limit, offset, orderBy)
+	built := db.StartBuildSpan(ctx, "select")
+	query, params := qb.BuildSelect(db.FullTableName(repo, tableName), conditions, limit, offset, orderBy)
+	built(query, nil)
 
 	// If dry-run, return the query preview without executing
 	if dryRun {
-		preview := map[string]any{
-			"dry_run":     true,
-			"query":       query,
-			"params":      params,
-			"description": "Preview of the SQL query. Set dry_run=false to execute.",
+		preview := DBQueryResult{
+			Rows:        []map[string]any{},
+			DryRun:      true,
+			Query:       query,
+			Params:      params,
+			Description: "Preview of the SQL query. Set dry_run=false to execute.",
 		}
 		previewJSON, err := json.MarshalIndent(preview, "", "  ")
 		if err != nil {
 			h.logger.ErrorContext(ctx, "Failed to marshal dry-run preview", "error", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal preview: %v", err)), nil
+			return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal preview")), nil
 		}
-		return mcp.NewToolResultText(string(previewJSON)), nil
+		return mcp.NewToolResultStructured(preview, string(previewJSON)), nil
 	}
 
 	// Execute query with timeout
@@ -123,124 +193,228 @@ func (h *DBToolsHandler) HandleDBQuery(ctx context.Context, request mcp.CallTool
 	rows, err := repo.Query(queryCtx, query, params...)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Query execution failed", "error", err, "query", query)
-		return mcp.NewToolResultError(fmt.Sprintf("query execution failed: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
 	}
 	defer rows.Close()
 
 	// Parse results
-	result, err := h.parseQueryResult(rows)
+	result, err := h.parseQueryResult(ctx, rows)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to parse query results: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
 	}
+	metrics.RecordRows(ctx, result.RowCount)
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	output := DBQueryResult{
+		Columns:  result.Columns,
+		Rows:     result.Rows,
+		RowCount: result.RowCount,
+	}
+
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal query result", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal result")), nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(output, string(resultJSON)), nil
 }
 
-// HandleDBTableList returns a list of all tables in the database
+// HandleDBTableList returns a list of all tables and other objects in the database
 func (h *DBToolsHandler) HandleDBTableList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling db_table_list tool request")
 
-	// Extract required parameter using mcp-go v0.43.2 best practices
+	// Extract required parameter
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(h.formatDatabaseNotFoundError(dbName)), nil
+		return toolerrors.Result(h.databaseNotFoundError(dbName)), nil
+	}
+
+	// An optional schema narrows the listing to one of the configured schemas
+	schemas := repo.Schemas()
+	if schema := request.GetString("schema", ""); schema != "" {
+		if err := db.ResolveSchema(repo, schema); err != nil {
+			return toolerrors.Result(err), nil
+		}
+		schemas = []string{schema}
 	}
 
-	// Get table list based on repository type
-	var tables []string
+	// Get object list based on repository type
+	var objects []db.ObjectInfo
 	switch r := repo.(type) {
 	case *db.MySQLRepository:
-		tables, err = r.GetTableList(ctx)
+		objects, err = r.GetObjects(ctx)
 	case *db.PostgresRepository:
-		tables, err = r.GetTableList(ctx)
+		objects, err = r.GetObjects(ctx)
 	default:
-		return mcp.NewToolResultError("unsupported repository type"), nil
+		return toolerrors.Result(toolerrors.New(toolerrors.CodeUnsupported, "unsupported repository type")), nil
 	}
 
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to get table list", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get table list: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, "", err)), nil
+	}
+
+	tables := []string{}
+	listed := []db.ObjectInfo{}
+	for _, object := range objects {
+		if schema, _ := db.SplitTableName(repo, object.Name); !slices.Contains(schemas, schema) {
+			continue
+		}
+		listed = append(listed, object)
+		if object.Type == db.ObjectTable {
+			tables = append(tables, object.Name)
+		}
 	}
 
-	result := map[string]any{
-		"database": dbName,
-		"tables":   tables,
-		"count":    len(tables),
+	result := DBTableListResult{
+		Database: dbName,
+		Schemas:  schemas,
+		Tables:   tables,
+		Count:    len(tables),
+		Objects:  listed,
 	}
 
 	resultJSON, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal table list", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal table list: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal table list")), nil
+	}
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
+}
+
+// HandleDBObjectDefinition returns the DDL of a table, view, routine, trigger or sequence
+func (h *DBToolsHandler) HandleDBObjectDefinition(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.InfoContext(ctx, "Handling db_object_definition tool request")
+
+	dbName, err := request.RequireString("database")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	name, err := request.RequireString("name")
+	if err != nil {
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if err := db.ValidateIdentifier("object", name); err != nil {
+		return toolerrors.Result(err), nil
+	}
+	objectType := request.GetString("type", "")
+	if objectType != "" && !slices.Contains(db.ObjectTypes, objectType) {
+		return toolerrors.Result(toolerrors.Newf(toolerrors.CodeInvalidArgument, "unknown object type %q, expected one of: %s",
+			objectType, strings.Join(db.ObjectTypes, ", "))), nil
+	}
+
+	repo, ok := h.repositories[dbName]
+	if !ok {
+		return toolerrors.Result(h.databaseNotFoundError(dbName)), nil
+	}
+
+	name, err = db.ResolveTableName(repo, request.GetString("schema", ""), name)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	// Check the object exists, and find its type when none was given
+	objectType, err = db.ResolveObject(ctx, repo, objectType, name)
+	if err != nil {
+		return toolerrors.Result(err), nil
+	}
+
+	var definition string
+	switch r := repo.(type) {
+	case *db.MySQLRepository:
+		definition, err = r.GetObjectDefinition(ctx, objectType, name)
+	case *db.PostgresRepository:
+		definition, err = r.GetObjectDefinition(ctx, objectType, name)
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get object definition", "object", name, "type", objectType, "error", err)
+		return toolerrors.Result(err), nil
+	}
+
+	result := DBObjectDefinitionResult{
+		Database:   dbName,
+		Name:       name,
+		Type:       objectType,
+		Definition: definition,
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to marshal object definition", "error", err)
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal object definition")), nil
+	}
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
 }
 
 // HandleDBTablePreview returns a preview of table data
 func (h *DBToolsHandler) HandleDBTablePreview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling db_table_preview tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	dbName, err := request.RequireString("database")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	tableName, err := request.RequireString("table")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
+	}
+	if err := db.ValidateIdentifier("table", tableName); err != nil {
+		return toolerrors.Result(err), nil
 	}
 
 	// Get repository
 	repo, ok := h.repositories[dbName]
 	if !ok {
-		return mcp.NewToolResultError(h.formatDatabaseNotFoundError(dbName)), nil
+		return toolerrors.Result(h.databaseNotFoundError(dbName)), nil
+	}
+	tableName, err = db.ResolveTableName(repo, request.GetString("schema", ""), tableName)
+	if err != nil {
+		return toolerrors.Result(err), nil
 	}
 
 	// Build preview query (limit to configured preview limit)
 	qb := db.NewQueryBuilder(repo.GetDriver())
-	query, params := qb.BuildSelect(tableName, nil, h.config.PreviewLimit, 0, "")
+	built := db.StartBuildSpan(ctx, "select")
+	query, params := qb.BuildSelect(db.FullTableName(repo, tableName), nil, h.config.PreviewLimit, 0, "")
+	built(query, nil)
 
 	// Execute query
 	// CRITICAL: Uses parameterized query
 	rows, err := repo.Query(ctx, query, params...)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Preview query failed", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("preview query failed: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
 	}
 	defer rows.Close()
 
 	// Parse results
-	result, err := h.parseQueryResult(rows)
+	result, err := h.parseQueryResult(ctx, rows)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to parse preview results: %v", err)), nil
+		return toolerrors.Result(db.ClassifyError(ctx, repo, tableName, err)), nil
 	}
+	metrics.RecordRows(ctx, result.RowCount)
 
 	// Add metadata
-	response := map[string]any{
-		"database":      dbName,
-		"table":         tableName,
-		"preview_limit": h.config.PreviewLimit,
-		"data":          result,
+	response := DBTablePreviewResult{
+		Database:     dbName,
+		Table:        tableName,
+		PreviewLimit: h.config.PreviewLimit,
+		Data:         result,
 	}
 
 	resultJSON, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal table preview", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal preview: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal preview")), nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(response, string(resultJSON)), nil
 }
 
 // HandleDBListDatabases returns a list of all available database instances
@@ -248,35 +422,42 @@ func (h *DBToolsHandler) HandleDBListDatabases(ctx context.Context, request mcp.
 	h.logger.InfoContext(ctx, "Handling db_list_databases tool request")
 
 	// Get all available databases with their types
-	databases := make([]map[string]any, 0, len(h.repositories))
+	databases := make([]DatabaseEntry, 0, len(h.repositories))
 	for name, repo := range h.repositories {
-		dbInfo := map[string]any{
-			"name":   name,
-			"driver": repo.GetDriver(),
-		}
-		databases = append(databases, dbInfo)
+		databases = append(databases, DatabaseEntry{
+			Name:   name,
+			Driver: repo.GetDriver(),
+		})
 	}
 
 	// Sort by name for consistent output
 	sort.Slice(databases, func(i, j int) bool {
-		return databases[i]["name"].(string) < databases[j]["name"].(string)
+		return databases[i].Name < databases[j].Name
 	})
 
-	result := map[string]any{
-		"databases": databases,
-		"count":     len(databases),
+	result := DBListDatabasesResult{
+		Databases: databases,
+		Count:     len(databases),
 	}
 
 	resultJSON, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to marshal database list", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal database list: %v", err)), nil
+		return toolerrors.Result(toolerrors.Wrap(toolerrors.CodeInternal, err, "failed to marshal database list")), nil
 	}
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
 }
 
 // parseQueryResult parses SQL rows into a QueryResult structure
-func (h *DBToolsHandler) parseQueryResult(rows *sql.Rows) (*db.QueryResult, error) {
+func (h *DBToolsHandler) parseQueryResult(ctx context.Context, rows *db.Rows) (result *db.QueryResult, err error) {
+	_, span := tracing.Start(ctx, "db.decode_rows")
+	defer func() {
+		if result != nil {
+			span.SetAttributes(tracing.AttrDBRowCount.Int(result.RowCount))
+		}
+		tracing.End(span, err)
+	}()
+
 	// Get column names
 	columns, err := rows.Columns()
 	if err != nil {
@@ -284,7 +465,7 @@ func (h *DBToolsHandler) parseQueryResult(rows *sql.Rows) (*db.QueryResult, erro
 	}
 
 	// Prepare result storage
-	var resultRows []map[string]any
+	resultRows := []map[string]any{}
 
 	// Iterate through rows
 	for rows.Next() {
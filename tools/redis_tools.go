@@ -3,7 +3,6 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"time"
 
@@ -11,6 +10,7 @@ import (
 
 	"github.com/SkillingX/mcp-localbridge/cache"
 	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/toolerrors"
 )
 
 // RedisToolsHandler provides Redis-related MCP tools
@@ -29,69 +29,103 @@ func NewRedisToolsHandler(clients map[string]*cache.RedisClient, cfg config.Redi
 	}
 }
 
+// RedisGetResult is the structured result of redis_get
+type RedisGetResult struct {
+	Redis string `json:"redis"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// RedisSetResult is the structured result of redis_set
+type RedisSetResult struct {
+	Redis   string  `json:"redis"`
+	Key     string  `json:"key"`
+	Success bool    `json:"success"`
+	TTL     float64 `json:"ttl"`
+}
+
+// RedisScanResult is the structured result of redis_scan
+type RedisScanResult struct {
+	Redis   string   `json:"redis"`
+	Pattern string   `json:"pattern"`
+	Keys    []string `json:"keys"`
+	Count   int      `json:"count"`
+	Limited bool     `json:"limited"`
+}
+
+// redisNotFoundError creates a REDIS_NOT_FOUND error with available Redis instances
+func (h *RedisToolsHandler) redisNotFoundError(redisName string) *toolerrors.ToolError {
+	available := make([]string, 0, len(h.clients))
+	for name := range h.clients {
+		available = append(available, name)
+	}
+	return toolerrors.NotFound(toolerrors.CodeRedisNotFound, "redis", redisName, available)
+}
+
 // HandleRedisGet retrieves a value from Redis by key
 func (h *RedisToolsHandler) HandleRedisGet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling redis_get tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	redisName, err := request.RequireString("redis")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	key, err := request.RequireString("key")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	// Get Redis client
 	client, ok := h.clients[redisName]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("Redis '%s' not found or not enabled", redisName)), nil
+		return toolerrors.Result(h.redisNotFoundError(redisName)), nil
 	}
 
 	// Get value
 	value, err := client.Get(ctx, key)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Redis GET failed", "error", err, "key", key)
-		return mcp.NewToolResultError(fmt.Sprintf("Redis GET failed: %v", err)), nil
+		return toolerrors.Result(toolerrors.FromRedisError(err)), nil
 	}
 
-	result := map[string]any{
-		"redis": redisName,
-		"key":   key,
-		"value": value,
-		"found": value != "",
+	result := RedisGetResult{
+		Redis: redisName,
+		Key:   key,
+		Value: value,
+		Found: value != "",
 	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
 }
 
 // HandleRedisSet sets a key-value pair in Redis
 func (h *RedisToolsHandler) HandleRedisSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling redis_set tool request")
 
-	// Extract required parameters using mcp-go v0.43.2 best practices
+	// Extract required parameters
 	redisName, err := request.RequireString("redis")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	key, err := request.RequireString("key")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	value, err := request.RequireString("value")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	// Get Redis client
 	client, ok := h.clients[redisName]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("Redis '%s' not found or not enabled", redisName)), nil
+		return toolerrors.Result(h.redisNotFoundError(redisName)), nil
 	}
 
 	// Parse optional TTL with GetInt (more type-safe)
@@ -104,28 +138,28 @@ func (h *RedisToolsHandler) HandleRedisSet(ctx context.Context, request mcp.Call
 	// Set value
 	if err := client.Set(ctx, key, value, expiration); err != nil {
 		h.logger.ErrorContext(ctx, "Redis SET failed", "error", err, "key", key)
-		return mcp.NewToolResultError(fmt.Sprintf("Redis SET failed: %v", err)), nil
+		return toolerrors.Result(toolerrors.FromRedisError(err)), nil
 	}
 
-	result := map[string]any{
-		"redis":   redisName,
-		"key":     key,
-		"success": true,
-		"ttl":     expiration.Seconds(),
+	result := RedisSetResult{
+		Redis:   redisName,
+		Key:     key,
+		Success: true,
+		TTL:     expiration.Seconds(),
 	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
 }
 
 // HandleRedisScan scans Redis keys matching a pattern
 func (h *RedisToolsHandler) HandleRedisScan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.InfoContext(ctx, "Handling redis_scan tool request")
 
-	// Extract required parameter using mcp-go v0.43.2 best practices
+	// Extract required parameter
 	redisName, err := request.RequireString("redis")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolerrors.Result(toolerrors.InvalidArgument(err)), nil
 	}
 
 	// Get pattern (default to "*")
@@ -134,11 +168,11 @@ func (h *RedisToolsHandler) HandleRedisScan(ctx context.Context, request mcp.Cal
 	// Get Redis client
 	client, ok := h.clients[redisName]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("Redis '%s' not found or not enabled", redisName)), nil
+		return toolerrors.Result(h.redisNotFoundError(redisName)), nil
 	}
 
 	// Scan keys (use multiple iterations to get more keys, up to max)
-	var allKeys []string
+	allKeys := []string{}
 	cursor := uint64(0)
 	maxKeys := h.config.MaxScanKeys
 
@@ -146,7 +180,7 @@ func (h *RedisToolsHandler) HandleRedisScan(ctx context.Context, request mcp.Cal
 		keys, newCursor, err := client.Scan(ctx, cursor, pattern, int64(h.config.ScanCount))
 		if err != nil {
 			h.logger.ErrorContext(ctx, "Redis SCAN failed", "error", err, "pattern", pattern)
-			return mcp.NewToolResultError(fmt.Sprintf("Redis SCAN failed: %v", err)), nil
+			return toolerrors.Result(toolerrors.FromRedisError(err)), nil
 		}
 
 		allKeys = append(allKeys, keys...)
@@ -164,14 +198,14 @@ func (h *RedisToolsHandler) HandleRedisScan(ctx context.Context, request mcp.Cal
 		}
 	}
 
-	result := map[string]any{
-		"redis":   redisName,
-		"pattern": pattern,
-		"keys":    allKeys,
-		"count":   len(allKeys),
-		"limited": len(allKeys) >= maxKeys,
+	result := RedisScanResult{
+		Redis:   redisName,
+		Pattern: pattern,
+		Keys:    allKeys,
+		Count:   len(allKeys),
+		Limited: len(allKeys) >= maxKeys,
 	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
 }
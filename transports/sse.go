@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/SkillingX/mcp-localbridge/config"
+	"github.com/SkillingX/mcp-localbridge/metrics"
 	mcpServer "github.com/SkillingX/mcp-localbridge/server"
 )
 
@@ -44,6 +46,9 @@ type SSETransport struct {
 
 // NewSSETransport creates a new SSE transport
 func NewSSETransport(mcpSrv *mcpServer.MCPServer, cfg config.SSEConfig, logger *slog.Logger) *SSETransport {
+	// Use our own HTTP server so SSE connections can be counted for metrics
+	httpServer := &http.Server{Addr: cfg.Address()}
+
 	// Create SSE server with full configuration options
 	sseServer := server.NewSSEServer(
 		mcpSrv.GetServer(),
@@ -52,8 +57,9 @@ func NewSSETransport(mcpSrv *mcpServer.MCPServer, cfg config.SSEConfig, logger *
 		server.WithMessageEndpoint(cfg.MessageEndpoint),
 		server.WithKeepAlive(cfg.KeepaliveInterval > 0),
 		server.WithKeepAliveInterval(time.Duration(cfg.KeepaliveInterval)*time.Second),
+		server.WithHTTPServer(httpServer),
 	)
-	// Note: mcp-go v0.43.2+ supports full configuration options
+	httpServer.Handler = countSSESessions(sseServer)
 
 	return &SSETransport{
 		mcpServer: mcpSrv,
@@ -101,3 +107,19 @@ func (t *SSETransport) Name() string {
 func (t *SSETransport) IsHealthy() bool {
 	return t.healthy
 }
+
+// countSSESessions tracks open SSE streams in the active sessions gauge.
+// The SSE handler blocks for the lifetime of the stream, so the gauge is
+// incremented on entry and decremented when the handler returns.
+func countSSESessions(sseServer *server.SSEServer) http.Handler {
+	ssePath := sseServer.CompleteSsePath()
+	gauge := metrics.ActiveSessions.WithLabelValues("sse")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == ssePath {
+			gauge.Inc()
+			defer gauge.Dec()
+		}
+		sseServer.ServeHTTP(w, r)
+	})
+}